@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gopkg.in/yaml.v3"
+	"io"
+	"strings"
+)
+
+// bindJSONOrYAML читает тело в JSON или, если Content-Type содержит yaml, в YAML —
+// для правил и шаблонов, которые администраторам удобнее писать в YAML.
+// Теги binding проверяются в обоих случаях.
+func bindJSONOrYAML(c *gin.Context, out interface{}) error {
+	if !strings.Contains(c.ContentType(), "yaml") {
+		return c.ShouldBindJSON(out)
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(body, out); err != nil {
+		return err
+	}
+	return binding.Validator.ValidateStruct(out)
+}
@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go-keycloak-jwt/services"
+	"net/http"
+	"strconv"
+)
+
+// @Summary Archived bureau response
+// @Description Расшифрованный сырой SOAP-ответ бюро по запросу скоринга. Каждое обращение записывается в журнал. Роль score_auditor
+// @Tags audit
+// @Produce json
+// @Param id path int true "Inquiry ID"
+// @Param reason query string false "Основание обращения (попадает в журнал)"
+// @Success 200 {object} models.BureauArchiveResponse
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "No archived bureau response"
+// @Failure 409 {object} map[string]string "Archive is not configured"
+// @Security BearerAuth
+// @Router /audit/scores/{id}/bureau-response [get]
+func GetArchivedBureauResponse(c *gin.Context) {
+	principal, _ := getPrincipal(c)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	response, err := services.GetArchivedBureauResponse(c.Request.Context(), principal, id, c.Query("reason"))
+	if errors.Is(err, services.ErrBureauArchiveNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if errors.Is(err, services.ErrBureauArchiveDisabled) {
+		c.JSON(http.StatusConflict, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+// @Summary Bureau archive access log
+// @Description Журнал обращений к архиву ответов бюро, новые сверху. Роль score_auditor
+// @Tags audit
+// @Produce json
+// @Param inquiry_id query int false "Только обращения к ответу по этому запросу"
+// @Param limit query int false "Количество записей (до 500)"
+// @Success 200 {array} models.BureauArchiveAccess
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Security BearerAuth
+// @Router /audit/bureau-archive-access [get]
+func GetBureauArchiveAccessLog(c *gin.Context) {
+	var inquiryId int64
+	var limit int
+	var err error
+	if value := c.Query("inquiry_id"); value != "" {
+		if inquiryId, err = strconv.ParseInt(value, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid inquiry_id"})
+			return
+		}
+	}
+	if value := c.Query("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+
+	accesses, err := services.GetBureauArchiveAccessLog(c.Request.Context(), inquiryId, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": accesses})
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/services"
+	"net/http"
+	"strconv"
+)
+
+// @Summary List bureau credentials
+// @Description Учётные данные бюро по пользователям, группам и по умолчанию (без паролей). Роль score_admin
+// @Tags admin
+// @Produce json
+// @Success 200 {array} models.BureauCredential
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Security BearerAuth
+// @Router /admin/bureau-credentials [get]
+func GetBureauCredentials(c *gin.Context) {
+	credentials, err := services.ListBureauCredentials(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": credentials})
+}
+
+// @Summary Create or rotate bureau credentials
+// @Description Создаёт или заменяет учётные данные бюро для пользователя, группы Keycloak или по умолчанию. Действуют со следующего запроса, без перезапуска. Роль score_admin
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param credential body models.BureauCredentialRequest true "Bureau credential"
+// @Success 200 {object} models.BureauCredential
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 409 {object} map[string]string "Credentials store is read-only"
+// @Security BearerAuth
+// @Router /admin/bureau-credentials [put]
+func PutBureauCredential(c *gin.Context) {
+	principal, _ := getPrincipal(c)
+
+	var request models.BureauCredentialRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	credential, err := services.SaveBureauCredential(c.Request.Context(), principal, request)
+	if errors.Is(err, services.ErrCredentialStoreReadOnly) || errors.Is(err, services.ErrCredentialStoreDisabled) {
+		c.JSON(http.StatusConflict, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": credential})
+}
+
+// @Summary Delete bureau credentials
+// @Description Удаляет учётные данные бюро. Роль score_admin
+// @Tags admin
+// @Produce json
+// @Param id path int true "Credential ID"
+// @Success 204
+// @Failure 404 {object} map[string]string "Bureau credential not found"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Security BearerAuth
+// @Router /admin/bureau-credentials/{id} [delete]
+func DeleteBureauCredential(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	err = services.DeleteBureauCredential(c.Request.Context(), id)
+	if errors.Is(err, services.ErrBureauCredentialNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if errors.Is(err, services.ErrCredentialStoreReadOnly) || errors.Is(err, services.ErrCredentialStoreDisabled) {
+		c.JSON(http.StatusConflict, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go-keycloak-jwt/resilience"
+	"go-keycloak-jwt/services"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// respondBureauError отвечает на ошибки, из-за которых запрос не дошёл до бюро:
+// 503 с Retry-After при разомкнутом выключателе и 403, если для пользователя
+// не настроены учётные данные бюро. Возвращает false для остальных ошибок.
+func respondBureauError(c *gin.Context, err error) bool {
+	if errors.Is(err, services.ErrNoBureauCredential) {
+		c.JSON(http.StatusForbidden, gin.H{"error": errorMessage(err)})
+		return true
+	}
+
+	var openErr *resilience.OpenError
+	if !errors.As(err, &openErr) {
+		return false
+	}
+	seconds := int(math.Ceil(openErr.RetryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Credit bureau is temporarily unavailable"})
+	return true
+}
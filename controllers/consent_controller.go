@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/services"
+	"net/http"
+	"strconv"
+)
+
+// @Summary Register consent
+// @Description Регистрирует согласие субъекта (ИИН/БИН) на запрос в бюро по перечисленным скоринговым картам ("*" — все карты)
+// @Tags consents
+// @Accept json
+// @Produce json
+// @Param consent body models.ConsentRequest true "Consent"
+// @Success 201 {object} models.Consent
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Security BearerAuth
+// @Router /consents [post]
+func PostConsent(c *gin.Context) {
+	principal, ok := getPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to extract user from token"})
+		return
+	}
+
+	var request models.ConsentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	consent, err := services.CreateConsent(c.Request.Context(), principal, request)
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid consent", "fields": validationErr.Fields})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": maskPII(c, consent)})
+}
+
+// @Summary Subject consents
+// @Description Все согласия субъекта, включая отозванные и истёкшие
+// @Tags consents
+// @Produce json
+// @Param iin query string true "ИИН/БИН субъекта"
+// @Success 200 {array} models.Consent
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Security BearerAuth
+// @Router /consents [get]
+func GetConsents(c *gin.Context) {
+	subject := c.Query("iin")
+	if subject == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "iin is required"})
+		return
+	}
+
+	consents, err := services.GetSubjectConsents(c.Request.Context(), subject)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": maskPII(c, consents)})
+}
+
+// @Summary Revoke consent
+// @Description Отзывает согласие; после отзыва скоринг по нему невозможен
+// @Tags consents
+// @Accept json
+// @Produce json
+// @Param id path int true "Consent ID"
+// @Param revoke body models.ConsentRevokeRequest false "Reason"
+// @Success 200 {object} models.Consent
+// @Failure 404 {object} map[string]string "Consent not found"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Security BearerAuth
+// @Router /consents/{id}/revoke [post]
+func RevokeConsent(c *gin.Context) {
+	principal, ok := getPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to extract user from token"})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var request models.ConsentRevokeRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+			return
+		}
+	}
+
+	consent, err := services.RevokeConsent(c.Request.Context(), principal, id, request.Reason)
+	if errors.Is(err, services.ErrConsentNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": maskPII(c, consent)})
+}
@@ -17,7 +17,7 @@ func GetCountries(c *gin.Context) {
 	data, err := services.GetCountries()
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
 	}
 	c.JSON(http.StatusOK, gin.H{"countries": data})
 }
@@ -34,10 +34,10 @@ func GetCountries(c *gin.Context) {
 // @Router /countries/{id} [get]
 func GetCountryById(c *gin.Context) {
 	reqId := c.Param("id")
-	data, err := services.GetCountryById(reqId)
+	data, err := services.GetCountryById(c.Request.Context(), reqId)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
 	}
 
 	c.JSON(http.StatusOK, gin.H{"data": data})
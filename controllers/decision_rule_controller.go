@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/services"
+	"net/http"
+	"strconv"
+)
+
+// @Summary List decision rule sets
+// @Description Все версии правил кредитного решения, новые сверху. Роль score_admin
+// @Tags decision-rules
+// @Produce json
+// @Success 200 {array} models.DecisionRuleSet
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Security BearerAuth
+// @Router /admin/decision-rules [get]
+func GetDecisionRuleSets(c *gin.Context) {
+	ruleSets, err := services.ListDecisionRuleSets(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": ruleSets})
+}
+
+// @Summary Get decision rule set
+// @Description Версия правил кредитного решения. Роль score_admin
+// @Tags decision-rules
+// @Produce json
+// @Param version path int true "Rule set version"
+// @Success 200 {object} models.DecisionRuleSet
+// @Failure 404 {object} map[string]string "Decision rule set not found"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Security BearerAuth
+// @Router /admin/decision-rules/{version} [get]
+func GetDecisionRuleSet(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid version"})
+		return
+	}
+
+	ruleSet, err := services.GetDecisionRuleSet(c.Request.Context(), version)
+	if errors.Is(err, services.ErrDecisionRuleSetNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": ruleSet})
+}
+
+// @Summary Create decision rule set version
+// @Description Сохраняет правила новой версией. Тело — JSON или YAML (Content-Type application/yaml). Роль score_admin
+// @Tags decision-rules
+// @Accept json
+// @Accept application/yaml
+// @Produce json
+// @Param rules body models.DecisionRuleSet true "Rule set"
+// @Param activate query bool false "Сразу сделать версию активной"
+// @Success 201 {object} models.DecisionRuleSet
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Security BearerAuth
+// @Router /admin/decision-rules [post]
+func PostDecisionRuleSet(c *gin.Context) {
+	principal, _ := getPrincipal(c)
+
+	var ruleSet models.DecisionRuleSet
+	if err := bindJSONOrYAML(c, &ruleSet); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	created, err := services.CreateDecisionRuleSet(c.Request.Context(), principal, ruleSet, c.Query("activate") == "true")
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid decision rules", "fields": validationErr.Fields})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": created})
+}
+
+// @Summary Activate decision rule set
+// @Description Делает версию правил активной: она применяется ко всем следующим скорингам. Роль score_admin
+// @Tags decision-rules
+// @Produce json
+// @Param version path int true "Rule set version"
+// @Success 204
+// @Failure 404 {object} map[string]string "Decision rule set not found"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Security BearerAuth
+// @Router /admin/decision-rules/{version}/activate [post]
+func ActivateDecisionRuleSet(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid version"})
+		return
+	}
+
+	err = services.ActivateDecisionRuleSet(c.Request.Context(), version)
+	if errors.Is(err, services.ErrDecisionRuleSetNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Decision rules dry run
+// @Description Прогоняет правила (переданные, указанной версии или активные) на результате скоринга без сохранения и показывает, какое правило сработало. Роль score_admin
+// @Tags decision-rules
+// @Accept json
+// @Produce json
+// @Param request body models.DecisionDryRunRequest true "Dry run"
+// @Success 200 {object} models.DecisionDryRunResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string "Rule set or score inquiry not found"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Security BearerAuth
+// @Router /admin/decision-rules/dry-run [post]
+func PostDecisionRulesDryRun(c *gin.Context) {
+	principal, _ := getPrincipal(c)
+
+	var request models.DecisionDryRunRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	result, err := services.DryRunDecisionRules(c.Request.Context(), principal, request)
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid decision rules", "fields": validationErr.Fields})
+		return
+	}
+	if errors.Is(err, services.ErrDecisionRuleSetNotFound) || errors.Is(err, services.ErrScoreInquiryNotFound) ||
+		errors.Is(err, services.ErrNoActiveDecisionRules) {
+		c.JSON(http.StatusNotFound, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
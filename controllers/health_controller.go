@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-keycloak-jwt/resilience"
+	"go-keycloak-jwt/services"
+	"net/http"
+)
+
+// @Summary Health check
+// @Description Состояние сервиса, выключателя запросов к бюро (bureau) и выключателей всех провайдеров скоринга (providers).
+// @Description Если выключатель бюро разомкнут, возвращает 503
+// @Tags main
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /health [get]
+func GetHealth(c *gin.Context) {
+	bureau := services.BureauHealth()
+
+	status := http.StatusOK
+	if bureau.State == resilience.Open {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, gin.H{"status": http.StatusText(status), "bureau": bureau, "providers": services.ScoreProvidersHealth()})
+}
@@ -0,0 +1,18 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-keycloak-jwt/services"
+	"net/http"
+)
+
+// @Summary Service JWKS
+// @Description Открытые ключи подписи сервиса (RS256) для проверки signed_result из POST /score. Ключ подписи указан в заголовке JWS (kid); после ротации прежние ключи остаются в наборе, пока они есть в SERVICE_SIGNING_KEYS
+// @Tags main
+// @Produce json
+// @Success 200 {object} models.JWKSet
+// @Router /.well-known/jwks.json [get]
+func GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, services.ServiceJWKS())
+}
@@ -0,0 +1,355 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/services"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// @Summary Create loan application
+// @Description Создать заявку на кредит в статусе draft. ИИН заявителя проверяется по контрольной сумме, страна — по справочнику countries
+// @Tags loan-applications
+// @Accept json
+// @Produce json
+// @Param application body models.LoanApplicationRequest true "Заявка"
+// @Param Idempotency-Key header string false "Повтор с тем же ключом получает первый ответ"
+// @Success 201 {object} models.LoanApplication
+// @Failure 400 {object} map[string]string "Invalid loan application"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Security BearerAuth
+// @Router /loan-applications [post]
+func PostLoanApplication(c *gin.Context) {
+	principal, ok := getPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to extract user from token"})
+		return
+	}
+
+	var request models.LoanApplicationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	application, err := services.CreateLoanApplication(c.Request.Context(), principal, request)
+	if respondLoanApplicationError(c, err) {
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": maskPII(c, application)})
+}
+
+// @Summary Loan applications
+// @Description Список заявок, новые первыми. Супервизор видит заявки своей команды, остальные — только свои
+// @Tags loan-applications
+// @Produce json
+// @Param status query string false "Статус: draft, scored, decided или closed"
+// @Param iin query string false "ИИН заявителя"
+// @Param limit query int false "Размер страницы (до 100)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} models.LoanApplication
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Security BearerAuth
+// @Router /loan-applications [get]
+func GetLoanApplications(c *gin.Context) {
+	principal, ok := getPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to extract user from token"})
+		return
+	}
+
+	filter := models.LoanApplicationFilter{
+		Status:       c.Query("status"),
+		ApplicantIin: c.Query("iin"),
+	}
+	var err error
+	if limit := c.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+	if offset := c.Query("offset"); offset != "" {
+		if filter.Offset, err = strconv.Atoi(offset); err != nil || filter.Offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+			return
+		}
+	}
+
+	applications, err := services.ListLoanApplications(c.Request.Context(), principal, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": maskPII(c, applications)})
+}
+
+// @Summary Loan application by ID
+// @Description Заявка с привязанными запросами скоринга и историей статусов
+// @Tags loan-applications
+// @Produce json
+// @Param id path int true "Application ID"
+// @Success 200 {object} models.LoanApplicationDetails
+// @Failure 404 {object} map[string]string "Loan application not found"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Security BearerAuth
+// @Router /loan-applications/{id} [get]
+func GetLoanApplicationById(c *gin.Context) {
+	principal, id, ok := loanApplicationParams(c)
+	if !ok {
+		return
+	}
+
+	details, err := services.GetLoanApplication(c.Request.Context(), principal, id)
+	if respondLoanApplicationError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": maskPII(c, details)})
+}
+
+// @Summary Update loan application
+// @Description Изменить данные заявки; доступно только для черновика (draft)
+// @Tags loan-applications
+// @Accept json
+// @Produce json
+// @Param id path int true "Application ID"
+// @Param application body models.LoanApplicationRequest true "Заявка"
+// @Success 200 {object} models.LoanApplication
+// @Failure 400 {object} map[string]string "Invalid loan application"
+// @Failure 404 {object} map[string]string "Loan application not found"
+// @Failure 409 {object} map[string]string "Loan application status does not allow this action"
+// @Security BearerAuth
+// @Router /loan-applications/{id} [put]
+func PutLoanApplication(c *gin.Context) {
+	principal, id, ok := loanApplicationParams(c)
+	if !ok {
+		return
+	}
+
+	var request models.LoanApplicationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	application, err := services.UpdateLoanApplication(c.Request.Context(), principal, id, request)
+	if respondLoanApplicationError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": maskPII(c, application)})
+}
+
+// @Summary Delete loan application
+// @Description Удалить черновик заявки; поданную заявку можно только закрыть
+// @Tags loan-applications
+// @Param id path int true "Application ID"
+// @Success 204
+// @Failure 404 {object} map[string]string "Loan application not found"
+// @Failure 409 {object} map[string]string "Loan application status does not allow this action"
+// @Security BearerAuth
+// @Router /loan-applications/{id} [delete]
+func DeleteLoanApplication(c *gin.Context) {
+	principal, id, ok := loanApplicationParams(c)
+	if !ok {
+		return
+	}
+
+	err := services.DeleteLoanApplication(c.Request.Context(), principal, id)
+	if respondLoanApplicationError(c, err) {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Score loan application
+// @Description Выполнить скоринг заявителя по карте и привязать запрос к заявке. Успешный ответ бюро переводит заявку
+// @Description из draft в scored; повторный скоринг допускается до решения. Согласие субъекта и квоты — как у POST /score
+// @Tags loan-applications
+// @Accept json
+// @Produce json
+// @Param id path int true "Application ID"
+// @Param score body models.LoanApplicationScoreRequest true "Скоринговая карта"
+// @Param Idempotency-Key header string false "Повтор с тем же ключом получает первый ответ, а не новый платный запрос"
+// @Success 200 {object} models.LoanApplicationDetails
+// @Failure 400 {object} map[string]string "Invalid score request"
+// @Failure 403 {object} map[string]string "No valid consent (code CONSENT_REQUIRED)"
+// @Failure 404 {object} map[string]string "Loan application not found"
+// @Failure 409 {object} map[string]string "Loan application status does not allow this action"
+// @Failure 429 {object} map[string]string "Score quota exceeded (code QUOTA_EXCEEDED)"
+// @Failure 503 {object} map[string]string "Credit bureau is temporarily unavailable"
+// @Security BearerAuth
+// @Router /loan-applications/{id}/score [post]
+func ScoreLoanApplication(c *gin.Context) {
+	principal, id, ok := loanApplicationParams(c)
+	if !ok {
+		return
+	}
+
+	var request models.LoanApplicationScoreRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	details, inquiry, err := services.ScoreLoanApplication(c.Request.Context(), c.GetHeader("Authorization"), principal, id, request)
+	markBillableInquiry(c, inquiry)
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid score request", "fields": validationErr.Fields})
+		return
+	}
+	if errors.Is(err, services.ErrConsentRequired) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": services.ConsentRequiredCode})
+		return
+	}
+	if respondQuotaExceeded(c, err) {
+		return
+	}
+	if respondBureauError(c, err) {
+		return
+	}
+	if respondLoanApplicationError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": maskPII(c, details)})
+}
+
+// @Summary Link score to loan application
+// @Description Привязать уже выполненный запрос скоринга того же субъекта. Успешный запрос переводит заявку в scored
+// @Tags loan-applications
+// @Accept json
+// @Produce json
+// @Param id path int true "Application ID"
+// @Param link body models.LoanApplicationLinkRequest true "Запрос скоринга"
+// @Success 200 {object} models.LoanApplicationDetails
+// @Failure 400 {object} map[string]string "Invalid loan application"
+// @Failure 404 {object} map[string]string "Loan application or score inquiry not found"
+// @Failure 409 {object} map[string]string "Loan application status does not allow this action"
+// @Security BearerAuth
+// @Router /loan-applications/{id}/scores [post]
+func PostLoanApplicationScore(c *gin.Context) {
+	principal, id, ok := loanApplicationParams(c)
+	if !ok {
+		return
+	}
+
+	var request models.LoanApplicationLinkRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	details, err := services.LinkLoanApplicationScore(c.Request.Context(), principal, id, request.InquiryId)
+	if errors.Is(err, services.ErrScoreInquiryNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if respondLoanApplicationError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": maskPII(c, details)})
+}
+
+// @Summary Decide loan application
+// @Description Зафиксировать решение (approve, refer или decline) по заявке в статусе scored
+// @Tags loan-applications
+// @Accept json
+// @Produce json
+// @Param id path int true "Application ID"
+// @Param decision body models.LoanApplicationDecisionRequest true "Решение"
+// @Success 200 {object} models.LoanApplicationDetails
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string "Loan application not found"
+// @Failure 409 {object} map[string]string "Loan application status does not allow this action"
+// @Security BearerAuth
+// @Router /loan-applications/{id}/decision [post]
+func DecideLoanApplication(c *gin.Context) {
+	principal, id, ok := loanApplicationParams(c)
+	if !ok {
+		return
+	}
+
+	var request models.LoanApplicationDecisionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	details, err := services.DecideLoanApplication(c.Request.Context(), principal, id, request)
+	if respondLoanApplicationError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": maskPII(c, details)})
+}
+
+// @Summary Close loan application
+// @Description Закрыть заявку на любом этапе; закрытая заявка больше не меняется
+// @Tags loan-applications
+// @Accept json
+// @Produce json
+// @Param id path int true "Application ID"
+// @Param close body models.LoanApplicationCloseRequest false "Комментарий"
+// @Success 200 {object} models.LoanApplicationDetails
+// @Failure 404 {object} map[string]string "Loan application not found"
+// @Failure 409 {object} map[string]string "Loan application status does not allow this action"
+// @Security BearerAuth
+// @Router /loan-applications/{id}/close [post]
+func CloseLoanApplication(c *gin.Context) {
+	principal, id, ok := loanApplicationParams(c)
+	if !ok {
+		return
+	}
+
+	// Тело необязательно
+	var request models.LoanApplicationCloseRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+			return
+		}
+	}
+
+	details, err := services.CloseLoanApplication(c.Request.Context(), principal, id, request.Comment)
+	if respondLoanApplicationError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": maskPII(c, details)})
+}
+
+func loanApplicationParams(c *gin.Context) (models.Principal, int64, bool) {
+	principal, ok := getPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to extract user from token"})
+		return principal, 0, false
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return principal, 0, false
+	}
+	return principal, id, true
+}
+
+// respondLoanApplicationError отвечает на ошибку сервиса заявок; false, если ошибки нет
+func respondLoanApplicationError(c *gin.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid loan application", "fields": validationErr.Fields})
+	case errors.Is(err, services.ErrLoanApplicationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": errorMessage(err)})
+	case errors.Is(err, services.ErrLoanApplicationStatus):
+		c.JSON(http.StatusConflict, gin.H{"error": errorMessage(err)})
+	default:
+		log.Printf("Ошибка обработки заявки: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+	}
+	return true
+}
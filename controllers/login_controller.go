@@ -21,7 +21,7 @@ func LoginHandler(c *gin.Context) {
 
 	// Привязка JSON-данных к структуре
 	if err := c.ShouldBindJSON(&login); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
 		return
 	}
 
@@ -29,7 +29,7 @@ func LoginHandler(c *gin.Context) {
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to login",
-			"details": err.Error(),
+			"details": errorMessage(err),
 		})
 	}
 
@@ -0,0 +1,277 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/services"
+	"net/http"
+	"strconv"
+)
+
+// @Summary Create watchlist
+// @Description Создаёт список наблюдения: ИИН/БИН заёмщиков, карта, периодичность перескоринга ("720h" или "30d") и правила
+// @Description оповещения (risk_grade_worsened, pd_band_increased, score_dropped). Роль score_monitoring или score_admin
+// @Tags monitoring
+// @Accept json
+// @Produce json
+// @Param watchlist body models.WatchlistRequest true "Watchlist"
+// @Success 201 {object} models.Watchlist
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 409 {object} map[string]string "Watchlist with this name already exists"
+// @Security BearerAuth
+// @Router /watchlists [post]
+func PostWatchlist(c *gin.Context) {
+	principal, _ := getPrincipal(c)
+
+	var request models.WatchlistRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	watchlist, err := services.CreateWatchlist(c.Request.Context(), principal, request)
+	if respondWatchlistError(c, err) {
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": watchlist})
+}
+
+// @Summary Update watchlist
+// @Description Меняет настройки списка наблюдения (состав — через /watchlists/{id}/members). Роль score_monitoring или score_admin
+// @Tags monitoring
+// @Accept json
+// @Produce json
+// @Param id path int true "Watchlist ID"
+// @Param watchlist body models.WatchlistRequest true "Watchlist"
+// @Success 200 {object} models.Watchlist
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string "Watchlist not found"
+// @Failure 409 {object} map[string]string "Watchlist with this name already exists"
+// @Security BearerAuth
+// @Router /watchlists/{id} [put]
+func PutWatchlist(c *gin.Context) {
+	principal, _ := getPrincipal(c)
+	id, ok := watchlistId(c)
+	if !ok {
+		return
+	}
+
+	var request models.WatchlistRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	watchlist, err := services.UpdateWatchlist(c.Request.Context(), principal, id, request)
+	if respondWatchlistError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": watchlist})
+}
+
+// @Summary Watchlists
+// @Description Списки наблюдения с числом субъектов и расписанием. Роль score_monitoring или score_admin
+// @Tags monitoring
+// @Produce json
+// @Success 200 {array} models.Watchlist
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Security BearerAuth
+// @Router /watchlists [get]
+func GetWatchlists(c *gin.Context) {
+	watchlists, err := services.ListWatchlists(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": watchlists})
+}
+
+// @Summary Watchlist by ID
+// @Description Список наблюдения с составом и последним результатом по каждому субъекту. Роль score_monitoring или score_admin
+// @Tags monitoring
+// @Produce json
+// @Param id path int true "Watchlist ID"
+// @Success 200 {object} models.WatchlistDetails
+// @Failure 404 {object} map[string]string "Watchlist not found"
+// @Security BearerAuth
+// @Router /watchlists/{id} [get]
+func GetWatchlistById(c *gin.Context) {
+	id, ok := watchlistId(c)
+	if !ok {
+		return
+	}
+
+	watchlist, err := services.GetWatchlist(c.Request.Context(), id)
+	if respondWatchlistError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": maskPII(c, watchlist)})
+}
+
+// @Summary Delete watchlist
+// @Description Удаляет список наблюдения вместе с его оповещениями. Роль score_monitoring или score_admin
+// @Tags monitoring
+// @Param id path int true "Watchlist ID"
+// @Success 204
+// @Failure 404 {object} map[string]string "Watchlist not found"
+// @Security BearerAuth
+// @Router /watchlists/{id} [delete]
+func DeleteWatchlist(c *gin.Context) {
+	id, ok := watchlistId(c)
+	if !ok {
+		return
+	}
+	if respondWatchlistError(c, services.DeleteWatchlist(c.Request.Context(), id)) {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Add watchlist members
+// @Description Добавляет субъектов (ИИН/БИН) в список наблюдения; уже добавленные пропускаются. Роль score_monitoring или score_admin
+// @Tags monitoring
+// @Accept json
+// @Produce json
+// @Param id path int true "Watchlist ID"
+// @Param members body models.WatchlistMembersRequest true "Subjects"
+// @Success 200 {object} map[string]int "added"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string "Watchlist not found"
+// @Security BearerAuth
+// @Router /watchlists/{id}/members [post]
+func PostWatchlistMembers(c *gin.Context) {
+	id, ok := watchlistId(c)
+	if !ok {
+		return
+	}
+
+	var request models.WatchlistMembersRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	added, err := services.AddWatchlistMembers(c.Request.Context(), id, request.SubjectIins)
+	if respondWatchlistError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"added": added})
+}
+
+// @Summary Remove watchlist member
+// @Description Исключает субъекта из списка наблюдения. Роль score_monitoring или score_admin
+// @Tags monitoring
+// @Param id path int true "Watchlist ID"
+// @Param iin path string true "ИИН/БИН субъекта"
+// @Success 204
+// @Failure 404 {object} map[string]string "Watchlist not found"
+// @Security BearerAuth
+// @Router /watchlists/{id}/members/{iin} [delete]
+func DeleteWatchlistMember(c *gin.Context) {
+	id, ok := watchlistId(c)
+	if !ok {
+		return
+	}
+	if respondWatchlistError(c, services.RemoveWatchlistMember(c.Request.Context(), id, c.Param("iin"))) {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Run watchlist now
+// @Description Ставит перескоринг списка на ближайший проход планировщика. Роль score_monitoring или score_admin
+// @Tags monitoring
+// @Param id path int true "Watchlist ID"
+// @Success 202
+// @Failure 404 {object} map[string]string "Watchlist not found"
+// @Security BearerAuth
+// @Router /watchlists/{id}/run [post]
+func RunWatchlist(c *gin.Context) {
+	id, ok := watchlistId(c)
+	if !ok {
+		return
+	}
+	if respondWatchlistError(c, services.RunWatchlistNow(c.Request.Context(), id)) {
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// @Summary Monitoring alerts
+// @Description Оповещения мониторинга портфеля с состоянием доставки, новые сверху. Роль score_monitoring или score_admin
+// @Tags monitoring
+// @Produce json
+// @Param watchlist_id query int false "Список наблюдения"
+// @Param iin query string false "ИИН/БИН субъекта"
+// @Param undelivered query bool false "Только недоставленные"
+// @Param from query string false "Начало периода (RFC3339 или YYYY-MM-DD)"
+// @Param to query string false "Конец периода (RFC3339 или YYYY-MM-DD, день включительно)"
+// @Param limit query int false "Количество записей (до 500)"
+// @Success 200 {array} models.MonitoringAlert
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /monitoring/alerts [get]
+func GetMonitoringAlerts(c *gin.Context) {
+	filter := models.MonitoringAlertFilter{
+		SubjectIin:  c.Query("iin"),
+		Undelivered: c.Query("undelivered") == "true",
+	}
+
+	var err error
+	if value := c.Query("watchlist_id"); value != "" {
+		if filter.WatchlistId, err = strconv.ParseInt(value, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid watchlist_id"})
+			return
+		}
+	}
+	if value := c.Query("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+	if filter.From, err = parseDateParam(c.Query("from"), false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if filter.To, err = parseDateParam(c.Query("to"), true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	alerts, err := services.GetMonitoringAlerts(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": maskPII(c, alerts)})
+}
+
+func watchlistId(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondWatchlistError отвечает на ошибку сервиса мониторинга; возвращает false, если ошибки нет
+func respondWatchlistError(c *gin.Context, err error) bool {
+	var validationErr *models.ValidationError
+	switch {
+	case err == nil:
+		return false
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid watchlist", "fields": validationErr.Fields})
+	case errors.Is(err, services.ErrWatchlistNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": errorMessage(err)})
+	case errors.Is(err, services.ErrWatchlistNameTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": errorMessage(err)})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+	}
+	return true
+}
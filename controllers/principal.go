@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/pii"
+)
+
+// Пользователь, сохранённый в контексте JwtMiddleware
+func getPrincipal(c *gin.Context) (models.Principal, bool) {
+	value, exists := c.Get("principal")
+	if !exists {
+		return models.Principal{}, false
+	}
+	principal, ok := value.(models.Principal)
+	return principal, ok
+}
+
+// maskPII скрывает персональные данные в ответе, если у пользователя нет роли score_pii_viewer (или score_auditor)
+func maskPII[T any](c *gin.Context, value T) T {
+	principal, _ := getPrincipal(c)
+	if principal.CanViewPII() {
+		return value
+	}
+	return pii.Mask(value)
+}
+
+// errorMessage — текст ошибки для ответа без ИИН и токенов
+func errorMessage(err error) string {
+	return pii.RedactText(err.Error())
+}
@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/services"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// respondQuotaExceeded отвечает 429, если квота исчерпана; Retry-After — секунды до сброса квоты
+func respondQuotaExceeded(c *gin.Context, err error) bool {
+	var quotaErr *models.QuotaExceededError
+	if !errors.As(err, &quotaErr) {
+		return false
+	}
+	retryAfter := int64(math.Ceil(time.Until(quotaErr.Status.ResetsAt).Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error": err.Error(),
+		"code":  services.QuotaExceededCode,
+		"quota": quotaErr.Status,
+	})
+	return true
+}
+
+// @Summary My score usage
+// @Description Квоты пользователя и его команды с остатком и использование пользователя за текущий месяц
+// @Tags usage
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Security BearerAuth
+// @Router /usage/me [get]
+func GetMyUsage(c *gin.Context) {
+	principal, ok := getPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to extract user from token"})
+		return
+	}
+
+	quotas, err := services.GetMyScoreQuotas(c.Request.Context(), principal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	usage, err := services.GetMyScoreUsage(c.Request.Context(), principal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"quotas": quotas, "usage": maskPII(c, usage)})
+}
+
+// @Summary Score quotas
+// @Description Все квоты на платные запросы скоринга
+// @Tags admin
+// @Produce json
+// @Success 200 {array} models.ScoreQuota
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Security BearerAuth
+// @Router /admin/quotas [get]
+func GetScoreQuotas(c *gin.Context) {
+	quotas, err := services.ListScoreQuotas(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": quotas})
+}
+
+// @Summary Save score quota
+// @Description Создаёт квоту или меняет лимит существующей (ключ — scope_type, scope, score_card, period). Scope "*" — квота каждому пользователю или команде
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param quota body models.ScoreQuotaRequest true "Quota"
+// @Success 200 {object} models.ScoreQuota
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Security BearerAuth
+// @Router /admin/quotas [put]
+func PutScoreQuota(c *gin.Context) {
+	principal, ok := getPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to extract user from token"})
+		return
+	}
+
+	var request models.ScoreQuotaRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	quota, err := services.PutScoreQuota(c.Request.Context(), principal, request)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": quota})
+}
+
+// @Summary Delete score quota
+// @Tags admin
+// @Param id path int true "Quota ID"
+// @Success 204
+// @Failure 404 {object} map[string]string "Score quota not found"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Security BearerAuth
+// @Router /admin/quotas/{id} [delete]
+func DeleteScoreQuota(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	err = services.DeleteScoreQuota(c.Request.Context(), id)
+	if errors.Is(err, services.ErrScoreQuotaNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Score usage
+// @Description Платные, кэшированные и неуспешные запросы скоринга по командам, пользователям, картам и провайдерам
+// @Tags admin
+// @Produce json
+// @Param from query string false "Начало периода (RFC3339 или YYYY-MM-DD)"
+// @Param to query string false "Конец периода (RFC3339 или YYYY-MM-DD, день включительно)"
+// @Param team query string false "Команда"
+// @Param user_id query string false "Пользователь"
+// @Param score_card query string false "Скоринговая карта"
+// @Success 200 {array} models.ScoreUsageRow
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Security BearerAuth
+// @Router /admin/usage [get]
+func GetScoreUsage(c *gin.Context) {
+	filter := models.ScoreUsageFilter{
+		Team:      c.Query("team"),
+		UserId:    c.Query("user_id"),
+		ScoreCard: c.Query("score_card"),
+	}
+
+	var err error
+	if filter.From, err = parseDateParam(c.Query("from"), false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if filter.To, err = parseDateParam(c.Query("to"), true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	usage, err := services.GetScoreUsage(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": maskPII(c, usage)})
+}
+
+// @Summary Monthly score billing
+// @Description Платные запросы за месяц по департаментам, картам и провайдерам в CSV (format=json — в JSON). Сумма считается по ценам из SCORE_CARD_PRICES
+// @Tags admin
+// @Produce text/csv
+// @Produce json
+// @Param month query string false "Месяц YYYY-MM, по умолчанию текущий"
+// @Param format query string false "csv (по умолчанию) или json"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Security BearerAuth
+// @Router /admin/usage/billing [get]
+func GetScoreBilling(c *gin.Context) {
+	month := c.Query("month")
+	rows, err := services.GetScoreBilling(c.Request.Context(), month)
+	if errors.Is(err, services.ErrInvalidBillingMonth) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	if c.Query("format") == "json" {
+		c.JSON(http.StatusOK, gin.H{"data": rows})
+		return
+	}
+	filename := "score-billing.csv"
+	if len(rows) > 0 {
+		filename = "score-billing-" + rows[0].Month + ".csv"
+	}
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	if err := services.WriteScoreBillingCSV(c.Writer, rows); err != nil {
+		log.Printf("Ошибка выгрузки счёта за скоринг: %v", err)
+	}
+}
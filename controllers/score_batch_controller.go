@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go-keycloak-jwt/services"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// @Summary Create score batch
+// @Description Загружает CSV или XLSX со списком субъектов и создаёт задание пакетного скоринга. Задание обрабатывается асинхронно
+// @Tags score-batches
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV или XLSX: колонка с заголовком атрибута или первая колонка"
+// @Param score_card formData string true "Скоринговая карта"
+// @Param attribute formData string false "Имя атрибута карты (по умолчанию IIN)"
+// @Param Idempotency-Key header string false "Повтор с тем же ключом и файлом получает первый ответ, а не новое задание"
+// @Success 202 {object} models.ScoreBatch
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 413 {object} map[string]string "Request body is too large"
+// @Failure 409 {object} map[string]string "Request with this Idempotency-Key is still in progress"
+// @Failure 422 {object} map[string]string "Idempotency-Key was already used with a different request"
+// @Security BearerAuth
+// @Router /score-batches [post]
+func PostScoreBatch(c *gin.Context) {
+	principal, ok := getPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to extract user from token"})
+		return
+	}
+
+	scoreCard := c.PostForm("score_card")
+	if scoreCard == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "score_card is required"})
+		return
+	}
+	attributeName := c.DefaultPostForm("attribute", "IIN")
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	subjects, err := services.ParseScoreBatchFile(fileHeader.Filename, file, attributeName)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	batch, err := services.CreateScoreBatch(c.Request.Context(), principal, scoreCard, attributeName,
+		fileHeader.Filename, subjects)
+	if err != nil {
+		log.Printf("Ошибка создания пакетного задания: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create score batch"})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"data": batch})
+}
+
+// @Summary Score batch progress
+// @Description Состояние задания пакетного скоринга: статус и число обработанных строк
+// @Tags score-batches
+// @Produce json
+// @Param id path int true "Batch ID"
+// @Success 200 {object} models.ScoreBatch
+// @Failure 404 {object} map[string]string "Score batch not found"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Security BearerAuth
+// @Router /score-batches/{id} [get]
+func GetScoreBatch(c *gin.Context) {
+	principal, ok := getPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to extract user from token"})
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	batch, err := services.GetScoreBatchById(c.Request.Context(), principal, id)
+	if errors.Is(err, services.ErrScoreBatchNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": batch})
+}
+
+// @Summary Download score batch results
+// @Description Результаты пакетного скоринга в CSV или XLSX, включая ошибки по каждой строке
+// @Tags score-batches
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param id path int true "Batch ID"
+// @Param format query string false "csv (по умолчанию) или xlsx"
+// @Success 200 {file} file
+// @Failure 404 {object} map[string]string "Score batch not found"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Security BearerAuth
+// @Router /score-batches/{id}/results [get]
+func GetScoreBatchResults(c *gin.Context) {
+	principal, ok := getPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to extract user from token"})
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or xlsx"})
+		return
+	}
+
+	results, err := services.GetScoreBatchResults(c.Request.Context(), principal, id)
+	if errors.Is(err, services.ErrScoreBatchNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	results = maskPII(c, results)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=score-batch-%d.%s", id, format))
+	if format == "xlsx" {
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		err = services.WriteScoreBatchResultsXLSX(c.Writer, results)
+	} else {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		err = services.WriteScoreBatchResultsCSV(c.Writer, results)
+	}
+	if err != nil {
+		log.Printf("Ошибка выгрузки результатов пакета %d: %v", id, err)
+	}
+}
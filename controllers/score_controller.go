@@ -1,62 +1,18 @@
 package controllers
 
 import (
-	"bytes"
 	"encoding/json"
-	"encoding/xml"
-	"fmt"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/services"
 	"log"
 	"net/http"
 )
 
-const GetScoreCardsXml = `
-<S:Envelope
-	xmlns:S="http://schemas.xmlsoap.org/soap/envelope/">
-	<S:Body>
-		<GetScoreCardsResponse
-			xmlns="http://score.ws.creditinfo.com/">
-			<return>
-				<attributes>
-					<name>IIN</name>
-				</attributes>
-				<name> BehaviorScoring</name>
-			</return>
-		</GetScoreCardsResponse>
-	</S:Body>
-</S:Envelope>
-`
-
-const ScoreXml = `
-<S:Envelope
-	xmlns:S="http://schemas.xmlsoap.org/soap/envelope/">
-	<S:Body>
-		<ScoreResponse
-			xmlns="http://score.ws.creditinfo.com/">
-			<return>
-				<IdQuery>3619</IdQuery>
-				<ErrorCode>0</ErrorCode>
-				<ErrorString>Выполнено успешно</ErrorString>
-				<Score>73.0</Score>
-				<OneYearProbabilityOfDefault>2% - 3%</OneYearProbabilityOfDefault>
-				<RiskGrade/>
-				<ScoreByML>444.0</ScoreByML>
-				<OneYearProbabilityOfDefaultByML>10% - 15%</OneYearProbabilityOfDefaultByML>
-				<RiskGradeByML>B1</RiskGradeByML>
-				<Causes>
-					<name>Test</name>
-					<causeText>Пример сообщения о низком балле</causeText>
-				</Causes>
-			</return>
-		</ScoreResponse>
-	</S:Body>
-</S:Envelope>
-
-`
-
 // @Summary Get score cards static
-// @Description Получить статические карточки с результатами
+// @Description Получить скоринговые карты всех провайдеров. response — прежний формат Creditinfo,
+// @Description score_cards — нормализованный список с провайдером каждой карты
 // @Tags scores
 // @Produce json
 // @Accept json
@@ -65,82 +21,44 @@ const ScoreXml = `
 // @Success 200 {object} models.ScoreCardsRequest
 // @Failure 404 {object} map[string]string "ScoreCardsRequest not found"
 // @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "No bureau credentials configured"
+// @Failure 503 {object} map[string]string "Credit bureau is temporarily unavailable"
 // @Security BearerAuth
 // @Router /get-score-cards [post]
 func GetScoreCards(c *gin.Context) {
 	tokenString := c.GetHeader("Authorization")
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to extract user ID from token"})
-		return
-	}
-	// Преобразуем userID в строку
-	userIDStr, ok := userID.(string)
+	principal, ok := getPrincipal(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid userID type"})
-		return
-	}
-
-	userName, exists := c.Get("userName")
-	if !exists {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to extract user name from token"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to extract user from token"})
 		return
 	}
 
-	userNameStr, ok := userName.(string)
-
 	var scoreCards models.ScoreCardsRequest
 
 	// Привязка JSON-данных к структуре
 	if err := c.ShouldBindJSON(&scoreCards); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
 		return
 	}
 
-	// Преобразуем тело в JSON
-	jsonBody, err := json.Marshal(scoreCards)
-	if err != nil {
-		log.Printf("Ошибка при преобразовании тела в JSON: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create body JSON"})
+	cards, err := services.GetScoreCards(c.Request.Context(), tokenString, principal, scoreCards)
+	if respondBureauError(c, err) {
 		return
 	}
-
-	req, err := http.NewRequest("POST", "http://example.com/your-endpoint", bytes.NewBuffer(jsonBody))
 	if err != nil {
-		log.Printf("Ошибка при создании запроса: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create body JSON"})
+		log.Printf("Ошибка получения скоринговых карт: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
 		return
 	}
-	req.Header.Set("Content-Type", "application/xml")
-	req.Header.Set("Culture", "ru-RU")
-	req.Header.Set("Password", "741102401283")
-	req.Header.Set("SecurityToken", tokenString)
-	req.Header.Set("UserId", userIDStr)
-	req.Header.Set("UserName", userNameStr)
-	req.Header.Set("Version", "1")
-
-	fmt.Println("-----------")
-	fmt.Println(tokenString)
-	fmt.Println("-----------")
-	fmt.Println(userIDStr)
-	fmt.Println("-----------")
-	fmt.Println(userNameStr)
-	fmt.Println("-----------")
-
-	// Читаем XML-ответ
-	xmlResponse := []byte(GetScoreCardsXml)
-
-	// Парсим XML-ответ
-	var envelope models.ScoreCardsEnvelopeXml
-	err = xml.Unmarshal(xmlResponse, &envelope)
-	if err != nil {
-		log.Printf("Ошибка парсинга XML: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse XML"})
-		return
+
+	// Прежний формат response — список карт в виде ответа Creditinfo
+	legacy := make([]models.ScoreCardsReturnDataXml, 0, len(cards))
+	for _, card := range cards {
+		legacy = append(legacy, card.Xml())
 	}
 
 	// Преобразуем в JSON
-	jsonResponse, err := json.Marshal(envelope.Body.GetScoreCardsResponse.ReturnData)
+	jsonResponse, err := json.Marshal(legacy)
 	if err != nil {
 		log.Printf("Ошибка преобразования в JSON: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to convert to JSON"})
@@ -148,95 +66,83 @@ func GetScoreCards(c *gin.Context) {
 	}
 
 	// Отправляем JSON-ответ клиенту
-	c.JSON(http.StatusOK, gin.H{"response": string(jsonResponse)})
+	c.JSON(http.StatusOK, gin.H{"response": string(jsonResponse), "score_cards": cards})
 }
 
 // @Summary Get score static
-// @Description Получить статические результаты
+// @Description Выполнить скоринг у провайдера карты. response — прежний формат Creditinfo,
+// @Description result — нормализованный результат с именем провайдера
 // @Tags scores
 // @Produce json
 // @Accept json
 // @Produce json
 // @Param login body models.ScoreRequest true "ScoreRequest"
+// @Param refresh query bool false "Игнорировать кэш и запросить бюро заново (роль score_cache_bypass)"
+// @Param signed query bool false "Добавить signed_result — компактный JWS (RS256) над результатом; ключи в /.well-known/jwks.json"
+// @Param Idempotency-Key header string false "Повтор с тем же ключом получает первый ответ, а не новый платный запрос"
 // @Success 200 {object} models.ScoreRequest
 // @Failure 404 {object} map[string]string "ScoreRequest not found"
 // @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "No bureau credentials configured or no valid consent (code CONSENT_REQUIRED)"
+// @Failure 409 {object} map[string]string "Request with this Idempotency-Key is still in progress"
+// @Failure 422 {object} map[string]string "Idempotency-Key was already used with a different request"
+// @Failure 429 {object} map[string]string "Score quota exceeded (code QUOTA_EXCEEDED)"
+// @Failure 503 {object} map[string]string "Credit bureau is temporarily unavailable"
 // @Security BearerAuth
 // @Router /score [post]
 func PostScore(c *gin.Context) {
 	tokenString := c.GetHeader("Authorization")
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to extract user ID from token"})
-		return
-	}
-	// Преобразуем userID в строку
-	userIDStr, ok := userID.(string)
+	principal, ok := getPrincipal(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid userID type"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to extract user from token"})
 		return
 	}
 
-	userName, exists := c.Get("userName")
-	if !exists {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to extract user name from token"})
-		return
-	}
-
-	userNameStr, ok := userName.(string)
-
 	var score models.ScoreRequest
 
 	// Привязка JSON-данных к структуре
 	if err := c.ShouldBindJSON(&score); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
 		return
 	}
 
-	// Преобразуем тело в JSON
-	jsonBody, err := json.Marshal(score)
-	if err != nil {
-		log.Printf("Ошибка при преобразовании тела в JSON: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create body JSON"})
+	options := services.ScoreOptions{ForceRefresh: c.Query("refresh") == "true", Shadow: true}
+	if options.ForceRefresh && !principal.HasRole(models.RoleCacheBypass) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not allowed to bypass the score cache"})
+		return
+	}
+	// Подпись проверяется заранее: после запроса в бюро отказывать уже поздно
+	signed := c.Query("signed") == "true"
+	if signed && !services.ServiceSigningEnabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Response signing is not configured"})
 		return
 	}
 
-	req, err := http.NewRequest("POST", "http://example.com/your-endpoint", bytes.NewBuffer(jsonBody))
-	if err != nil {
-		log.Printf("Ошибка при создании запроса: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create body JSON"})
+	result, inquiry, err := services.Score(c.Request.Context(), tokenString, principal, score, options)
+	markBillableInquiry(c, inquiry)
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid score request", "fields": validationErr.Fields})
+		return
+	}
+	if errors.Is(err, services.ErrConsentRequired) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": services.ConsentRequiredCode})
+		return
+	}
+	if respondQuotaExceeded(c, err) {
+		return
+	}
+	if respondBureauError(c, err) {
 		return
 	}
-	req.Header.Set("Content-Type", "application/xml")
-	req.Header.Set("Culture", "ru-RU")
-	req.Header.Set("Password", "741102401283")
-	req.Header.Set("SecurityToken", tokenString)
-	req.Header.Set("UserId", userIDStr)
-	req.Header.Set("UserName", userNameStr)
-	req.Header.Set("Version", "1")
-
-	fmt.Println("-----------")
-	fmt.Println(tokenString)
-	fmt.Println("-----------")
-	fmt.Println(userIDStr)
-	fmt.Println("-----------")
-	fmt.Println(userNameStr)
-	fmt.Println("-----------")
-
-	// Читаем XML-ответ
-	xmlResponse := []byte(ScoreXml)
-
-	// Парсим XML-ответ
-	var envelope models.ScoreResponseXml
-	err = xml.Unmarshal(xmlResponse, &envelope)
 	if err != nil {
-		log.Printf("Ошибка парсинга XML: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse XML"})
+		log.Printf("Ошибка скоринга: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err), "inquiry_id": inquiry.Id})
 		return
 	}
 
 	// Преобразуем в JSON
-	jsonResponse, err := json.Marshal(envelope)
+	jsonResponse, err := json.Marshal(result.Envelope())
 	if err != nil {
 		log.Printf("Ошибка преобразования в JSON: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to convert to JSON"})
@@ -244,5 +150,30 @@ func PostScore(c *gin.Context) {
 	}
 
 	// Отправляем JSON-ответ клиенту
-	c.JSON(http.StatusOK, gin.H{"response": string(jsonResponse)})
+	response := gin.H{
+		"response":    string(jsonResponse),
+		"result":      result,
+		"inquiry_id":  inquiry.Id,
+		"cached":      inquiry.CachedFromId != nil,
+		"inquired_at": inquiry.InquiredAt,
+		"decision":    services.ScoreDecisionOf(inquiry),
+	}
+	if signed {
+		jws, err := services.SignScoreResult(principal, inquiry, result)
+		if err != nil {
+			log.Printf("Ошибка подписи результата скоринга: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign score result", "inquiry_id": inquiry.Id})
+			return
+		}
+		response["signed_result"] = jws
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// markBillableInquiry отмечает, что запрос к провайдеру оплачен: Idempotency сохранит даже ответ 5xx,
+// чтобы повтор с тем же ключом не оплачивался снова
+func markBillableInquiry(c *gin.Context, inquiry models.ScoreInquiry) {
+	if inquiry.Billable() {
+		c.Set("billable_inquiry", true)
+	}
 }
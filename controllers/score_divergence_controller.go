@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/services"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// @Summary Classic vs ML divergence
+// @Description Как часто классы риска классического (RiskGrade) и ML-результата (RiskGradeByML) расходятся и на сколько позиций
+// @Description шкалы MONITORING_RISK_GRADES: в целом, по картам, по периодам, распределение пар классов и случаи с наибольшим
+// @Description расхождением. Учитываются успешные запросы не из кэша; без одного из классов — как несравнимые.
+// @Description Роль score_analyst или score_admin
+// @Tags analytics
+// @Produce json
+// @Param score_card query string false "Скоринговая карта"
+// @Param provider query string false "Провайдер"
+// @Param from query string false "Начало периода (RFC3339 или YYYY-MM-DD)"
+// @Param to query string false "Конец периода (RFC3339 или YYYY-MM-DD, день включительно)"
+// @Param interval query string false "Шаг динамики: day (по умолчанию), week или month"
+// @Param top query int false "Количество случаев с наибольшим расхождением (до 100)"
+// @Success 200 {object} models.ScoreDivergenceReport
+// @Failure 400 {object} map[string]string "Invalid score divergence filter"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /analytics/score-divergence [get]
+func GetScoreDivergence(c *gin.Context) {
+	filter, ok := bindScoreDivergenceFilter(c)
+	if !ok {
+		return
+	}
+	filter.Interval = c.Query("interval")
+	if top := c.Query("top"); top != "" {
+		var err error
+		if filter.Top, err = strconv.Atoi(top); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid top"})
+			return
+		}
+	}
+
+	report, err := services.GetScoreDivergenceReport(c.Request.Context(), filter)
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid score divergence filter", "fields": validationErr.Fields})
+		return
+	}
+	if err != nil {
+		log.Printf("Ошибка анализа расхождений скоринга: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": maskPII(c, report)})
+}
+
+// @Summary Export classic vs ML divergence
+// @Description Запросы с классическим и ML-результатом в CSV для команды валидации моделей, сначала с наибольшей разницей классов
+// @Description (не более 100000 строк). Роль score_analyst или score_admin
+// @Tags analytics
+// @Produce text/csv
+// @Param score_card query string false "Скоринговая карта"
+// @Param provider query string false "Провайдер"
+// @Param from query string false "Начало периода (RFC3339 или YYYY-MM-DD)"
+// @Param to query string false "Конец периода (RFC3339 или YYYY-MM-DD, день включительно)"
+// @Param mismatches_only query bool false "Только запросы с разными классами риска"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Security BearerAuth
+// @Router /analytics/score-divergence/export [get]
+func ExportScoreDivergence(c *gin.Context) {
+	filter, ok := bindScoreDivergenceFilter(c)
+	if !ok {
+		return
+	}
+	filter.MismatchesOnly = c.Query("mismatches_only") == "true"
+
+	cases, err := services.GetScoreDivergenceCases(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=score-divergence.csv")
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	if err := services.WriteScoreDivergenceCSV(c.Writer, maskPII(c, cases)); err != nil {
+		log.Printf("Ошибка выгрузки расхождений скоринга: %v", err)
+	}
+}
+
+func bindScoreDivergenceFilter(c *gin.Context) (models.ScoreDivergenceFilter, bool) {
+	filter := models.ScoreDivergenceFilter{
+		ScoreCard: c.Query("score_card"),
+		Provider:  c.Query("provider"),
+	}
+
+	var err error
+	if filter.From, err = parseDateParam(c.Query("from"), false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return filter, false
+	}
+	if filter.To, err = parseDateParam(c.Query("to"), true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return filter, false
+	}
+	return filter, true
+}
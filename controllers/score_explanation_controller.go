@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go-keycloak-jwt/i18n"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/services"
+	"net/http"
+	"strconv"
+)
+
+// @Summary Score explanation
+// @Description Причины результата скоринга по убыванию значимости (серьёзность из каталога кодов причин) с текстом для письма заявителю об отказе. Коды вне каталога идут последними с текстом бюро
+// @Tags scores
+// @Produce json
+// @Param id path int true "Inquiry ID"
+// @Param lang query string false "Язык текстов: ru, kk или en (по умолчанию — язык запроса)"
+// @Param top query int false "Сколько причин вернуть (по умолчанию все)"
+// @Success 200 {object} models.ScoreExplanation
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string "Score inquiry not found"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Security BearerAuth
+// @Router /scores/{id}/explanation [get]
+func GetScoreExplanation(c *gin.Context) {
+	principal, ok := getPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to extract user from token"})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	locale := c.GetString("locale")
+	if lang := c.Query("lang"); lang != "" {
+		if locale = i18n.Normalize(lang); locale == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lang"})
+			return
+		}
+	}
+	if locale == "" {
+		locale = i18n.DefaultLocale()
+	}
+	top := 0
+	if value := c.Query("top"); value != "" {
+		if top, err = strconv.Atoi(value); err != nil || top < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid top"})
+			return
+		}
+	}
+
+	explanation, err := services.GetScoreExplanation(c.Request.Context(), principal, id, locale, top)
+	if errors.Is(err, services.ErrScoreInquiryNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": maskPII(c, explanation)})
+}
+
+// @Summary Reason codes
+// @Description Каталог кодов причин скоринга
+// @Tags admin
+// @Produce json
+// @Success 200 {array} models.ReasonCode
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Security BearerAuth
+// @Router /admin/reason-codes [get]
+func GetReasonCodes(c *gin.Context) {
+	codes, err := services.ListReasonCodes(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": codes})
+}
+
+// @Summary Save reason code
+// @Description Добавляет код причины в каталог или заменяет его: серьёзность 1–5, текст для клиента на ru/kk/en, пояснение для сотрудников
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param reason_code body models.ReasonCodeRequest true "Reason code"
+// @Success 200 {object} models.ReasonCode
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Security BearerAuth
+// @Router /admin/reason-codes [put]
+func PutReasonCode(c *gin.Context) {
+	principal, ok := getPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to extract user from token"})
+		return
+	}
+
+	var request models.ReasonCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	code, err := services.PutReasonCode(c.Request.Context(), principal, request)
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reason code", "fields": validationErr.Fields})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": code})
+}
+
+// @Summary Delete reason code
+// @Tags admin
+// @Param code path string true "Reason code"
+// @Success 204
+// @Failure 404 {object} map[string]string "Reason code not found"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Security BearerAuth
+// @Router /admin/reason-codes/{code} [delete]
+func DeleteReasonCode(c *gin.Context) {
+	err := services.DeleteReasonCode(c.Request.Context(), c.Param("code"))
+	if errors.Is(err, services.ErrReasonCodeNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
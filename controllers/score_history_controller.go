@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/services"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// @Summary Score inquiry history
+// @Description История запросов скоринга с фильтрами и курсорной пагинацией. Супервизор видит запросы своей команды, остальные — только свои
+// @Tags scores
+// @Produce json
+// @Param iin query string false "ИИН субъекта"
+// @Param user_id query string false "Пользователь, выполнивший запрос"
+// @Param score_card query string false "Скоринговая карта"
+// @Param risk_grade query string false "Класс риска (классический или ML)"
+// @Param error_code query string false "Код ошибки бюро"
+// @Param from query string false "Начало периода (RFC3339 или YYYY-MM-DD)"
+// @Param to query string false "Конец периода (RFC3339 или YYYY-MM-DD, день включительно)"
+// @Param sort query string false "Поле сортировки: created_at или latency_ms"
+// @Param order query string false "Направление сортировки: asc или desc"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Param limit query int false "Размер страницы (до 100)"
+// @Success 200 {object} models.ScoreInquiryPage
+// @Failure 400 {object} map[string]string "Invalid score history filter"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /scores [get]
+func GetScores(c *gin.Context) {
+	principal, ok := getPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to extract user from token"})
+		return
+	}
+
+	filter := models.ScoreInquiryFilter{
+		SubjectIin: c.Query("iin"),
+		UserId:     c.Query("user_id"),
+		ScoreCard:  c.Query("score_card"),
+		RiskGrade:  c.Query("risk_grade"),
+		ErrorCode:  c.Query("error_code"),
+		Sort:       c.Query("sort"),
+		Order:      c.Query("order"),
+		Cursor:     c.Query("cursor"),
+	}
+
+	var err error
+	if filter.From, err = parseDateParam(c.Query("from"), false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if filter.To, err = parseDateParam(c.Query("to"), true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+
+	page, err := services.GetScoreInquiries(c.Request.Context(), principal, filter)
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid score history filter", "fields": validationErr.Fields})
+		return
+	}
+	if err != nil {
+		log.Printf("Ошибка поиска в истории скоринга: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, maskPII(c, page))
+}
+
+// @Summary Score inquiry by ID
+// @Description Полная информация о запросе скоринга, включая причины
+// @Tags scores
+// @Produce json
+// @Param id path int true "Inquiry ID"
+// @Success 200 {object} models.ScoreInquiry
+// @Failure 404 {object} map[string]string "Score inquiry not found"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Security BearerAuth
+// @Router /scores/{id} [get]
+func GetScoreById(c *gin.Context) {
+	principal, ok := getPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to extract user from token"})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	inquiry, err := services.GetScoreInquiryById(c.Request.Context(), principal, id)
+	if errors.Is(err, services.ErrScoreInquiryNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": maskPII(c, inquiry)})
+}
+
+// Дата из query-параметра: RFC3339 или YYYY-MM-DD.
+// Для конца периода дата без времени включает весь день.
+func parseDateParam(value string, endOfPeriod bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date: %s", value)
+	}
+	if endOfPeriod {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
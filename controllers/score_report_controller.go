@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/services"
+	"net/http"
+	"strconv"
+)
+
+// @Summary Score report
+// @Description Печатный отчёт по запросу скоринга: субъект, баллы и классы риска классической и ML-модели, PD, причины, IdQuery, офицер и время формирования. ИИН маскируется без роли score_pii_viewer
+// @Tags scores
+// @Produce application/pdf
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param id path int true "Inquiry ID"
+// @Param format query string false "pdf (по умолчанию) или xlsx"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string "Score inquiry not found"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Security BearerAuth
+// @Router /scores/{id}/report [get]
+func GetScoreReport(c *gin.Context) {
+	principal, ok := getPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to extract user from token"})
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	format := c.DefaultQuery("format", "pdf")
+	if format != "pdf" && format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be pdf or xlsx"})
+		return
+	}
+
+	inquiry, err := services.GetScoreInquiryById(c.Request.Context(), principal, id)
+	if errors.Is(err, services.ErrScoreInquiryNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	report, err := services.RenderScoreReport(c.Request.Context(), principal, maskPII(c, inquiry), format)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	contentType := "application/pdf"
+	if format == "xlsx" {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=score-report-%d.%s", id, format))
+	c.Data(http.StatusOK, contentType, report)
+}
+
+// @Summary Get score report template
+// @Description Текущее оформление отчёта о скоринге (шаблон по умолчанию, если своё не загружено). Роль score_admin
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.ReportTemplate
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Security BearerAuth
+// @Router /admin/report-template [get]
+func GetScoreReportTemplate(c *gin.Context) {
+	tpl, err := services.GetScoreReportTemplate(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": tpl})
+}
+
+// @Summary Replace score report template
+// @Description Заменяет оформление отчёта: заголовок, бренд, цвет, логотип (PNG в base64), подвал (text/template), подписи и диапазоны PD. Тело — JSON или YAML. Роль score_admin
+// @Tags admin
+// @Accept json
+// @Accept application/yaml
+// @Produce json
+// @Param template body models.ReportTemplate true "Report template"
+// @Success 200 {object} models.ReportTemplate
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Security BearerAuth
+// @Router /admin/report-template [put]
+func PutScoreReportTemplate(c *gin.Context) {
+	principal, _ := getPrincipal(c)
+
+	var tpl models.ReportTemplate
+	if err := bindJSONOrYAML(c, &tpl); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	saved, err := services.SaveScoreReportTemplate(c.Request.Context(), principal, tpl)
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report template", "fields": validationErr.Fields})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": saved})
+}
+
+// @Summary Reset score report template
+// @Description Возвращает оформление отчёта по умолчанию. Роль score_admin
+// @Tags admin
+// @Success 204
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Security BearerAuth
+// @Router /admin/report-template [delete]
+func DeleteScoreReportTemplate(c *gin.Context) {
+	if err := services.ResetScoreReportTemplate(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
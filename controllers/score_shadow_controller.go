@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/services"
+	"net/http"
+)
+
+// @Summary Shadow scoring comparison
+// @Description Сравнение теневого провайдера с основным по каждой паре провайдеров и карте: доля совпадений класса риска
+// @Description и разница баллов (теневой − основной) по запросам, где оба ответили без ошибки. Роль score_admin
+// @Tags admin
+// @Produce json
+// @Param provider query string false "Основной провайдер"
+// @Param shadow_provider query string false "Теневой провайдер"
+// @Param score_card query string false "Скоринговая карта основного запроса"
+// @Param from query string false "Начало периода (RFC3339 или YYYY-MM-DD)"
+// @Param to query string false "Конец периода (RFC3339 или YYYY-MM-DD, день включительно)"
+// @Success 200 {array} models.ScoreShadowComparison
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Security BearerAuth
+// @Router /admin/score-shadow/comparison [get]
+func GetScoreShadowComparison(c *gin.Context) {
+	filter := models.ScoreShadowFilter{
+		Provider:       c.Query("provider"),
+		ShadowProvider: c.Query("shadow_provider"),
+		ScoreCard:      c.Query("score_card"),
+	}
+
+	var err error
+	if filter.From, err = parseDateParam(c.Query("from"), false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+	if filter.To, err = parseDateParam(c.Query("to"), true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+		return
+	}
+
+	comparisons, err := services.GetScoreShadowComparison(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": comparisons})
+}
@@ -0,0 +1,321 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// Схема таблиц, которыми владеет сервис. Выражения идемпотентны и выполняются
+// при каждом старте, поэтому новые изменения добавляются только в конец списка.
+var migrations = []string{
+	`CREATE TABLE IF NOT EXISTS score_inquiries (
+		id BIGSERIAL PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		user_name TEXT NOT NULL,
+		score_card TEXT NOT NULL,
+		attributes JSONB NOT NULL DEFAULT '{}'::jsonb,
+		id_query TEXT NOT NULL DEFAULT '',
+		error_code TEXT NOT NULL DEFAULT '',
+		error_string TEXT NOT NULL DEFAULT '',
+		score TEXT NOT NULL DEFAULT '',
+		one_year_probability_of_default TEXT NOT NULL DEFAULT '',
+		risk_grade TEXT NOT NULL DEFAULT '',
+		score_by_ml TEXT NOT NULL DEFAULT '',
+		one_year_probability_of_default_by_ml TEXT NOT NULL DEFAULT '',
+		risk_grade_by_ml TEXT NOT NULL DEFAULT '',
+		latency_ms BIGINT NOT NULL DEFAULT 0,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS score_inquiries_user_id_idx ON score_inquiries (user_id, created_at)`,
+	`CREATE INDEX IF NOT EXISTS score_inquiries_score_card_idx ON score_inquiries (score_card, created_at)`,
+	`CREATE TABLE IF NOT EXISTS score_inquiry_causes (
+		id BIGSERIAL PRIMARY KEY,
+		inquiry_id BIGINT NOT NULL REFERENCES score_inquiries (id) ON DELETE CASCADE,
+		position INT NOT NULL,
+		name TEXT NOT NULL DEFAULT '',
+		cause_text TEXT NOT NULL DEFAULT ''
+	)`,
+	`CREATE INDEX IF NOT EXISTS score_inquiry_causes_inquiry_id_idx ON score_inquiry_causes (inquiry_id)`,
+	`ALTER TABLE score_inquiries ADD COLUMN IF NOT EXISTS team TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE score_inquiries ADD COLUMN IF NOT EXISTS subject_iin TEXT NOT NULL DEFAULT ''`,
+	`CREATE INDEX IF NOT EXISTS score_inquiries_subject_iin_idx ON score_inquiries (subject_iin, created_at)`,
+	`CREATE INDEX IF NOT EXISTS score_inquiries_team_idx ON score_inquiries (team, created_at)`,
+	`CREATE TABLE IF NOT EXISTS score_batches (
+		id BIGSERIAL PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		user_name TEXT NOT NULL,
+		team TEXT NOT NULL DEFAULT '',
+		score_card TEXT NOT NULL,
+		attribute_name TEXT NOT NULL,
+		file_name TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL,
+		total INT NOT NULL DEFAULT 0,
+		processed INT NOT NULL DEFAULT 0,
+		failed INT NOT NULL DEFAULT 0,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		finished_at TIMESTAMPTZ
+	)`,
+	`CREATE TABLE IF NOT EXISTS score_batch_items (
+		id BIGSERIAL PRIMARY KEY,
+		batch_id BIGINT NOT NULL REFERENCES score_batches (id) ON DELETE CASCADE,
+		row_number INT NOT NULL,
+		subject TEXT NOT NULL,
+		status TEXT NOT NULL,
+		inquiry_id BIGINT REFERENCES score_inquiries (id),
+		error TEXT NOT NULL DEFAULT ''
+	)`,
+	`CREATE INDEX IF NOT EXISTS score_batch_items_batch_id_idx ON score_batch_items (batch_id, status)`,
+	`ALTER TABLE score_inquiries ADD COLUMN IF NOT EXISTS cached_from_id BIGINT REFERENCES score_inquiries (id)`,
+	`ALTER TABLE score_inquiries ADD COLUMN IF NOT EXISTS inquired_at TIMESTAMPTZ NOT NULL DEFAULT now()`,
+	`CREATE TABLE IF NOT EXISTS score_cache (
+		cache_key TEXT PRIMARY KEY,
+		score_card TEXT NOT NULL,
+		response JSONB NOT NULL,
+		inquiry_id BIGINT NOT NULL,
+		inquired_at TIMESTAMPTZ NOT NULL,
+		expires_at TIMESTAMPTZ NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS score_cache_expires_at_idx ON score_cache (expires_at)`,
+	`CREATE TABLE IF NOT EXISTS bureau_credentials (
+		id BIGSERIAL PRIMARY KEY,
+		subject_type TEXT NOT NULL,
+		subject TEXT NOT NULL DEFAULT '',
+		user_name TEXT NOT NULL,
+		password_enc BYTEA NOT NULL,
+		culture TEXT NOT NULL DEFAULT 'ru-RU',
+		version TEXT NOT NULL DEFAULT '1',
+		updated_by TEXT NOT NULL DEFAULT '',
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (subject_type, subject)
+	)`,
+	`CREATE TABLE IF NOT EXISTS bureau_traffic (
+		id BIGSERIAL PRIMARY KEY,
+		operation TEXT NOT NULL,
+		match_key TEXT NOT NULL,
+		attributes JSONB NOT NULL DEFAULT '{}'::jsonb,
+		request_xml TEXT NOT NULL,
+		response_xml TEXT NOT NULL,
+		status_code INT NOT NULL,
+		headers JSONB NOT NULL DEFAULT '{}'::jsonb,
+		recorded_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS bureau_traffic_match_key_idx ON bureau_traffic (match_key, recorded_at)`,
+	`CREATE TABLE IF NOT EXISTS bureau_archive (
+		id BIGSERIAL PRIMARY KEY,
+		inquiry_id BIGINT NOT NULL UNIQUE REFERENCES score_inquiries (id),
+		operation TEXT NOT NULL,
+		status_code INT NOT NULL,
+		key_id TEXT NOT NULL,
+		wrapped_key BYTEA NOT NULL,
+		ciphertext BYTEA NOT NULL,
+		sha256 TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE TABLE IF NOT EXISTS bureau_archive_access (
+		id BIGSERIAL PRIMARY KEY,
+		archive_id BIGINT NOT NULL REFERENCES bureau_archive (id),
+		inquiry_id BIGINT NOT NULL,
+		user_id TEXT NOT NULL,
+		user_name TEXT NOT NULL,
+		reason TEXT NOT NULL DEFAULT '',
+		accessed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS bureau_archive_access_inquiry_id_idx ON bureau_archive_access (inquiry_id, accessed_at)`,
+	`CREATE TABLE IF NOT EXISTS decision_rule_sets (
+		version INT PRIMARY KEY,
+		name TEXT NOT NULL,
+		definition JSONB NOT NULL,
+		active BOOLEAN NOT NULL DEFAULT false,
+		created_by TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS decision_rule_sets_active_idx ON decision_rule_sets (active) WHERE active`,
+	`ALTER TABLE score_inquiries ADD COLUMN IF NOT EXISTS decision TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE score_inquiries ADD COLUMN IF NOT EXISTS decision_rule TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE score_inquiries ADD COLUMN IF NOT EXISTS decision_rules_version INT`,
+	`CREATE TABLE IF NOT EXISTS consents (
+		id BIGSERIAL PRIMARY KEY,
+		subject_iin TEXT NOT NULL,
+		scope TEXT[] NOT NULL,
+		channel TEXT NOT NULL,
+		granted_at TIMESTAMPTZ NOT NULL,
+		expires_at TIMESTAMPTZ NOT NULL,
+		document_ref TEXT NOT NULL DEFAULT '',
+		created_by TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		revoked_at TIMESTAMPTZ,
+		revoked_by TEXT NOT NULL DEFAULT '',
+		revoke_reason TEXT NOT NULL DEFAULT ''
+	)`,
+	`CREATE INDEX IF NOT EXISTS consents_subject_iin_idx ON consents (subject_iin, expires_at)`,
+	`ALTER TABLE score_inquiries ADD COLUMN IF NOT EXISTS consent_id BIGINT REFERENCES consents (id)`,
+	`CREATE TABLE IF NOT EXISTS report_templates (
+		name TEXT PRIMARY KEY,
+		definition JSONB NOT NULL,
+		updated_by TEXT NOT NULL DEFAULT '',
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`ALTER TABLE score_inquiries ADD COLUMN IF NOT EXISTS provider TEXT NOT NULL DEFAULT 'creditinfo'`,
+	`CREATE TABLE IF NOT EXISTS score_shadow_results (
+		id BIGSERIAL PRIMARY KEY,
+		inquiry_id BIGINT NOT NULL REFERENCES score_inquiries (id) ON DELETE CASCADE,
+		provider TEXT NOT NULL,
+		score_card TEXT NOT NULL,
+		id_query TEXT NOT NULL DEFAULT '',
+		error_code TEXT NOT NULL DEFAULT '',
+		error_string TEXT NOT NULL DEFAULT '',
+		score TEXT NOT NULL DEFAULT '',
+		one_year_probability_of_default TEXT NOT NULL DEFAULT '',
+		risk_grade TEXT NOT NULL DEFAULT '',
+		score_by_ml TEXT NOT NULL DEFAULT '',
+		risk_grade_by_ml TEXT NOT NULL DEFAULT '',
+		latency_ms BIGINT NOT NULL DEFAULT 0,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS score_shadow_results_inquiry_id_idx ON score_shadow_results (inquiry_id)`,
+	`CREATE INDEX IF NOT EXISTS score_shadow_results_created_at_idx ON score_shadow_results (created_at)`,
+	`CREATE TABLE IF NOT EXISTS watchlists (
+		id BIGSERIAL PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE,
+		score_card TEXT NOT NULL,
+		interval TEXT NOT NULL,
+		alert_rules JSONB NOT NULL DEFAULT '[]',
+		active BOOLEAN NOT NULL DEFAULT TRUE,
+		team TEXT NOT NULL DEFAULT '',
+		created_by TEXT NOT NULL DEFAULT '',
+		next_run_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		last_run_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE TABLE IF NOT EXISTS watchlist_members (
+		id BIGSERIAL PRIMARY KEY,
+		watchlist_id BIGINT NOT NULL REFERENCES watchlists (id) ON DELETE CASCADE,
+		subject_iin TEXT NOT NULL,
+		last_inquiry_id BIGINT REFERENCES score_inquiries (id),
+		last_scored_at TIMESTAMPTZ,
+		last_error TEXT NOT NULL DEFAULT '',
+		added_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (watchlist_id, subject_iin)
+	)`,
+	`CREATE TABLE IF NOT EXISTS monitoring_alerts (
+		id BIGSERIAL PRIMARY KEY,
+		watchlist_id BIGINT NOT NULL REFERENCES watchlists (id) ON DELETE CASCADE,
+		member_id BIGINT NOT NULL REFERENCES watchlist_members (id) ON DELETE CASCADE,
+		subject_iin TEXT NOT NULL,
+		score_card TEXT NOT NULL,
+		rule TEXT NOT NULL,
+		field TEXT NOT NULL,
+		previous_value TEXT NOT NULL DEFAULT '',
+		current_value TEXT NOT NULL DEFAULT '',
+		message TEXT NOT NULL DEFAULT '',
+		previous_inquiry_id BIGINT NOT NULL REFERENCES score_inquiries (id),
+		inquiry_id BIGINT NOT NULL REFERENCES score_inquiries (id),
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		delivered_at TIMESTAMPTZ,
+		delivery_attempts INT NOT NULL DEFAULT 0,
+		delivery_error TEXT NOT NULL DEFAULT ''
+	)`,
+	`CREATE INDEX IF NOT EXISTS monitoring_alerts_watchlist_id_idx ON monitoring_alerts (watchlist_id, created_at)`,
+	`CREATE INDEX IF NOT EXISTS monitoring_alerts_undelivered_idx ON monitoring_alerts (created_at) WHERE delivered_at IS NULL`,
+	`CREATE TABLE IF NOT EXISTS idempotency_keys (
+		user_id TEXT NOT NULL,
+		idempotency_key TEXT NOT NULL,
+		method TEXT NOT NULL,
+		path TEXT NOT NULL,
+		request_hash TEXT NOT NULL,
+		status_code INT,
+		content_type TEXT NOT NULL DEFAULT '',
+		body BYTEA,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		expires_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (user_id, idempotency_key)
+	)`,
+	`CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at)`,
+	`CREATE TABLE IF NOT EXISTS score_quotas (
+		id BIGSERIAL PRIMARY KEY,
+		scope_type TEXT NOT NULL,
+		scope TEXT NOT NULL,
+		score_card TEXT NOT NULL DEFAULT '*',
+		period TEXT NOT NULL,
+		quota_limit BIGINT NOT NULL,
+		updated_by TEXT NOT NULL DEFAULT '',
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (scope_type, scope, score_card, period)
+	)`,
+	`CREATE TABLE IF NOT EXISTS score_reason_codes (
+		code TEXT PRIMARY KEY,
+		severity INT NOT NULL,
+		customer_text JSONB NOT NULL DEFAULT '{}',
+		internal_explanation TEXT NOT NULL DEFAULT '',
+		updated_by TEXT NOT NULL DEFAULT '',
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE TABLE IF NOT EXISTS loan_applications (
+		id BIGSERIAL PRIMARY KEY,
+		applicant_iin TEXT NOT NULL,
+		applicant_name TEXT NOT NULL,
+		country_id INT NOT NULL REFERENCES countries(id),
+		amount NUMERIC(18, 2) NOT NULL,
+		currency TEXT NOT NULL DEFAULT 'KZT',
+		term_months INT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'draft',
+		decision TEXT NOT NULL DEFAULT '',
+		decision_comment TEXT NOT NULL DEFAULT '',
+		decided_by TEXT NOT NULL DEFAULT '',
+		user_id TEXT NOT NULL,
+		user_name TEXT NOT NULL,
+		team TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS loan_applications_applicant_iin_idx ON loan_applications (applicant_iin)`,
+	`CREATE INDEX IF NOT EXISTS loan_applications_status_idx ON loan_applications (status, created_at)`,
+	`CREATE TABLE IF NOT EXISTS loan_application_scores (
+		application_id BIGINT NOT NULL REFERENCES loan_applications(id) ON DELETE CASCADE,
+		inquiry_id BIGINT NOT NULL REFERENCES score_inquiries(id),
+		linked_by TEXT NOT NULL DEFAULT '',
+		linked_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (application_id, inquiry_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS loan_application_status_history (
+		id BIGSERIAL PRIMARY KEY,
+		application_id BIGINT NOT NULL REFERENCES loan_applications(id) ON DELETE CASCADE,
+		from_status TEXT NOT NULL DEFAULT '',
+		to_status TEXT NOT NULL,
+		comment TEXT NOT NULL DEFAULT '',
+		changed_by TEXT NOT NULL DEFAULT '',
+		changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS loan_application_status_history_application_idx
+		ON loan_application_status_history (application_id, id)`,
+	`ALTER TABLE score_batches ADD COLUMN IF NOT EXISTS groups TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE score_batches ADD COLUMN IF NOT EXISTS roles TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE watchlists ADD COLUMN IF NOT EXISTS groups TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE watchlists ADD COLUMN IF NOT EXISTS roles TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE bureau_archive_access ADD COLUMN IF NOT EXISTS outcome TEXT NOT NULL DEFAULT 'read'`,
+	`ALTER TABLE bureau_archive_access ADD COLUMN IF NOT EXISTS error TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE score_shadow_results ADD COLUMN IF NOT EXISTS one_year_probability_of_default_by_ml TEXT NOT NULL DEFAULT ''`,
+	`DO $$
+	BEGIN
+		IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'loan_applications_country_id_fkey') THEN
+			ALTER TABLE loan_applications ADD CONSTRAINT loan_applications_country_id_fkey
+				FOREIGN KEY (country_id) REFERENCES countries(id);
+		END IF;
+	END $$`,
+	`ALTER TABLE watchlists ADD COLUMN IF NOT EXISTS user_id TEXT NOT NULL DEFAULT ''`,
+	// Время запроса к бюро у записей, созданных до колонки inquired_at: DEFAULT поставил им время миграции.
+	// Сервис пишет время начала запроса, оно не позже created_at (у ответов из кэша — раньше),
+	// поэтому более позднее значение — след DEFAULT, и его заменяет время создания
+	`UPDATE score_inquiries SET inquired_at = created_at WHERE inquired_at IS NULL OR inquired_at > created_at`,
+	`ALTER TABLE score_inquiries ALTER COLUMN inquired_at SET NOT NULL`,
+}
+
+func Migrate() {
+	for i, stmt := range migrations {
+		if _, err := DB.Exec(context.Background(), stmt); err != nil {
+			log.Fatal(fmt.Errorf("migration %d failed: %v", i, err))
+		}
+	}
+	fmt.Println("Database schema is up to date")
+}
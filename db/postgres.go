@@ -3,26 +3,25 @@ package db
 import (
 	"context"
 	"fmt"
-	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
 	"log"
 	"os"
 )
 
-var DB *pgx.Conn
+// Пул соединений: запросы обрабатываются параллельно (gin и фоновые воркеры),
+// а одиночное pgx.Conn не потокобезопасно.
+var DB *pgxpool.Pool
 
 func ConnectDB() {
 	databaseURL := os.Getenv("DATABASE_URL")
-	conn, err := pgx.Connect(context.Background(), databaseURL)
+	pool, err := pgxpool.Connect(context.Background(), databaseURL)
 	if err != nil {
 		log.Fatal(err)
 	}
-	DB = conn
+	DB = pool
 	fmt.Println("Successfully connected to database")
 }
 
 func CloseDB() {
-	err := DB.Close(context.Background())
-	if err != nil {
-		log.Fatal(err)
-	}
+	DB.Close()
 }
@@ -15,70 +15,157 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/countries": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Открытые ключи подписи сервиса (RS256) для проверки signed_result из POST /score. Ключ подписи указан в заголовке JWS (kid); после ротации прежние ключи остаются в наборе, пока они есть в SERVICE_SIGNING_KEYS",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "main"
+                ],
+                "summary": "Service JWKS",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.JWKSet"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/bureau-credentials": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Возвращает список всех стран (защищенный маршрут)",
+                "description": "Учётные данные бюро по пользователям, группам и по умолчанию (без паролей). Роль score_admin",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "countries"
+                    "admin"
                 ],
-                "summary": "Get all countries",
+                "summary": "List bureau credentials",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "type": "array",
-                                "items": {
-                                    "$ref": "#/definitions/models.Country"
-                                }
+                                "$ref": "#/definitions/models.BureauCredential"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создаёт или заменяет учётные данные бюро для пользователя, группы Keycloak или по умолчанию. Действуют со следующего запроса, без перезапуска. Роль score_admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create or rotate bureau credentials",
+                "parameters": [
+                    {
+                        "description": "Bureau credential",
+                        "name": "credential",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BureauCredentialRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BureauCredential"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Credentials store is read-only",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
             }
         },
-        "/countries/{id}": {
-            "get": {
+        "/admin/bureau-credentials/{id}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Возвращает информацию о стране по её идентификатору",
+                "description": "Удаляет учётные данные бюро. Роль score_admin",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "countries"
+                    "admin"
                 ],
-                "summary": "Get country by ID",
+                "summary": "Delete bureau credentials",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Country ID",
+                        "description": "Credential ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.Country"
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Insufficient permissions",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -87,7 +174,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Country not found",
+                        "description": "Bureau credential not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -98,45 +185,86 @@ const docTemplate = `{
                 }
             }
         },
-        "/get-score-cards": {
+        "/admin/decision-rules": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Все версии правил кредитного решения, новые сверху. Роль score_admin",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "decision-rules"
+                ],
+                "summary": "List decision rule sets",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DecisionRuleSet"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Получить статические карточки с результатами",
+                "description": "Сохраняет правила новой версией. Тело — JSON или YAML (Content-Type application/yaml). Роль score_admin",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/yaml"
                 ],
                 "produces": [
-                    "application/json",
                     "application/json"
                 ],
                 "tags": [
-                    "scores"
+                    "decision-rules"
                 ],
-                "summary": "Get score cards static",
+                "summary": "Create decision rule set version",
                 "parameters": [
                     {
-                        "description": "ScoreCardsRequest",
-                        "name": "login",
+                        "description": "Rule set",
+                        "name": "rules",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ScoreCardsRequest"
+                            "$ref": "#/definitions/models.DecisionRuleSet"
                         }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Сразу сделать версию активной",
+                        "name": "activate",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.ScoreCardsRequest"
+                            "$ref": "#/definitions/models.DecisionRuleSet"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -144,8 +272,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "ScoreCardsRequest not found",
+                    "403": {
+                        "description": "Insufficient permissions",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -156,9 +284,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/login": {
+        "/admin/decision-rules/dry-run": {
             "post": {
-                "description": "Логин на сайт",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Прогоняет правила (переданные, указанной версии или активные) на результате скоринга без сохранения и показывает, какое правило сработало. Роль score_admin",
                 "consumes": [
                     "application/json"
                 ],
@@ -166,16 +299,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "main"
+                    "decision-rules"
                 ],
+                "summary": "Decision rules dry run",
                 "parameters": [
                     {
-                        "description": "Login credentials",
-                        "name": "login",
+                        "description": "Dry run",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.LoginRequest"
+                            "$ref": "#/definitions/models.DecisionDryRunRequest"
                         }
                     }
                 ],
@@ -183,11 +317,29 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.TokenData"
+                            "$ref": "#/definitions/models.DecisionDryRunResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "404": {
-                        "description": "Not Found",
+                        "description": "Rule set or score inquiry not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -198,45 +350,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/score": {
-            "post": {
+        "/admin/decision-rules/{version}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Получить статические результаты",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Версия правил кредитного решения. Роль score_admin",
                 "produces": [
-                    "application/json",
                     "application/json"
                 ],
                 "tags": [
-                    "scores"
+                    "decision-rules"
                 ],
-                "summary": "Get score static",
+                "summary": "Get decision rule set",
                 "parameters": [
                     {
-                        "description": "ScoreRequest",
-                        "name": "login",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.ScoreRequest"
-                        }
+                        "type": "integer",
+                        "description": "Rule set version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.ScoreRequest"
+                            "$ref": "#/definitions/models.DecisionRuleSet"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Insufficient permissions",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -245,7 +391,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "ScoreRequest not found",
+                        "description": "Decision rule set not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -255,41 +401,4390 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.Country": {
-            "type": "object",
-            "additionalProperties": true
-        },
-        "models.LoginRequest": {
-            "type": "object",
-            "required": [
-                "password",
-                "username"
-            ],
-            "properties": {
-                "password": {
-                    "type": "string"
-                },
-                "username": {
-                    "type": "string"
-                }
-            }
         },
-        "models.ScoreCardsRequest": {
-            "type": "object",
-            "required": [
-                "get_score_cards"
-            ],
-            "properties": {
-                "get_score_cards": {
-                    "type": "string"
+        "/admin/decision-rules/{version}/activate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Делает версию правил активной: она применяется ко всем следующим скорингам. Роль score_admin",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "decision-rules"
+                ],
+                "summary": "Activate decision rule set",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rule set version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Decision rule set not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
                 }
             }
         },
-        "models.ScoreRequest": {
-            "type": "object",
+        "/admin/quotas": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Все квоты на платные запросы скоринга",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Score quotas",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ScoreQuota"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создаёт квоту или меняет лимит существующей (ключ — scope_type, scope, score_card, period). Scope \"*\" — квота каждому пользователю или команде",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Save score quota",
+                "parameters": [
+                    {
+                        "description": "Quota",
+                        "name": "quota",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ScoreQuotaRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ScoreQuota"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/quotas/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Delete score quota",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Quota ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Score quota not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reason-codes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Каталог кодов причин скоринга",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reason codes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ReasonCode"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Добавляет код причины в каталог или заменяет его: серьёзность 1–5, текст для клиента на ru/kk/en, пояснение для сотрудников",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Save reason code",
+                "parameters": [
+                    {
+                        "description": "Reason code",
+                        "name": "reason_code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReasonCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReasonCode"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reason-codes/{code}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Delete reason code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reason code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Reason code not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/report-template": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Текущее оформление отчёта о скоринге (шаблон по умолчанию, если своё не загружено). Роль score_admin",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get score report template",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReportTemplate"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заменяет оформление отчёта: заголовок, бренд, цвет, логотип (PNG в base64), подвал (text/template), подписи и диапазоны PD. Тело — JSON или YAML. Роль score_admin",
+                "consumes": [
+                    "application/json",
+                    "application/yaml"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Replace score report template",
+                "parameters": [
+                    {
+                        "description": "Report template",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReportTemplate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReportTemplate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает оформление отчёта по умолчанию. Роль score_admin",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reset score report template",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/score-shadow/comparison": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Сравнение теневого провайдера с основным по каждой паре провайдеров и карте: доля совпадений класса риска\nи разница баллов (теневой − основной) по запросам, где оба ответили без ошибки. Роль score_admin",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Shadow scoring comparison",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Основной провайдер",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Теневой провайдер",
+                        "name": "shadow_provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Скоринговая карта основного запроса",
+                        "name": "score_card",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC3339 или YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (RFC3339 или YYYY-MM-DD, день включительно)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ScoreShadowComparison"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/usage": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Платные, кэшированные и неуспешные запросы скоринга по командам, пользователям, картам и провайдерам",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Score usage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC3339 или YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (RFC3339 или YYYY-MM-DD, день включительно)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Команда",
+                        "name": "team",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Пользователь",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Скоринговая карта",
+                        "name": "score_card",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ScoreUsageRow"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/usage/billing": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Платные запросы за месяц по департаментам, картам и провайдерам в CSV (format=json — в JSON). Сумма считается по ценам из SCORE_CARD_PRICES",
+                "produces": [
+                    "text/csv",
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Monthly score billing",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Месяц YYYY-MM, по умолчанию текущий",
+                        "name": "month",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv (по умолчанию) или json",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/analytics/score-divergence": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Как часто классы риска классического (RiskGrade) и ML-результата (RiskGradeByML) расходятся и на сколько позиций\nшкалы MONITORING_RISK_GRADES: в целом, по картам, по периодам, распределение пар классов и случаи с наибольшим\nрасхождением. Учитываются успешные запросы не из кэша; без одного из классов — как несравнимые.\nРоль score_analyst или score_admin",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Classic vs ML divergence",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Скоринговая карта",
+                        "name": "score_card",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Провайдер",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC3339 или YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (RFC3339 или YYYY-MM-DD, день включительно)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Шаг динамики: day (по умолчанию), week или month",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество случаев с наибольшим расхождением (до 100)",
+                        "name": "top",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ScoreDivergenceReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid score divergence filter",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/analytics/score-divergence/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Запросы с классическим и ML-результатом в CSV для команды валидации моделей, сначала с наибольшей разницей классов\n(не более 100000 строк). Роль score_analyst или score_admin",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Export classic vs ML divergence",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Скоринговая карта",
+                        "name": "score_card",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Провайдер",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC3339 или YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (RFC3339 или YYYY-MM-DD, день включительно)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только запросы с разными классами риска",
+                        "name": "mismatches_only",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/audit/bureau-archive-access": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Журнал обращений к архиву ответов бюро, новые сверху. Роль score_auditor",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "Bureau archive access log",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Только обращения к ответу по этому запросу",
+                        "name": "inquiry_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество записей (до 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.BureauArchiveAccess"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/audit/scores/{id}/bureau-response": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Расшифрованный сырой SOAP-ответ бюро по запросу скоринга. Каждое обращение записывается в журнал. Роль score_auditor",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "Archived bureau response",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Inquiry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Основание обращения (попадает в журнал)",
+                        "name": "reason",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BureauArchiveResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "No archived bureau response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Archive is not configured",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/consents": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Все согласия субъекта, включая отозванные и истёкшие",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "consents"
+                ],
+                "summary": "Subject consents",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ИИН/БИН субъекта",
+                        "name": "iin",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Consent"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Регистрирует согласие субъекта (ИИН/БИН) на запрос в бюро по перечисленным скоринговым картам (\"*\" — все карты)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "consents"
+                ],
+                "summary": "Register consent",
+                "parameters": [
+                    {
+                        "description": "Consent",
+                        "name": "consent",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ConsentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Consent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/consents/{id}/revoke": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отзывает согласие; после отзыва скоринг по нему невозможен",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "consents"
+                ],
+                "summary": "Revoke consent",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Consent ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "revoke",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ConsentRevokeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Consent"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Consent not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/countries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает список всех стран (защищенный маршрут)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "countries"
+                ],
+                "summary": "Get all countries",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/models.Country"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/countries/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает информацию о стране по её идентификатору",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "countries"
+                ],
+                "summary": "Get country by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Country ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Country"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Country not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/get-score-cards": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получить скоринговые карты всех провайдеров. response — прежний формат Creditinfo,\nscore_cards — нормализованный список с провайдером каждой карты",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/json"
+                ],
+                "tags": [
+                    "scores"
+                ],
+                "summary": "Get score cards static",
+                "parameters": [
+                    {
+                        "description": "ScoreCardsRequest",
+                        "name": "login",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ScoreCardsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ScoreCardsRequest"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "No bureau credentials configured",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "ScoreCardsRequest not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Credit bureau is temporarily unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Состояние сервиса, выключателя запросов к бюро (bureau) и выключателей всех провайдеров скоринга (providers).\nЕсли выключатель бюро разомкнут, возвращает 503",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "main"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/loan-applications": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Список заявок, новые первыми. Супервизор видит заявки своей команды, остальные — только свои",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loan-applications"
+                ],
+                "summary": "Loan applications",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Статус: draft, scored, decided или closed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ИИН заявителя",
+                        "name": "iin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (до 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LoanApplication"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создать заявку на кредит в статусе draft. ИИН заявителя проверяется по контрольной сумме, страна — по справочнику countries",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loan-applications"
+                ],
+                "summary": "Create loan application",
+                "parameters": [
+                    {
+                        "description": "Заявка",
+                        "name": "application",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoanApplicationRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Повтор с тем же ключом получает первый ответ",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.LoanApplication"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid loan application",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/loan-applications/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заявка с привязанными запросами скоринга и историей статусов",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loan-applications"
+                ],
+                "summary": "Loan application by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LoanApplicationDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Loan application not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Изменить данные заявки; доступно только для черновика (draft)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loan-applications"
+                ],
+                "summary": "Update loan application",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Заявка",
+                        "name": "application",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoanApplicationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LoanApplication"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid loan application",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Loan application not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Loan application status does not allow this action",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удалить черновик заявки; поданную заявку можно только закрыть",
+                "tags": [
+                    "loan-applications"
+                ],
+                "summary": "Delete loan application",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Loan application not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Loan application status does not allow this action",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/loan-applications/{id}/close": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Закрыть заявку на любом этапе; закрытая заявка больше не меняется",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loan-applications"
+                ],
+                "summary": "Close loan application",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Комментарий",
+                        "name": "close",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.LoanApplicationCloseRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LoanApplicationDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Loan application not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Loan application status does not allow this action",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/loan-applications/{id}/decision": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Зафиксировать решение (approve, refer или decline) по заявке в статусе scored",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loan-applications"
+                ],
+                "summary": "Decide loan application",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Решение",
+                        "name": "decision",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoanApplicationDecisionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LoanApplicationDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Loan application not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Loan application status does not allow this action",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/loan-applications/{id}/score": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выполнить скоринг заявителя по карте и привязать запрос к заявке. Успешный ответ бюро переводит заявку\nиз draft в scored; повторный скоринг допускается до решения. Согласие субъекта и квоты — как у POST /score",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loan-applications"
+                ],
+                "summary": "Score loan application",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Скоринговая карта",
+                        "name": "score",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoanApplicationScoreRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Повтор с тем же ключом получает первый ответ, а не новый платный запрос",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LoanApplicationDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid score request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "No valid consent (code CONSENT_REQUIRED)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Loan application not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Loan application status does not allow this action",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Score quota exceeded (code QUOTA_EXCEEDED)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Credit bureau is temporarily unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/loan-applications/{id}/scores": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Привязать уже выполненный запрос скоринга того же субъекта. Успешный запрос переводит заявку в scored",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loan-applications"
+                ],
+                "summary": "Link score to loan application",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Запрос скоринга",
+                        "name": "link",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoanApplicationLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LoanApplicationDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid loan application",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Loan application or score inquiry not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Loan application status does not allow this action",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Логин на сайт",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "main"
+                ],
+                "parameters": [
+                    {
+                        "description": "Login credentials",
+                        "name": "login",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TokenData"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/monitoring/alerts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Оповещения мониторинга портфеля с состоянием доставки, новые сверху. Роль score_monitoring или score_admin",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "monitoring"
+                ],
+                "summary": "Monitoring alerts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Список наблюдения",
+                        "name": "watchlist_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ИИН/БИН субъекта",
+                        "name": "iin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только недоставленные",
+                        "name": "undelivered",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC3339 или YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (RFC3339 или YYYY-MM-DD, день включительно)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество записей (до 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.MonitoringAlert"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/score": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выполнить скоринг у провайдера карты. response — прежний формат Creditinfo,\nresult — нормализованный результат с именем провайдера",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/json"
+                ],
+                "tags": [
+                    "scores"
+                ],
+                "summary": "Get score static",
+                "parameters": [
+                    {
+                        "description": "ScoreRequest",
+                        "name": "login",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ScoreRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Игнорировать кэш и запросить бюро заново (роль score_cache_bypass)",
+                        "name": "refresh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Добавить signed_result — компактный JWS (RS256) над результатом; ключи в /.well-known/jwks.json",
+                        "name": "signed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Повтор с тем же ключом получает первый ответ, а не новый платный запрос",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ScoreRequest"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "No bureau credentials configured or no valid consent (code CONSENT_REQUIRED)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "ScoreRequest not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Request with this Idempotency-Key is still in progress",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key was already used with a different request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Score quota exceeded (code QUOTA_EXCEEDED)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Credit bureau is temporarily unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/score-batches": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Загружает CSV или XLSX со списком субъектов и создаёт задание пакетного скоринга. Задание обрабатывается асинхронно",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "score-batches"
+                ],
+                "summary": "Create score batch",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV или XLSX: колонка с заголовком атрибута или первая колонка",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Скоринговая карта",
+                        "name": "score_card",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Имя атрибута карты (по умолчанию IIN)",
+                        "name": "attribute",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Повтор с тем же ключом и файлом получает первый ответ, а не новое задание",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.ScoreBatch"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Request with this Idempotency-Key is still in progress",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Request body is too large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key was already used with a different request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/score-batches/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Состояние задания пакетного скоринга: статус и число обработанных строк",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "score-batches"
+                ],
+                "summary": "Score batch progress",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Batch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ScoreBatch"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Score batch not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/score-batches/{id}/results": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Результаты пакетного скоринга в CSV или XLSX, включая ошибки по каждой строке",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "score-batches"
+                ],
+                "summary": "Download score batch results",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Batch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv (по умолчанию) или xlsx",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Score batch not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/scores": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "История запросов скоринга с фильтрами и курсорной пагинацией. Супервизор видит запросы своей команды, остальные — только свои",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scores"
+                ],
+                "summary": "Score inquiry history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ИИН субъекта",
+                        "name": "iin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Пользователь, выполнивший запрос",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Скоринговая карта",
+                        "name": "score_card",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Класс риска (классический или ML)",
+                        "name": "risk_grade",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Код ошибки бюро",
+                        "name": "error_code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC3339 или YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (RFC3339 или YYYY-MM-DD, день включительно)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Поле сортировки: created_at или latency_ms",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Направление сортировки: asc или desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (до 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ScoreInquiryPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid score history filter",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/scores/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Полная информация о запросе скоринга, включая причины",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scores"
+                ],
+                "summary": "Score inquiry by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Inquiry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ScoreInquiry"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Score inquiry not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/scores/{id}/explanation": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Причины результата скоринга по убыванию значимости (серьёзность из каталога кодов причин) с текстом для письма заявителю об отказе. Коды вне каталога идут последними с текстом бюро",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scores"
+                ],
+                "summary": "Score explanation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Inquiry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Язык текстов: ru, kk или en (по умолчанию — язык запроса)",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Сколько причин вернуть (по умолчанию все)",
+                        "name": "top",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ScoreExplanation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Score inquiry not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/scores/{id}/report": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Печатный отчёт по запросу скоринга: субъект, баллы и классы риска классической и ML-модели, PD, причины, IdQuery, офицер и время формирования. ИИН маскируется без роли score_pii_viewer",
+                "produces": [
+                    "application/pdf",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "scores"
+                ],
+                "summary": "Score report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Inquiry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pdf (по умолчанию) или xlsx",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Score inquiry not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/usage/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Квоты пользователя и его команды с остатком и использование пользователя за текущий месяц",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "usage"
+                ],
+                "summary": "My score usage",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/watchlists": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Списки наблюдения с числом субъектов и расписанием. Роль score_monitoring или score_admin",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "monitoring"
+                ],
+                "summary": "Watchlists",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Watchlist"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создаёт список наблюдения: ИИН/БИН заёмщиков, карта, периодичность перескоринга (\"720h\" или \"30d\") и правила\nоповещения (risk_grade_worsened, pd_band_increased, score_dropped). Роль score_monitoring или score_admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "monitoring"
+                ],
+                "summary": "Create watchlist",
+                "parameters": [
+                    {
+                        "description": "Watchlist",
+                        "name": "watchlist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WatchlistRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Watchlist"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Watchlist with this name already exists",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/watchlists/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Список наблюдения с составом и последним результатом по каждому субъекту. Роль score_monitoring или score_admin",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "monitoring"
+                ],
+                "summary": "Watchlist by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Watchlist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WatchlistDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Watchlist not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Меняет настройки списка наблюдения (состав — через /watchlists/{id}/members). Роль score_monitoring или score_admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "monitoring"
+                ],
+                "summary": "Update watchlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Watchlist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Watchlist",
+                        "name": "watchlist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WatchlistRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Watchlist"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Watchlist not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Watchlist with this name already exists",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет список наблюдения вместе с его оповещениями. Роль score_monitoring или score_admin",
+                "tags": [
+                    "monitoring"
+                ],
+                "summary": "Delete watchlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Watchlist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Watchlist not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/watchlists/{id}/members": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Добавляет субъектов (ИИН/БИН) в список наблюдения; уже добавленные пропускаются. Роль score_monitoring или score_admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "monitoring"
+                ],
+                "summary": "Add watchlist members",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Watchlist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Subjects",
+                        "name": "members",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WatchlistMembersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "added",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Watchlist not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/watchlists/{id}/members/{iin}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Исключает субъекта из списка наблюдения. Роль score_monitoring или score_admin",
+                "tags": [
+                    "monitoring"
+                ],
+                "summary": "Remove watchlist member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Watchlist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ИИН/БИН субъекта",
+                        "name": "iin",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Watchlist not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/watchlists/{id}/run": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ставит перескоринг списка на ближайший проход планировщика. Роль score_monitoring или score_admin",
+                "tags": [
+                    "monitoring"
+                ],
+                "summary": "Run watchlist now",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Watchlist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "404": {
+                        "description": "Watchlist not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.BureauArchiveAccess": {
+            "type": "object",
+            "properties": {
+                "accessed_at": {
+                    "type": "string"
+                },
+                "archive_id": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "inquiry_id": {
+                    "type": "integer"
+                },
+                "outcome": {
+                    "description": "read — ответ выдан, failed — расшифровать не удалось (Error)",
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "user_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BureauArchiveResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "inquiry_id": {
+                    "type": "integer"
+                },
+                "key_id": {
+                    "type": "string"
+                },
+                "operation": {
+                    "type": "string"
+                },
+                "response_xml": {
+                    "type": "string"
+                },
+                "served_from_cache": {
+                    "description": "Запрос обслужен из кэша: ответ бюро — из архива исходного запроса SourceInquiryId",
+                    "type": "boolean"
+                },
+                "sha256": {
+                    "type": "string"
+                },
+                "source_inquiry_id": {
+                    "type": "integer"
+                },
+                "status_code": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BureauCredential": {
+            "type": "object",
+            "properties": {
+                "culture": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "subject_type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                },
+                "user_name": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BureauCredentialRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "subject_type",
+                "user_name"
+            ],
+            "properties": {
+                "culture": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "subject_type": {
+                    "type": "string",
+                    "enum": [
+                        "user",
+                        "group",
+                        "default"
+                    ]
+                },
+                "user_name": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Causes": {
+            "type": "object",
+            "properties": {
+                "causeText": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Consent": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "document_ref": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "granted_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "revoke_reason": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "revoked_by": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "subject_iin": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ConsentRequest": {
+            "type": "object",
+            "required": [
+                "channel",
+                "expires_at",
+                "scope",
+                "subject_iin"
+            ],
+            "properties": {
+                "channel": {
+                    "type": "string"
+                },
+                "document_ref": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "granted_at": {
+                    "description": "По умолчанию — момент регистрации",
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "subject_iin": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ConsentRevokeRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Country": {
+            "type": "object",
+            "additionalProperties": true
+        },
+        "models.DecisionCondition": {
+            "type": "object",
+            "required": [
+                "field",
+                "op"
+            ],
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "op": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "object"
+                }
+            }
+        },
+        "models.DecisionDryRunRequest": {
+            "type": "object",
+            "properties": {
+                "facts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "inquiry_id": {
+                    "type": "integer"
+                },
+                "rule_set": {
+                    "$ref": "#/definitions/models.DecisionRuleSet"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.DecisionDryRunResponse": {
+            "type": "object",
+            "properties": {
+                "decision": {
+                    "$ref": "#/definitions/models.ScoreDecision"
+                },
+                "facts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "trace": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DecisionRuleTrace"
+                    }
+                }
+            }
+        },
+        "models.DecisionRule": {
+            "type": "object",
+            "required": [
+                "decision",
+                "name",
+                "when"
+            ],
+            "properties": {
+                "decision": {
+                    "type": "string",
+                    "enum": [
+                        "approve",
+                        "refer",
+                        "decline"
+                    ]
+                },
+                "name": {
+                    "type": "string"
+                },
+                "when": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/models.DecisionCondition"
+                    }
+                }
+            }
+        },
+        "models.DecisionRuleSet": {
+            "type": "object",
+            "required": [
+                "default",
+                "name"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "default": {
+                    "type": "string",
+                    "enum": [
+                        "approve",
+                        "refer",
+                        "decline"
+                    ]
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DecisionRule"
+                    }
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.DecisionRuleTrace": {
+            "type": "object",
+            "properties": {
+                "failed_condition": {
+                    "type": "string"
+                },
+                "matched": {
+                    "type": "boolean"
+                },
+                "rule": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.JWKSet": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.JWK"
+                    }
+                }
+            }
+        },
+        "models.LoanApplication": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "applicant_iin": {
+                    "type": "string"
+                },
+                "applicant_name": {
+                    "type": "string"
+                },
+                "country_code": {
+                    "type": "string"
+                },
+                "country_id": {
+                    "description": "Страна проживания — запись из countries",
+                    "type": "integer"
+                },
+                "country_name": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "decided_by": {
+                    "type": "string"
+                },
+                "decision": {
+                    "description": "Решение по заявке (approve, refer, decline) и комментарий к нему",
+                    "type": "string"
+                },
+                "decision_comment": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "team": {
+                    "type": "string"
+                },
+                "term_months": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "user_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LoanApplicationCloseRequest": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LoanApplicationDecisionRequest": {
+            "type": "object",
+            "required": [
+                "decision"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "decision": {
+                    "type": "string",
+                    "enum": [
+                        "approve",
+                        "refer",
+                        "decline"
+                    ]
+                }
+            }
+        },
+        "models.LoanApplicationDetails": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "applicant_iin": {
+                    "type": "string"
+                },
+                "applicant_name": {
+                    "type": "string"
+                },
+                "country_code": {
+                    "type": "string"
+                },
+                "country_id": {
+                    "description": "Страна проживания — запись из countries",
+                    "type": "integer"
+                },
+                "country_name": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "decided_by": {
+                    "type": "string"
+                },
+                "decision": {
+                    "description": "Решение по заявке (approve, refer, decline) и комментарий к нему",
+                    "type": "string"
+                },
+                "decision_comment": {
+                    "type": "string"
+                },
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LoanApplicationStatusChange"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "scores": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ScoreInquiry"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "team": {
+                    "type": "string"
+                },
+                "term_months": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "user_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LoanApplicationLinkRequest": {
+            "type": "object",
+            "required": [
+                "inquiry_id"
+            ],
+            "properties": {
+                "inquiry_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LoanApplicationRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "applicant_iin",
+                "applicant_name",
+                "country_id",
+                "term_months"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "applicant_iin": {
+                    "type": "string"
+                },
+                "applicant_name": {
+                    "type": "string"
+                },
+                "country_id": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "description": "По умолчанию KZT",
+                    "type": "string"
+                },
+                "term_months": {
+                    "type": "integer",
+                    "maximum": 600,
+                    "minimum": 1
+                }
+            }
+        },
+        "models.LoanApplicationScoreRequest": {
+            "type": "object",
+            "required": [
+                "score_card"
+            ],
+            "properties": {
+                "score_card": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LoanApplicationStatusChange": {
+            "type": "object",
+            "properties": {
+                "changed_at": {
+                    "type": "string"
+                },
+                "changed_by": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "from_status": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "to_status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LoginRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MonitoringAlert": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current_value": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "delivery_attempts": {
+                    "type": "integer"
+                },
+                "delivery_error": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "inquiry_id": {
+                    "type": "integer"
+                },
+                "member_id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "previous_inquiry_id": {
+                    "type": "integer"
+                },
+                "previous_value": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                },
+                "score_card": {
+                    "type": "string"
+                },
+                "subject_iin": {
+                    "type": "string"
+                },
+                "watchlist_id": {
+                    "type": "integer"
+                },
+                "watchlist_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MonitoringAlertRule": {
+            "type": "object",
+            "required": [
+                "type"
+            ],
+            "properties": {
+                "field": {
+                    "description": "Сравниваемое поле: для классов риска RiskGrade (по умолчанию) или RiskGradeByML,\nдля PD — OneYearProbabilityOfDefault или OneYearProbabilityOfDefaultByML, для балла — Score или ScoreByML",
+                    "type": "string"
+                },
+                "notches": {
+                    "description": "Минимальное ухудшение класса риска или PD-диапазона; по умолчанию 1",
+                    "type": "integer"
+                },
+                "points": {
+                    "description": "Минимальное снижение балла для score_dropped",
+                    "type": "number"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReasonCode": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "customer_text": {
+                    "description": "Текст для клиента по языкам: {\"ru\": \"...\", \"kk\": \"...\", \"en\": \"...\"}",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "internal_explanation": {
+                    "type": "string"
+                },
+                "severity": {
+                    "description": "1 — наименее, 5 — наиболее существенная причина",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReasonCodeRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "customer_text"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "customer_text": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "internal_explanation": {
+                    "type": "string"
+                },
+                "severity": {
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 1
+                }
+            }
+        },
+        "models.ReportPdRange": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "number"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.ReportTemplate": {
+            "type": "object",
+            "properties": {
+                "brand": {
+                    "type": "string"
+                },
+                "brand_color": {
+                    "description": "Цвет шапки и заголовков таблиц, #RRGGBB",
+                    "type": "string"
+                },
+                "footer": {
+                    "description": "text/template; доступны .Officer, .GeneratedBy, .GeneratedAt, .InquiryId, .IdQuery",
+                    "type": "string"
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "logo_png": {
+                    "description": "Логотип в PNG, base64",
+                    "type": "string"
+                },
+                "pd_ranges": {
+                    "description": "Диапазоны вероятности дефолта в тех же единицах, что возвращает бюро",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReportPdRange"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ScoreBatch": {
+            "type": "object",
+            "properties": {
+                "attribute_name": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "file_name": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "processed": {
+                    "type": "integer"
+                },
+                "score_card": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "team": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "user_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ScoreCardsRequest": {
+            "type": "object",
+            "required": [
+                "get_score_cards"
+            ],
+            "properties": {
+                "get_score_cards": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ScoreDecision": {
+            "type": "object",
+            "properties": {
+                "decision": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                },
+                "rules_version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ScoreDivergenceCase": {
+            "type": "object",
+            "properties": {
+                "RiskGrade": {
+                    "type": "string"
+                },
+                "RiskGradeByML": {
+                    "type": "string"
+                },
+                "Score": {
+                    "type": "string"
+                },
+                "ScoreByML": {
+                    "type": "string"
+                },
+                "comparable": {
+                    "type": "boolean"
+                },
+                "grade_mismatch": {
+                    "type": "boolean"
+                },
+                "grade_notch_delta": {
+                    "description": "Разница позиций классов риска (ML − классический); нет для несравнимых",
+                    "type": "integer"
+                },
+                "inquired_at": {
+                    "type": "string"
+                },
+                "inquiry_id": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "score_card": {
+                    "type": "string"
+                },
+                "subject_iin": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ScoreDivergenceReport": {
+            "type": "object",
+            "properties": {
+                "by_period": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ScoreDivergenceStats"
+                    }
+                },
+                "by_score_card": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ScoreDivergenceStats"
+                    }
+                },
+                "grade_pairs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ScoreGradePair"
+                    }
+                },
+                "summary": {
+                    "$ref": "#/definitions/models.ScoreDivergenceStats"
+                },
+                "top_cases": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ScoreDivergenceCase"
+                    }
+                }
+            }
+        },
+        "models.ScoreDivergenceStats": {
+            "type": "object",
+            "properties": {
+                "comparable": {
+                    "type": "integer"
+                },
+                "grade_mismatches": {
+                    "type": "integer"
+                },
+                "grade_notch_delta_mean": {
+                    "type": "number"
+                },
+                "grade_notch_delta_mean_abs": {
+                    "type": "number"
+                },
+                "mismatch_rate": {
+                    "type": "number"
+                },
+                "not_comparable": {
+                    "type": "integer"
+                },
+                "period": {
+                    "type": "string"
+                },
+                "score_card": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ScoreExplanation": {
+            "type": "object",
+            "properties": {
+                "decision": {
+                    "type": "string"
+                },
+                "inquired_at": {
+                    "type": "string"
+                },
+                "inquiry_id": {
+                    "type": "integer"
+                },
+                "locale": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "reasons": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ScoreReason"
+                    }
+                },
+                "risk_grade": {
+                    "type": "string"
+                },
+                "risk_grade_by_ml": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "string"
+                },
+                "score_by_ml": {
+                    "type": "string"
+                },
+                "score_card": {
+                    "type": "string"
+                },
+                "subject_iin": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ScoreGradePair": {
+            "type": "object",
+            "properties": {
+                "RiskGrade": {
+                    "type": "string"
+                },
+                "RiskGradeByML": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "score_card": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ScoreInquiry": {
+            "type": "object",
+            "properties": {
+                "Causes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Causes"
+                    }
+                },
+                "ErrorCode": {
+                    "type": "string"
+                },
+                "ErrorString": {
+                    "type": "string"
+                },
+                "IdQuery": {
+                    "type": "string"
+                },
+                "OneYearProbabilityOfDefault": {
+                    "type": "string"
+                },
+                "OneYearProbabilityOfDefaultByML": {
+                    "type": "string"
+                },
+                "RiskGrade": {
+                    "type": "string"
+                },
+                "RiskGradeByML": {
+                    "type": "string"
+                },
+                "Score": {
+                    "type": "string"
+                },
+                "ScoreByML": {
+                    "type": "string"
+                },
+                "attributes": {
+                    "type": "object"
+                },
+                "cached_from_id": {
+                    "type": "integer"
+                },
+                "consent_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "decision": {
+                    "type": "string"
+                },
+                "decision_rule": {
+                    "type": "string"
+                },
+                "decision_rules_version": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "inquired_at": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "score_card": {
+                    "type": "string"
+                },
+                "subject_iin": {
+                    "type": "string"
+                },
+                "team": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "user_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ScoreInquiryPage": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ScoreInquiry"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ScoreQuota": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "period": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string"
+                },
+                "scope_type": {
+                    "type": "string"
+                },
+                "score_card": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ScoreQuotaRequest": {
+            "type": "object",
+            "required": [
+                "period",
+                "scope",
+                "scope_type"
+            ],
+            "properties": {
+                "limit": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "period": {
+                    "type": "string",
+                    "enum": [
+                        "day",
+                        "month"
+                    ]
+                },
+                "scope": {
+                    "type": "string"
+                },
+                "scope_type": {
+                    "type": "string",
+                    "enum": [
+                        "user",
+                        "team"
+                    ]
+                },
+                "score_card": {
+                    "description": "По умолчанию \"*\" — все карты",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ScoreReason": {
+            "type": "object",
+            "properties": {
+                "bureau_text": {
+                    "type": "string"
+                },
+                "cataloged": {
+                    "description": "false — кода нет в каталоге, его стоит добавить",
+                    "type": "boolean"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "internal_explanation": {
+                    "type": "string"
+                },
+                "rank": {
+                    "type": "integer"
+                },
+                "severity": {
+                    "type": "integer"
+                },
+                "text": {
+                    "description": "Текст для письма клиенту; для кода вне каталога — текст бюро",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ScoreRequest": {
+            "type": "object",
             "properties": {
                 "Score": {
                     "type": "object",
@@ -323,9 +4818,275 @@ const docTemplate = `{
                 }
             }
         },
+        "models.ScoreShadowComparison": {
+            "type": "object",
+            "properties": {
+                "compared": {
+                    "type": "integer"
+                },
+                "pd_by_ml_delta_mean": {
+                    "type": "number"
+                },
+                "pd_by_ml_delta_mean_abs": {
+                    "type": "number"
+                },
+                "primary_latency_mean_ms": {
+                    "type": "number"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "risk_grade_agreement": {
+                    "type": "number"
+                },
+                "risk_grade_by_ml_agreement": {
+                    "type": "number"
+                },
+                "risk_grade_by_ml_compared": {
+                    "type": "integer"
+                },
+                "risk_grade_by_ml_matches": {
+                    "type": "integer"
+                },
+                "risk_grade_compared": {
+                    "type": "integer"
+                },
+                "risk_grade_matches": {
+                    "type": "integer"
+                },
+                "score_card": {
+                    "type": "string"
+                },
+                "score_delta_max": {
+                    "type": "number"
+                },
+                "score_delta_mean": {
+                    "type": "number"
+                },
+                "score_delta_mean_abs": {
+                    "type": "number"
+                },
+                "score_delta_min": {
+                    "type": "number"
+                },
+                "shadow_errors": {
+                    "type": "integer"
+                },
+                "shadow_latency_mean_ms": {
+                    "type": "number"
+                },
+                "shadow_provider": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ScoreUsageRow": {
+            "type": "object",
+            "properties": {
+                "billable_calls": {
+                    "type": "integer"
+                },
+                "cached_calls": {
+                    "type": "integer"
+                },
+                "failed_calls": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "score_card": {
+                    "type": "string"
+                },
+                "team": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "user_name": {
+                    "type": "string"
+                }
+            }
+        },
         "models.TokenData": {
             "type": "object",
             "additionalProperties": true
+        },
+        "models.Watchlist": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "alert_rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MonitoringAlertRule"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "last_run_at": {
+                    "type": "string"
+                },
+                "members": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "score_card": {
+                    "type": "string"
+                },
+                "team": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WatchlistDetails": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "alert_rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MonitoringAlertRule"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "last_run_at": {
+                    "type": "string"
+                },
+                "member_list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WatchlistMember"
+                    }
+                },
+                "members": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "score_card": {
+                    "type": "string"
+                },
+                "team": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WatchlistMember": {
+            "type": "object",
+            "properties": {
+                "added_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_inquiry_id": {
+                    "type": "integer"
+                },
+                "last_scored_at": {
+                    "type": "string"
+                },
+                "subject_iin": {
+                    "type": "string"
+                },
+                "watchlist_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.WatchlistMembersRequest": {
+            "type": "object",
+            "required": [
+                "subject_iins"
+            ],
+            "properties": {
+                "subject_iins": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.WatchlistRequest": {
+            "type": "object",
+            "required": [
+                "interval",
+                "name",
+                "score_card"
+            ],
+            "properties": {
+                "active": {
+                    "description": "По умолчанию список активен",
+                    "type": "boolean"
+                },
+                "alert_rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MonitoringAlertRule"
+                    }
+                },
+                "interval": {
+                    "description": "Периодичность перескоринга: длительность Go (\"720h\") или дни (\"30d\")",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "score_card": {
+                    "type": "string"
+                },
+                "subject_iins": {
+                    "description": "Начальный состав списка",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
         }
     },
     "securityDefinitions": {
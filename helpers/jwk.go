@@ -0,0 +1,20 @@
+package helpers
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"go-keycloak-jwt/models"
+	"math/big"
+)
+
+// PublicKeyJWK описывает открытый RSA-ключ подписи в формате JWK (RFC 7517) для публикации в JWKS
+func PublicKeyJWK(kid string, key *rsa.PublicKey) models.JWK {
+	return models.JWK{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
@@ -16,13 +16,13 @@ func createRSAPublicKeyFromModExp(nStr string, eStr string) (*rsa.PublicKey, err
 	// Декодируем модуль (n) из base64URL
 	nBytes, err := base64.RawURLEncoding.DecodeString(nStr)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при декодировании модуля: %v", err)
+		return nil, fmt.Errorf("failed to decode key modulus: %v", err)
 	}
 
 	// Декодируем экспоненту (e) из base64URL
 	eBytes, err := base64.RawURLEncoding.DecodeString(eStr)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при декодировании экспоненты: %v", err)
+		return nil, fmt.Errorf("failed to decode key exponent: %v", err)
 	}
 
 	// Преобразуем экспоненту в целое число
@@ -47,7 +47,7 @@ func createRSAPublicKeyFromModExp(nStr string, eStr string) (*rsa.PublicKey, err
 func convertRSAPublicKeyToPEM(pubKey *rsa.PublicKey) (string, error) {
 	pubKeyBytes, err := x509.MarshalPKIXPublicKey(pubKey)
 	if err != nil {
-		return "", fmt.Errorf("ошибка при маршализации публичного ключа: %v", err)
+		return "", fmt.Errorf("failed to marshal public key: %v", err)
 	}
 
 	pemKey := &pem.Block{
@@ -58,7 +58,7 @@ func convertRSAPublicKeyToPEM(pubKey *rsa.PublicKey) (string, error) {
 	return string(pem.EncodeToMemory(pemKey)), nil
 }
 
-func TokenLoader(tokenString string, keys []models.KeyData) (string, string, error) {
+func TokenLoader(tokenString string, keys []models.KeyData) (models.Principal, error) {
 	// Парсинг массива ключей
 	// JWT токен для валидации
 
@@ -75,7 +75,7 @@ func TokenLoader(tokenString string, keys []models.KeyData) (string, string, err
 		// Извлекаем "kid" из заголовка токена
 		kid, ok := token.Header["kid"].(string)
 		if !ok {
-			return nil, fmt.Errorf("не найден 'kid' в заголовке токена")
+			return nil, fmt.Errorf("kid is missing in token header")
 		}
 
 		// Ищем соответствующий ключ по "kid"
@@ -84,17 +84,17 @@ func TokenLoader(tokenString string, keys []models.KeyData) (string, string, err
 				// Преобразуем модуль и экспоненту в публичный ключ
 				pubKey, err := createRSAPublicKeyFromModExp(key.N, key.E)
 				if err != nil {
-					return nil, fmt.Errorf("ошибка при создании публичного ключа: %v", err)
+					return nil, fmt.Errorf("failed to create public key: %v", err)
 				}
 				return pubKey, nil
 			}
 		}
 
-		return nil, fmt.Errorf("ключ с 'kid' %s не найден", kid)
+		return nil, fmt.Errorf("key with kid %s not found", kid)
 	})
 
 	if err != nil {
-		return "", "", err
+		return models.Principal{}, err
 	}
 
 	// Проверка валидности токена
@@ -102,16 +102,53 @@ func TokenLoader(tokenString string, keys []models.KeyData) (string, string, err
 		// Извлекаем данные из токена
 		userID, ok := claims["sub"].(string)
 		if !ok {
-			return "", "", fmt.Errorf("не найден userID в токене")
+			return models.Principal{}, fmt.Errorf("sub claim is missing in token")
 		}
 
 		userName, ok := claims["preferred_username"].(string)
 		if !ok {
-			return "", "", fmt.Errorf("не найден userName в токене")
+			return models.Principal{}, fmt.Errorf("preferred_username claim is missing in token")
 		}
 
-		return userID, userName, nil
+		// Команда задаётся атрибутом пользователя в Keycloak (mapper "team")
+		team, _ := claims["team"].(string)
+		// Язык пользователя — стандартный атрибут профиля Keycloak locale
+		locale, _ := claims["locale"].(string)
+
+		return models.Principal{
+			UserId:   userID,
+			UserName: userName,
+			Team:     team,
+			Groups:   stringClaims(claims["groups"]),
+			Roles:    realmRoles(claims),
+			Locale:   locale,
+		}, nil
 	} else {
-		return "", "", fmt.Errorf("токен недействителен")
+		return models.Principal{}, fmt.Errorf("token is invalid")
 	}
 }
+
+// Роли realm из claim'а realm_access.roles
+func realmRoles(claims jwt.MapClaims) []string {
+	realmAccess, ok := claims["realm_access"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return stringClaims(realmAccess["roles"])
+}
+
+// Массив строк из claim'а; элементы других типов пропускаются
+func stringClaims(claim interface{}) []string {
+	raw, ok := claim.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var values []string
+	for _, v := range raw {
+		if value, ok := v.(string); ok {
+			values = append(values, value)
+		}
+	}
+	return values
+}
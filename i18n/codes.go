@@ -0,0 +1,98 @@
+package i18n
+
+import "net/http"
+
+// Коды ошибок по HTTP-статусу — для ошибок без собственного кода
+const (
+	CodeInvalidRequest     = "INVALID_REQUEST"
+	CodeUnauthorized       = "UNAUTHORIZED"
+	CodeForbidden          = "FORBIDDEN"
+	CodeNotFound           = "NOT_FOUND"
+	CodeConflict           = "CONFLICT"
+	CodeUnprocessable      = "UNPROCESSABLE_REQUEST"
+	CodeTooManyRequests    = "TOO_MANY_REQUESTS"
+	CodeInternalError      = "INTERNAL_ERROR"
+	CodeServiceUnavailable = "SERVICE_UNAVAILABLE"
+)
+
+// Стабильные коды ошибок API: клиенты опираются на код, текст в "message" зависит от языка.
+// Коды для известных текстов ошибок (поле "error" остаётся английским для совместимости).
+// Ошибки с динамическим текстом получают код по HTTP-статусу.
+var textCodes = map[string]string{
+	"Unable to extract user from token":     "TOKEN_INVALID",
+	"Insufficient permissions":              "INSUFFICIENT_PERMISSIONS",
+	"invalid id":                            "INVALID_ID",
+	"invalid limit":                         "INVALID_PARAMETER",
+	"invalid version":                       "INVALID_PARAMETER",
+	"invalid watchlist_id":                  "INVALID_PARAMETER",
+	"invalid top":                           "INVALID_PARAMETER",
+	"invalid inquiry_id":                    "INVALID_PARAMETER",
+	"invalid cursor":                        "INVALID_PARAMETER",
+	"score_card is required":                "MISSING_PARAMETER",
+	"iin is required":                       "MISSING_PARAMETER",
+	"file is required":                      "MISSING_PARAMETER",
+	"format must be pdf or xlsx":            "UNSUPPORTED_FORMAT",
+	"format must be csv or xlsx":            "UNSUPPORTED_FORMAT",
+	"failed to read request body":           CodeInvalidRequest,
+	"request body is too large":             "REQUEST_TOO_LARGE",
+	"Not allowed to bypass the score cache": "CACHE_BYPASS_FORBIDDEN",
+	"Invalid watchlist":                     "VALIDATION_FAILED",
+	"Invalid score request":                 "VALIDATION_FAILED",
+	"Invalid consent":                       "VALIDATION_FAILED",
+	"Invalid report template":               "VALIDATION_FAILED",
+	"Response signing is not configured":    "RESPONSE_SIGNING_DISABLED",
+	"Invalid reason code":                   "VALIDATION_FAILED",
+	"invalid lang":                          "INVALID_PARAMETER",
+	"reason code not found":                 "REASON_CODE_NOT_FOUND",
+	"Invalid decision rules":                "VALIDATION_FAILED",
+	"Invalid score history filter":          "VALIDATION_FAILED",
+	"Invalid score divergence filter":       "VALIDATION_FAILED",
+	"Invalid loan application":              "VALIDATION_FAILED",
+	"invalid offset":                        "INVALID_PARAMETER",
+	"Idempotency-Key is too long":           "IDEMPOTENCY_KEY_TOO_LONG",
+	"Idempotency-Key was already used with a different request":               "IDEMPOTENCY_KEY_REUSED",
+	"a request with this Idempotency-Key is still in progress":                "IDEMPOTENCY_KEY_IN_PROGRESS",
+	"Credit bureau is temporarily unavailable":                                "BUREAU_UNAVAILABLE",
+	"no credit bureau credentials configured for this user":                   "NO_BUREAU_CREDENTIAL",
+	"score inquiry not found":                                                 "SCORE_INQUIRY_NOT_FOUND",
+	"score quota not found":                                                   "SCORE_QUOTA_NOT_FOUND",
+	"month must be in YYYY-MM format":                                         "INVALID_MONTH",
+	"consent not found":                                                       "CONSENT_NOT_FOUND",
+	"bureau credential not found":                                             "BUREAU_CREDENTIAL_NOT_FOUND",
+	"bureau credentials are loaded from a file and cannot be changed via API": "BUREAU_CREDENTIALS_READ_ONLY",
+	"bureau credentials store is not configured":                              "BUREAU_CREDENTIALS_DISABLED",
+	"watchlist not found":                                                     "WATCHLIST_NOT_FOUND",
+	"watchlist with this name already exists":                                 "WATCHLIST_NAME_TAKEN",
+	"no archived bureau response for this inquiry":                            "BUREAU_ARCHIVE_NOT_FOUND",
+	"bureau response archive is not configured":                               "BUREAU_ARCHIVE_DISABLED",
+	"score batch not found":                                                   "SCORE_BATCH_NOT_FOUND",
+	"decision rule set not found":                                             "DECISION_RULE_SET_NOT_FOUND",
+	"no active decision rule set":                                             "NO_ACTIVE_DECISION_RULES",
+	"loan application not found":                                              "LOAN_APPLICATION_NOT_FOUND",
+	"loan application status does not allow this action":                      "LOAN_APPLICATION_STATUS_CONFLICT",
+}
+
+var statusCodes = map[int]string{
+	http.StatusBadRequest:          CodeInvalidRequest,
+	http.StatusUnauthorized:        CodeUnauthorized,
+	http.StatusForbidden:           CodeForbidden,
+	http.StatusNotFound:            CodeNotFound,
+	http.StatusConflict:            CodeConflict,
+	http.StatusUnprocessableEntity: CodeUnprocessable,
+	http.StatusTooManyRequests:     CodeTooManyRequests,
+	http.StatusServiceUnavailable:  CodeServiceUnavailable,
+}
+
+// ErrorCode — код ошибки по её тексту, иначе по HTTP-статусу
+func ErrorCode(status int, text string) string {
+	if code, ok := textCodes[text]; ok {
+		return code
+	}
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	if status >= http.StatusBadRequest && status < http.StatusInternalServerError {
+		return CodeInvalidRequest
+	}
+	return CodeInternalError
+}
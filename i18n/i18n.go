@@ -0,0 +1,164 @@
+// Package i18n выбирает язык ответа (ru, kk, en) и переводит сообщения об ошибках API.
+//
+// Язык берётся из заголовка Accept-Language, затем из атрибута профиля пользователя (claim "locale"
+// в токене Keycloak), иначе — I18N_DEFAULT_LOCALE (по умолчанию ru). Тот же язык передаётся бюро
+// в заголовке Culture. Каталоги сообщений — messages/*.json: стабильный код ошибки → текст.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	Russian = "ru"
+	Kazakh  = "kk"
+	English = "en"
+)
+
+// Поддерживаемые языки и соответствующие им значения Culture для бюро
+var cultures = map[string]string{
+	Russian: "ru-RU",
+	Kazakh:  "kk-KZ",
+	English: "en-US",
+}
+
+//go:embed messages/*.json
+var catalogFiles embed.FS
+
+var catalogs = loadCatalogs()
+
+func loadCatalogs() map[string]map[string]string {
+	result := map[string]map[string]string{}
+	for locale := range cultures {
+		data, err := catalogFiles.ReadFile("messages/" + locale + ".json")
+		if err != nil {
+			log.Fatalf("i18n: catalog for %s is missing: %v", locale, err)
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			log.Fatalf("i18n: invalid catalog for %s: %v", locale, err)
+		}
+		result[locale] = messages
+	}
+	return result
+}
+
+// DefaultLocale — язык, если клиент и профиль его не задают
+func DefaultLocale() string {
+	if locale := Normalize(os.Getenv("I18N_DEFAULT_LOCALE")); locale != "" {
+		return locale
+	}
+	return Russian
+}
+
+// Normalize приводит тег языка (kk-KZ, EN, ru_RU) к поддерживаемому коду; пусто, если язык не поддерживается
+func Normalize(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	// Казахский иногда передаётся как kz
+	if tag == "kz" {
+		tag = Kazakh
+	}
+	if _, ok := cultures[tag]; ok {
+		return tag
+	}
+	return ""
+}
+
+// Negotiate выбирает язык: первый поддерживаемый из Accept-Language (с учётом q), затем язык профиля,
+// затем язык по умолчанию
+func Negotiate(acceptLanguage string, profileLocale string) string {
+	locale, _ := NegotiateExplicit(acceptLanguage, profileLocale)
+	return locale
+}
+
+// NegotiateExplicit — как Negotiate; explicit = false, если ни заголовок, ни профиль язык не задали
+// и выбран язык по умолчанию
+func NegotiateExplicit(acceptLanguage string, profileLocale string) (locale string, explicit bool) {
+	type candidate struct {
+		locale  string
+		quality float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		locale := Normalize(fields[0])
+		if locale == "" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			candidates = append(candidates, candidate{locale, quality})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	if len(candidates) > 0 {
+		return candidates[0].locale, true
+	}
+	if locale := Normalize(profileLocale); locale != "" {
+		return locale, true
+	}
+	return DefaultLocale(), false
+}
+
+// Culture — значение заголовка Culture бюро для языка
+func Culture(locale string) string {
+	if culture, ok := cultures[Normalize(locale)]; ok {
+		return culture
+	}
+	return cultures[DefaultLocale()]
+}
+
+// Message — текст сообщения по коду; если перевода нет, используется язык по умолчанию, затем английский
+func Message(locale string, code string) string {
+	for _, l := range []string{Normalize(locale), DefaultLocale(), English} {
+		if message, ok := catalogs[l][code]; ok {
+			return message
+		}
+	}
+	return code
+}
+
+type contextKey struct{}
+
+type contextLocale struct {
+	locale   string
+	explicit bool
+}
+
+// WithLocale сохраняет выбранный для запроса язык в контексте; explicit — язык задан клиентом или профилем
+func WithLocale(ctx context.Context, locale string, explicit bool) context.Context {
+	return context.WithValue(ctx, contextKey{}, contextLocale{locale: locale, explicit: explicit})
+}
+
+// FromContext — язык запроса; ok = false для фоновых задач, где язык не выбирался
+func FromContext(ctx context.Context) (string, bool) {
+	value, ok := ctx.Value(contextKey{}).(contextLocale)
+	return value.locale, ok && value.locale != ""
+}
+
+// ExplicitFromContext — язык запроса, только если его задал клиент (Accept-Language) или профиль пользователя;
+// язык по умолчанию не считается выбором и не должен перекрывать настройки учётных данных
+func ExplicitFromContext(ctx context.Context) (string, bool) {
+	value, ok := ctx.Value(contextKey{}).(contextLocale)
+	return value.locale, ok && value.explicit && value.locale != ""
+}
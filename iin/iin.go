@@ -0,0 +1,201 @@
+// Package iin разбирает и проверяет казахстанские ИИН (физические лица)
+// и БИН (юридические лица): формат из 12 цифр, контрольный разряд и
+// закодированные в номере дату рождения, век и пол либо дату регистрации.
+package iin
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrFormat    = errors.New("must consist of exactly 12 digits")
+	ErrChecksum  = errors.New("check digit does not match")
+	ErrBirthDate = errors.New("contains an invalid birth date")
+	ErrCentury   = errors.New("contains an invalid century/sex digit")
+	ErrNotIIN    = errors.New("is a BIN of a legal entity, not an IIN")
+	ErrNotBIN    = errors.New("is an IIN of an individual, not a BIN")
+	ErrBINDate   = errors.New("contains an invalid registration month")
+	ErrBINType   = errors.New("contains an invalid entity type or division digit")
+)
+
+type Sex string
+
+const (
+	Male   Sex = "M"
+	Female Sex = "F"
+)
+
+// IIN — индивидуальный идентификационный номер физического лица
+type IIN struct {
+	Value     string
+	BirthDate time.Time
+	Sex       Sex
+}
+
+// Тип юридического лица (5-я цифра БИН)
+type EntityType int
+
+const (
+	ResidentEntity    EntityType = 4
+	NonResidentEntity EntityType = 5
+	JointEntrepreneur EntityType = 6
+)
+
+// Признак подразделения (6-я цифра БИН)
+type Division int
+
+const (
+	HeadOffice           Division = 0
+	Branch               Division = 1
+	RepresentativeOffice Division = 2
+	PeasantFarm          Division = 3
+)
+
+// BIN — бизнес-идентификационный номер юридического лица
+type BIN struct {
+	Value          string
+	RegisteredYear int
+	RegisteredMon  time.Month
+	EntityType     EntityType
+	Division       Division
+}
+
+// IsBIN сообщает, что номер по структуре является БИН: в ИИН 5-я цифра —
+// десятки дня рождения (0–3), в БИН — тип юридического лица (4–6)
+func IsBIN(value string) bool {
+	digits, err := parseDigits(value)
+	return err == nil && digits[4] >= 4 && digits[4] <= 6
+}
+
+// ParseIIN проверяет ИИН и извлекает из него дату рождения и пол
+func ParseIIN(value string) (IIN, error) {
+	digits, err := parseDigits(value)
+	if err != nil {
+		return IIN{}, err
+	}
+	if digits[4] >= 4 && digits[4] <= 6 {
+		return IIN{}, ErrNotIIN
+	}
+	if err := verifyChecksum(digits); err != nil {
+		return IIN{}, err
+	}
+
+	var century int
+	var sex Sex
+	switch digits[6] {
+	case 1, 2:
+		century = 1800
+	case 3, 4:
+		century = 1900
+	case 5, 6:
+		century = 2000
+	default:
+		return IIN{}, ErrCentury
+	}
+	if digits[6]%2 == 1 {
+		sex = Male
+	} else {
+		sex = Female
+	}
+
+	year := century + digits[0]*10 + digits[1]
+	month := time.Month(digits[2]*10 + digits[3])
+	day := digits[4]*10 + digits[5]
+	birthDate := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	// time.Date нормализует 31 февраля в 3 марта — такую дату считаем ошибкой
+	if month < time.January || month > time.December || birthDate.Month() != month || birthDate.Day() != day {
+		return IIN{}, ErrBirthDate
+	}
+	if birthDate.After(time.Now()) {
+		return IIN{}, ErrBirthDate
+	}
+
+	return IIN{Value: value, BirthDate: birthDate, Sex: sex}, nil
+}
+
+// ParseBIN проверяет БИН и извлекает из него дату регистрации и тип юридического лица
+func ParseBIN(value string) (BIN, error) {
+	digits, err := parseDigits(value)
+	if err != nil {
+		return BIN{}, err
+	}
+	if digits[4] < 4 || digits[4] > 6 {
+		return BIN{}, ErrNotBIN
+	}
+	if digits[5] > 3 {
+		return BIN{}, ErrBINType
+	}
+	if err := verifyChecksum(digits); err != nil {
+		return BIN{}, err
+	}
+
+	month := time.Month(digits[2]*10 + digits[3])
+	if month < time.January || month > time.December {
+		return BIN{}, ErrBINDate
+	}
+	year := 2000 + digits[0]*10 + digits[1]
+	if year > time.Now().Year() {
+		year -= 100
+	}
+
+	return BIN{
+		Value:          value,
+		RegisteredYear: year,
+		RegisteredMon:  month,
+		EntityType:     EntityType(digits[4]),
+		Division:       Division(digits[5]),
+	}, nil
+}
+
+func parseDigits(value string) ([12]int, error) {
+	var digits [12]int
+	if len(value) != 12 {
+		return digits, ErrFormat
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return digits, ErrFormat
+		}
+		digits[i] = int(value[i] - '0')
+	}
+	return digits, nil
+}
+
+var (
+	firstWeights  = [11]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}
+	secondWeights = [11]int{3, 4, 5, 6, 7, 8, 9, 10, 11, 1, 2}
+)
+
+// Контрольный разряд: взвешенная сумма первых 11 цифр по модулю 11.
+// Если получилось 10, считается со вторым набором весов; повторные 10
+// означают, что такой номер не выдаётся.
+func verifyChecksum(digits [12]int) error {
+	check := weightedSum(digits, firstWeights) % 11
+	if check == 10 {
+		check = weightedSum(digits, secondWeights) % 11
+	}
+	if check == 10 || check != digits[11] {
+		return ErrChecksum
+	}
+	return nil
+}
+
+func weightedSum(digits [12]int, weights [11]int) int {
+	sum := 0
+	for i, w := range weights {
+		sum += digits[i] * w
+	}
+	return sum
+}
+
+func (s Sex) String() string {
+	switch s {
+	case Male:
+		return "male"
+	case Female:
+		return "female"
+	}
+	return fmt.Sprintf("Sex(%q)", string(s))
+}
@@ -0,0 +1,66 @@
+package iin
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseIIN(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  IIN
+		err   error
+	}{
+		{
+			name:  "valid",
+			value: "900115300003",
+			want:  IIN{Value: "900115300003", BirthDate: time.Date(1990, time.January, 15, 0, 0, 0, 0, time.UTC), Sex: Male},
+		},
+		{
+			name:  "valid on second pass",
+			value: "900115300401",
+			want:  IIN{Value: "900115300401", BirthDate: time.Date(1990, time.January, 15, 0, 0, 0, 0, time.UTC), Sex: Male},
+		},
+		{name: "invalid checksum", value: "900115300004", err: ErrChecksum},
+		// Обе взвешенные суммы дают 10 — такой номер не выдаётся при любом контрольном разряде
+		{name: "second pass 10", value: "900115400020", err: ErrChecksum},
+		{name: "BIN", value: "050340000009", err: ErrNotIIN},
+		{name: "format", value: "90011530000", err: ErrFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseIIN(tt.value)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ParseIIN(%q) error = %v, want %v", tt.value, err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseIIN(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecondPassTenRejectsEveryCheckDigit(t *testing.T) {
+	for digit := '0'; digit <= '9'; digit++ {
+		value := "90011540002" + string(digit)
+		if _, err := ParseIIN(value); !errors.Is(err, ErrChecksum) {
+			t.Errorf("ParseIIN(%q) error = %v, want %v", value, err, ErrChecksum)
+		}
+	}
+}
+
+func TestParseBIN(t *testing.T) {
+	got, err := ParseBIN("050340000009")
+	if err != nil {
+		t.Fatalf("ParseBIN error = %v", err)
+	}
+	want := BIN{Value: "050340000009", RegisteredYear: 2005, RegisteredMon: time.March, EntityType: ResidentEntity, Division: HeadOffice}
+	if got != want {
+		t.Errorf("ParseBIN = %+v, want %+v", got, want)
+	}
+	if !IsBIN("050340000009") || IsBIN("900115300003") {
+		t.Error("IsBIN does not tell a BIN from an IIN")
+	}
+}
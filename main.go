@@ -11,7 +11,12 @@ import (
 	"go-keycloak-jwt/db"
 	_ "go-keycloak-jwt/docs"
 	"go-keycloak-jwt/middlewares"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/pii"
+	"go-keycloak-jwt/services"
+	"go-keycloak-jwt/simulator"
 	"log"
+	"os"
 	"time"
 )
 
@@ -23,6 +28,17 @@ import (
 // @in header
 // @name Authorization
 func main() {
+	// Локальный симулятор бюро: go run . simulator -addr :8090
+	if len(os.Args) > 1 && os.Args[1] == "simulator" {
+		simulator.Run(os.Args[2:])
+		return
+	}
+
+	// ИИН и токены не должны попадать в логи ни приложения, ни gin
+	log.SetOutput(pii.NewLogWriter(os.Stderr))
+	gin.DefaultWriter = pii.NewLogWriter(os.Stdout)
+	gin.DefaultErrorWriter = pii.NewLogWriter(os.Stderr)
+
 	err := godotenv.Load()
 	if err != nil {
 		log.Fatal("Error loading .env file")
@@ -30,6 +46,18 @@ func main() {
 
 	db.ConnectDB()
 	defer db.CloseDB()
+	db.Migrate()
+	services.InitBureauCredentials()
+	services.InitBureauArchive()
+	services.InitServiceKeys()
+	services.InitTokenMinter()
+	services.InitScoreClient()
+	services.InitScoreProviders()
+	services.InitScoreShadow()
+	services.InitScoreCache()
+	services.StartScoreBatchWorkers()
+	services.StartMonitoring()
+	services.StartIdempotency()
 
 	// Custom CORS configuration
 	config := cors.Config{
@@ -44,31 +72,112 @@ func main() {
 			"sec-ch-ua-mobile",
 			"sec-ch-ua-platform",
 			"User-Agent",
-			"Referer"}, // Allow necessary headers, including sec-ch-ua and user-agent
-		ExposeHeaders:    []string{"Content-Length", "Authorization"}, // Expose headers if needed
-		AllowCredentials: true,                                        // Allow cookies or authentication data
-		MaxAge:           12 * time.Hour,                              // Cache preflight for 12 hours
+			"Referer",
+			"Accept-Language",
+			middlewares.IdempotencyKeyHeader}, // Allow necessary headers, including sec-ch-ua and user-agent
+		ExposeHeaders:    []string{"Content-Length", "Authorization", "Content-Language", middlewares.IdempotentReplayHeader}, // Expose headers if needed
+		AllowCredentials: true,                                                                                                // Allow cookies or authentication data
+		MaxAge:           12 * time.Hour,                                                                                      // Cache preflight for 12 hours
 	}
 
 	// Initialize Gin Router with custom CORS configuration
 	r := gin.Default()
 	r.Use(cors.New(config)) // Apply the custom CORS configuration
 	r.Use(gin.Recovery())
+	r.Use(middlewares.Locale)
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	r.POST("/login", controllers.LoginHandler)
+	r.GET("/health", controllers.GetHealth)
+	r.GET("/.well-known/jwks.json", controllers.GetJWKS)
 
 	// Защищённый маршрут
 
 	// Запрос структуры score-карты
 	r.POST("/get-score-cards", middlewares.JwtMiddleware, controllers.GetScoreCards)
-	r.POST("/score", middlewares.JwtMiddleware, controllers.PostScore)
+	r.POST("/score", middlewares.JwtMiddleware, middlewares.Idempotency, controllers.PostScore)
+
+	// История запросов скоринга
+	r.GET("/scores", middlewares.JwtMiddleware, controllers.GetScores)
+	r.GET("/scores/:id", middlewares.JwtMiddleware, controllers.GetScoreById)
+	r.GET("/scores/:id/report", middlewares.JwtMiddleware, controllers.GetScoreReport)
+	r.GET("/scores/:id/explanation", middlewares.JwtMiddleware, controllers.GetScoreExplanation)
+
+	// Согласия субъектов на запрос в бюро
+	r.POST("/consents", middlewares.JwtMiddleware, middlewares.Idempotency, controllers.PostConsent)
+	r.GET("/consents", middlewares.JwtMiddleware, controllers.GetConsents)
+	r.POST("/consents/:id/revoke", middlewares.JwtMiddleware, middlewares.Idempotency, controllers.RevokeConsent)
+
+	// Пакетный скоринг
+	r.POST("/score-batches", middlewares.JwtMiddleware, middlewares.Idempotency, controllers.PostScoreBatch)
+	r.GET("/score-batches/:id", middlewares.JwtMiddleware, controllers.GetScoreBatch)
+	r.GET("/score-batches/:id/results", middlewares.JwtMiddleware, controllers.GetScoreBatchResults)
+
+	// Заявки на кредит
+	r.POST("/loan-applications", middlewares.JwtMiddleware, middlewares.Idempotency, controllers.PostLoanApplication)
+	r.GET("/loan-applications", middlewares.JwtMiddleware, controllers.GetLoanApplications)
+	r.GET("/loan-applications/:id", middlewares.JwtMiddleware, controllers.GetLoanApplicationById)
+	r.PUT("/loan-applications/:id", middlewares.JwtMiddleware, middlewares.Idempotency, controllers.PutLoanApplication)
+	r.DELETE("/loan-applications/:id", middlewares.JwtMiddleware, middlewares.Idempotency, controllers.DeleteLoanApplication)
+	r.POST("/loan-applications/:id/score", middlewares.JwtMiddleware, middlewares.Idempotency, controllers.ScoreLoanApplication)
+	r.POST("/loan-applications/:id/scores", middlewares.JwtMiddleware, middlewares.Idempotency, controllers.PostLoanApplicationScore)
+	r.POST("/loan-applications/:id/decision", middlewares.JwtMiddleware, middlewares.Idempotency, controllers.DecideLoanApplication)
+	r.POST("/loan-applications/:id/close", middlewares.JwtMiddleware, middlewares.Idempotency, controllers.CloseLoanApplication)
 
 	// Запрос по странам score-карты
 	r.GET("/countries", middlewares.JwtMiddleware, controllers.GetCountries)
 	r.GET("/countries/:id", middlewares.JwtMiddleware, controllers.GetCountryById)
 
+	// Использование и квоты
+	r.GET("/usage/me", middlewares.JwtMiddleware, controllers.GetMyUsage)
+
+	// Мониторинг портфеля
+	monitoring := r.Group("/", middlewares.JwtMiddleware, middlewares.RequireRole(models.RoleMonitoring, models.RoleAdmin),
+		middlewares.Idempotency)
+	monitoring.POST("/watchlists", controllers.PostWatchlist)
+	monitoring.GET("/watchlists", controllers.GetWatchlists)
+	monitoring.GET("/watchlists/:id", controllers.GetWatchlistById)
+	monitoring.PUT("/watchlists/:id", controllers.PutWatchlist)
+	monitoring.DELETE("/watchlists/:id", controllers.DeleteWatchlist)
+	monitoring.POST("/watchlists/:id/members", controllers.PostWatchlistMembers)
+	monitoring.DELETE("/watchlists/:id/members/:iin", controllers.DeleteWatchlistMember)
+	monitoring.POST("/watchlists/:id/run", controllers.RunWatchlist)
+	monitoring.GET("/monitoring/alerts", controllers.GetMonitoringAlerts)
+
+	// Администрирование
+	admin := r.Group("/admin", middlewares.JwtMiddleware, middlewares.RequireRole(models.RoleAdmin), middlewares.Idempotency)
+	admin.GET("/bureau-credentials", controllers.GetBureauCredentials)
+	admin.PUT("/bureau-credentials", controllers.PutBureauCredential)
+	admin.DELETE("/bureau-credentials/:id", controllers.DeleteBureauCredential)
+	admin.GET("/decision-rules", controllers.GetDecisionRuleSets)
+	admin.POST("/decision-rules", controllers.PostDecisionRuleSet)
+	admin.POST("/decision-rules/dry-run", controllers.PostDecisionRulesDryRun)
+	admin.GET("/decision-rules/:version", controllers.GetDecisionRuleSet)
+	admin.POST("/decision-rules/:version/activate", controllers.ActivateDecisionRuleSet)
+	admin.GET("/report-template", controllers.GetScoreReportTemplate)
+	admin.PUT("/report-template", controllers.PutScoreReportTemplate)
+	admin.DELETE("/report-template", controllers.DeleteScoreReportTemplate)
+	admin.GET("/score-shadow/comparison", controllers.GetScoreShadowComparison)
+	admin.GET("/reason-codes", controllers.GetReasonCodes)
+	admin.PUT("/reason-codes", controllers.PutReasonCode)
+	admin.DELETE("/reason-codes/:code", controllers.DeleteReasonCode)
+	admin.GET("/quotas", controllers.GetScoreQuotas)
+	admin.PUT("/quotas", controllers.PutScoreQuota)
+	admin.DELETE("/quotas/:id", controllers.DeleteScoreQuota)
+	admin.GET("/usage", controllers.GetScoreUsage)
+	admin.GET("/usage/billing", controllers.GetScoreBilling)
+
+	// Аналитика
+	analytics := r.Group("/analytics", middlewares.JwtMiddleware, middlewares.RequireRole(models.RoleAnalyst, models.RoleAdmin))
+	analytics.GET("/score-divergence", controllers.GetScoreDivergence)
+	analytics.GET("/score-divergence/export", controllers.ExportScoreDivergence)
+
+	// Аудит
+	audit := r.Group("/audit", middlewares.JwtMiddleware, middlewares.RequireRole(models.RoleAuditor))
+	audit.GET("/scores/:id/bureau-response", controllers.GetArchivedBureauResponse)
+	audit.GET("/bureau-archive-access", controllers.GetBureauArchiveAccessLog)
+
 	fmt.Print("Server listening on port 8082")
 
 	// Запуск сервера
@@ -3,6 +3,7 @@ package middlewares
 import (
 	"github.com/gin-gonic/gin"
 	"go-keycloak-jwt/helpers"
+	"go-keycloak-jwt/i18n"
 	"net/http"
 )
 
@@ -13,17 +14,26 @@ func JwtMiddleware(c *gin.Context) {
 	} else {
 		c.Redirect(http.StatusTemporaryRedirect, "/login")
 		c.Abort()
+		return
 	}
 	jwts, err := helpers.LoadKeycloakPublicKey()
-	userID, userName, err := helpers.TokenLoader(tokenString, jwts)
+	principal, err := helpers.TokenLoader(tokenString, jwts)
 	if err != nil {
 		c.Redirect(http.StatusTemporaryRedirect, "/login")
 		c.Abort()
+		return
 	}
 
 	// Сохраняем данные в контексте
-	c.Set("userID", userID)
-	c.Set("userName", userName)
+	c.Set("userID", principal.UserId)
+	c.Set("userName", principal.UserName)
+	c.Set("principal", principal)
+
+	// Язык из профиля пользователя применяется, если клиент не прислал Accept-Language
+	if principal.Locale != "" {
+		locale, explicit := i18n.NegotiateExplicit(c.GetHeader("Accept-Language"), principal.Locale)
+		setLocale(c, locale, explicit)
+	}
 
 	c.Next()
 }
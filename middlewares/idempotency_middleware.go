@@ -0,0 +1,154 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/services"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// Заголовок ответа, отданного повторно из сохранённого
+const IdempotentReplayHeader = "Idempotent-Replayed"
+
+const maxIdempotencyKeyLength = 255
+
+// Тело запроса читается целиком ради отпечатка; загрузки пакетов больше этого не принимаются
+const maxIdempotentBodyBytes = 32 << 20
+
+// Idempotency выполняет изменяющий запрос с заголовком Idempotency-Key один раз для пользователя и ключа:
+// повтор с тем же телом получает сохранённый первый ответ, с другим телом — 422, пока первый ещё выполняется — 409.
+// Ответ 5xx сохраняется, только если обработчик отметил платный запрос к провайдеру (billable_inquiry):
+// иначе сбой бюро или разомкнутый выключатель запоминался бы на всё окно, и повтор после восстановления был бы невозможен.
+// Ставится после JwtMiddleware; запросы без заголовка и GET проходят как обычно.
+func Idempotency(c *gin.Context) {
+	key := strings.TrimSpace(c.GetHeader(IdempotencyKeyHeader))
+	if key == "" || c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+		c.Next()
+		return
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key is too long"})
+		return
+	}
+
+	value, _ := c.Get("principal")
+	principal, _ := value.(models.Principal)
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodyBytes))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body is too large"})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	path := c.Request.URL.RequestURI()
+	hash := requestHash(c.GetHeader("Content-Type"), body)
+	replay, err := services.BeginIdempotentRequest(c.Request.Context(), principal, key, c.Request.Method, path, hash)
+	switch {
+	case errors.Is(err, services.ErrIdempotencyKeyReused):
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrIdempotencyKeyInProgress):
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to check Idempotency-Key"})
+		return
+	case replay != nil:
+		c.Header(IdempotentReplayHeader, "true")
+		c.Data(replay.StatusCode, replay.ContentType, replay.Body)
+		c.Abort()
+		return
+	}
+
+	writer := &capturingResponseWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+
+	// Клиент мог уже отключиться, а ответ нужно сохранить для его повтора
+	ctx := context.Background()
+	completed := false
+	defer func() {
+		if !completed {
+			services.ReleaseIdempotentRequest(ctx, principal, key)
+		}
+	}()
+
+	c.Next()
+
+	if writer.Status() < http.StatusInternalServerError || c.GetBool("billable_inquiry") {
+		services.CompleteIdempotentRequest(ctx, principal, key, writer.Status(), writer.Header().Get("Content-Type"), writer.body.Bytes())
+		completed = true
+	}
+}
+
+// capturingResponseWriter копирует тело ответа, чтобы сохранить его для повторов
+type capturingResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *capturingResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *capturingResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// requestHash — отпечаток тела запроса. Для multipart/form-data граница между частями при повторе
+// обычно другая, поэтому хэшируются имена полей, имена файлов и содержимое частей.
+func requestHash(contentType string, body []byte) string {
+	sum := sha256.New()
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err == nil && mediaType == "multipart/form-data" && params["boundary"] != "" {
+		if parts, err := multipartParts(body, params["boundary"]); err == nil {
+			for _, part := range parts {
+				sum.Write([]byte(part))
+				sum.Write([]byte{0})
+			}
+			return hex.EncodeToString(sum.Sum(nil))
+		}
+	}
+	sum.Write(body)
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+func multipartParts(body []byte, boundary string) ([]string, error) {
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	var parts []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part.FormName()+"\x00"+part.FileName()+"\x00"+string(content))
+	}
+	sort.Strings(parts)
+	return parts, nil
+}
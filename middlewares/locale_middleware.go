@@ -0,0 +1,86 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"go-keycloak-jwt/i18n"
+	"net/http"
+	"strings"
+)
+
+// Locale выбирает язык запроса по Accept-Language и дополняет JSON-ответы с ошибкой стабильным кодом ("code")
+// и переведённым сообщением ("message"); английский текст в "error" остаётся для совместимости.
+// Ставится первым, до JwtMiddleware: тот уточняет язык по профилю пользователя.
+func Locale(c *gin.Context) {
+	locale, explicit := i18n.NegotiateExplicit(c.GetHeader("Accept-Language"), "")
+	setLocale(c, locale, explicit)
+
+	writer := &localizingResponseWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+	c.Next()
+
+	if writer.buffering {
+		body := localizeErrorBody(writer.body.Bytes(), writer.Status(), c.GetString("locale"))
+		writer.Header().Del("Content-Length")
+		_, _ = writer.ResponseWriter.Write(body)
+	}
+}
+
+// setLocale сохраняет язык в контексте gin и запроса — оттуда его берут клиенты бюро
+func setLocale(c *gin.Context, locale string, explicit bool) {
+	c.Set("locale", locale)
+	c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), locale, explicit))
+	c.Header("Content-Language", locale)
+}
+
+// localizingResponseWriter придерживает тело JSON-ответа с ошибкой, остальные ответы пишутся сразу
+type localizingResponseWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	buffering bool
+}
+
+func (w *localizingResponseWriter) Write(data []byte) (int, error) {
+	if w.buffering || w.isJSONError() {
+		w.buffering = true
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *localizingResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *localizingResponseWriter) isJSONError() bool {
+	return w.Status() >= http.StatusBadRequest && strings.HasPrefix(w.Header().Get("Content-Type"), "application/json")
+}
+
+func localizeErrorBody(body []byte, status int, locale string) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var payload map[string]interface{}
+	if err := decoder.Decode(&payload); err != nil {
+		return body
+	}
+	text, ok := payload["error"].(string)
+	if !ok {
+		return body
+	}
+
+	code, _ := payload["code"].(string)
+	if code == "" {
+		code = i18n.ErrorCode(status, text)
+		payload["code"] = code
+	}
+	if _, exists := payload["message"]; !exists {
+		payload["message"] = i18n.Message(locale, code)
+	}
+
+	localized, err := json.Marshal(payload)
+	if err != nil {
+		return body
+	}
+	return localized
+}
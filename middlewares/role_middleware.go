@@ -0,0 +1,23 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-keycloak-jwt/models"
+	"net/http"
+)
+
+// RequireRole пропускает запрос, только если у пользователя есть одна из ролей.
+// Ставится после JwtMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("principal")
+		principal, _ := value.(models.Principal)
+		for _, role := range roles {
+			if principal.HasRole(role) {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+	}
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Зашифрованный сырой ответ бюро, привязанный к запросу скоринга.
+// Ответ шифруется собственным ключом данных, который обёрнут мастер-ключом KeyId.
+type BureauArchiveRecord struct {
+	Id         int64     `json:"id"`
+	InquiryId  int64     `json:"inquiry_id"`
+	Operation  string    `json:"operation"`
+	StatusCode int       `json:"status_code"`
+	KeyId      string    `json:"key_id"`
+	WrappedKey []byte    `json:"-"`
+	Ciphertext []byte    `json:"-"`
+	Sha256     string    `json:"sha256"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Исход обращения к архиву
+const (
+	BureauArchiveRead   = "read"
+	BureauArchiveFailed = "failed"
+)
+
+// Расшифрованный ответ бюро для аудитора
+type BureauArchiveResponse struct {
+	InquiryId int64 `json:"inquiry_id"`
+	// Запрос обслужен из кэша: ответ бюро — из архива исходного запроса SourceInquiryId
+	ServedFromCache bool      `json:"served_from_cache"`
+	SourceInquiryId int64     `json:"source_inquiry_id"`
+	Operation       string    `json:"operation"`
+	StatusCode      int       `json:"status_code"`
+	KeyId           string    `json:"key_id"`
+	Sha256          string    `json:"sha256"`
+	ResponseXml     string    `json:"response_xml"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// Запись журнала обращений к архиву
+type BureauArchiveAccess struct {
+	Id        int64  `json:"id"`
+	ArchiveId int64  `json:"archive_id"`
+	InquiryId int64  `json:"inquiry_id"`
+	UserId    string `json:"user_id"`
+	UserName  string `json:"user_name"`
+	Reason    string `json:"reason,omitempty"`
+	// read — ответ выдан, failed — расшифровать не удалось (Error)
+	Outcome    string    `json:"outcome"`
+	Error      string    `json:"error,omitempty"`
+	AccessedAt time.Time `json:"accessed_at"`
+}
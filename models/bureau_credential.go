@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// К кому относятся учётные данные бюро
+const (
+	CredentialSubjectUser    = "user"
+	CredentialSubjectGroup   = "group"
+	CredentialSubjectDefault = "default"
+)
+
+// Учётные данные бюро для пользователя, группы Keycloak или по умолчанию.
+// Пароль наружу не отдаётся.
+type BureauCredential struct {
+	Id          int64     `json:"id"`
+	SubjectType string    `json:"subject_type"`
+	Subject     string    `json:"subject"`
+	UserName    string    `json:"user_name"`
+	Password    string    `json:"-" pii:"secret"`
+	Culture     string    `json:"culture"`
+	Version     string    `json:"version"`
+	UpdatedBy   string    `json:"updated_by,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Запрос на создание или ротацию учётных данных бюро
+type BureauCredentialRequest struct {
+	SubjectType string `json:"subject_type" binding:"required,oneof=user group default"`
+	Subject     string `json:"subject"`
+	UserName    string `json:"user_name" binding:"required"`
+	Password    string `json:"password" binding:"required" pii:"secret"`
+	Culture     string `json:"culture"`
+	Version     string `json:"version"`
+}
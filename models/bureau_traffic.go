@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Записанный обмен с бюро: сырой SOAP-запрос и ответ без учётных данных
+type BureauTrafficRecord struct {
+	Id          int64             `json:"id,omitempty"`
+	Operation   string            `json:"operation"`
+	MatchKey    string            `json:"match_key"`
+	Attributes  map[string]string `json:"attributes,omitempty" pii:"text"`
+	RequestXml  string            `json:"request_xml" pii:"text"`
+	ResponseXml string            `json:"response_xml" pii:"text"`
+	StatusCode  int               `json:"status_code"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	RecordedAt  time.Time         `json:"recorded_at"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Scope, покрывающий все скоринговые карты
+const ConsentScopeAll = "*"
+
+// Согласие субъекта (по ИИН/БИН) на запрос в кредитное бюро.
+// Scope — скоринговые карты, на которые дано согласие, или "*" для всех.
+type Consent struct {
+	Id           int64      `json:"id"`
+	SubjectIin   string     `json:"subject_iin" pii:"iin"`
+	Scope        []string   `json:"scope"`
+	Channel      string     `json:"channel"`
+	GrantedAt    time.Time  `json:"granted_at"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	DocumentRef  string     `json:"document_ref,omitempty"`
+	CreatedBy    string     `json:"created_by"`
+	CreatedAt    time.Time  `json:"created_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy    string     `json:"revoked_by,omitempty"`
+	RevokeReason string     `json:"revoke_reason,omitempty"`
+}
+
+// Valid — согласие не отозвано и не истекло на момент at
+func (c Consent) Valid(at time.Time) bool {
+	return c.RevokedAt == nil && !at.Before(c.GrantedAt) && at.Before(c.ExpiresAt)
+}
+
+// Covers — согласие распространяется на скоринговую карту
+func (c Consent) Covers(scoreCard string) bool {
+	for _, scope := range c.Scope {
+		if scope == ConsentScopeAll || scope == scoreCard {
+			return true
+		}
+	}
+	return false
+}
+
+type ConsentRequest struct {
+	SubjectIin string   `json:"subject_iin" binding:"required" pii:"iin"`
+	Scope      []string `json:"scope" binding:"required,min=1"`
+	Channel    string   `json:"channel" binding:"required"`
+	// По умолчанию — момент регистрации
+	GrantedAt   *time.Time `json:"granted_at"`
+	ExpiresAt   time.Time  `json:"expires_at" binding:"required"`
+	DocumentRef string     `json:"document_ref"`
+}
+
+type ConsentRevokeRequest struct {
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// Решения по заявке, которые могут выдавать правила
+const (
+	DecisionApprove = "approve"
+	DecisionRefer   = "refer"
+	DecisionDecline = "decline"
+)
+
+// Версия набора правил кредитного решения. Правила проверяются по порядку,
+// срабатывает первое, у которого выполнены все условия; иначе — Default.
+type DecisionRuleSet struct {
+	Version   int            `json:"version" yaml:"-"`
+	Name      string         `json:"name" yaml:"name" binding:"required"`
+	Rules     []DecisionRule `json:"rules" yaml:"rules" binding:"dive"`
+	Default   string         `json:"default" yaml:"default" binding:"required,oneof=approve refer decline"`
+	Active    bool           `json:"active" yaml:"-"`
+	CreatedBy string         `json:"created_by,omitempty" yaml:"-"`
+	CreatedAt time.Time      `json:"created_at" yaml:"-"`
+}
+
+type DecisionRule struct {
+	Name     string              `json:"name" yaml:"name" binding:"required"`
+	When     []DecisionCondition `json:"when" yaml:"when" binding:"required,min=1,dive"`
+	Decision string              `json:"decision" yaml:"decision" binding:"required,oneof=approve refer decline"`
+}
+
+// Условие над полем результата скоринга (Score, RiskGradeByML, ScoreCard и т.п.).
+// Операции: eq, ne, gt, gte, lt, lte (значение), in, not_in (список), between (две границы включительно).
+// Числа сравниваются как числа, остальное — как строки, поэтому классы риска A1..E3 упорядочены.
+type DecisionCondition struct {
+	Field string      `json:"field" yaml:"field" binding:"required"`
+	Op    string      `json:"op" yaml:"op" binding:"required"`
+	Value interface{} `json:"value" yaml:"value" swaggertype:"object"`
+}
+
+// Решение по результату скоринга и сработавшее правило
+type ScoreDecision struct {
+	Decision     string `json:"decision"`
+	Rule         string `json:"rule,omitempty"`
+	RulesVersion int    `json:"rules_version"`
+}
+
+// Пробный прогон правил: набор правил (или версия, по умолчанию активная)
+// и результат скоринга — полями или ссылкой на запрос из истории
+type DecisionDryRunRequest struct {
+	Version   int               `json:"version"`
+	RuleSet   *DecisionRuleSet  `json:"rule_set"`
+	InquiryId int64             `json:"inquiry_id"`
+	Facts     map[string]string `json:"facts"`
+}
+
+type DecisionDryRunResponse struct {
+	Decision ScoreDecision       `json:"decision"`
+	Facts    map[string]string   `json:"facts"`
+	Trace    []DecisionRuleTrace `json:"trace"`
+}
+
+// Результат проверки одного правила при пробном прогоне
+type DecisionRuleTrace struct {
+	Rule            string `json:"rule"`
+	Matched         bool   `json:"matched"`
+	FailedCondition string `json:"failed_condition,omitempty"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Сохранённый ответ на запрос с заголовком Idempotency-Key (таблица idempotency_keys).
+// Пока запрос выполняется, StatusCode = 0; повтор с тем же ключом в это время получает 409.
+type IdempotencyRecord struct {
+	UserId      string
+	Key         string
+	Method      string
+	Path        string
+	RequestHash string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
@@ -16,5 +16,5 @@ type TokenData map[string]interface{}
 // Структура для получения данных из POST-запроса
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Password string `json:"password" binding:"required" pii:"secret"`
 }
@@ -0,0 +1,90 @@
+package models
+
+import "time"
+
+// Статусы заявки на кредит: draft → scored → decided → closed; закрыть можно на любом этапе
+const (
+	LoanStatusDraft   = "draft"
+	LoanStatusScored  = "scored"
+	LoanStatusDecided = "decided"
+	LoanStatusClosed  = "closed"
+)
+
+// Заявка на кредит (таблица loan_applications)
+type LoanApplication struct {
+	Id            int64  `json:"id"`
+	ApplicantIin  string `json:"applicant_iin" pii:"iin"`
+	ApplicantName string `json:"applicant_name" pii:"name"`
+	// Страна проживания — запись из countries
+	CountryId   int     `json:"country_id"`
+	CountryName string  `json:"country_name"`
+	CountryCode string  `json:"country_code"`
+	Amount      float64 `json:"amount"`
+	Currency    string  `json:"currency"`
+	TermMonths  int     `json:"term_months"`
+	Status      string  `json:"status"`
+	// Решение по заявке (approve, refer, decline) и комментарий к нему
+	Decision        string    `json:"decision,omitempty"`
+	DecisionComment string    `json:"decision_comment,omitempty" pii:"text"`
+	DecidedBy       string    `json:"decided_by,omitempty"`
+	UserId          string    `json:"user_id"`
+	UserName        string    `json:"user_name"`
+	Team            string    `json:"team"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type LoanApplicationRequest struct {
+	ApplicantIin  string  `json:"applicant_iin" binding:"required" pii:"iin"`
+	ApplicantName string  `json:"applicant_name" binding:"required" pii:"name"`
+	CountryId     int     `json:"country_id" binding:"required"`
+	Amount        float64 `json:"amount" binding:"required,gt=0"`
+	// По умолчанию KZT
+	Currency   string `json:"currency"`
+	TermMonths int    `json:"term_months" binding:"required,min=1,max=600"`
+}
+
+// Запрос скоринга заявителя по карте; атрибут IIN/BIN берётся из заявки
+type LoanApplicationScoreRequest struct {
+	ScoreCard string `json:"score_card" binding:"required"`
+}
+
+// Привязка уже выполненного запроса скоринга того же субъекта
+type LoanApplicationLinkRequest struct {
+	InquiryId int64 `json:"inquiry_id" binding:"required"`
+}
+
+type LoanApplicationDecisionRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=approve refer decline"`
+	Comment  string `json:"comment"`
+}
+
+type LoanApplicationCloseRequest struct {
+	Comment string `json:"comment"`
+}
+
+// Запись истории статусов заявки (таблица loan_application_status_history)
+type LoanApplicationStatusChange struct {
+	Id         int64     `json:"id"`
+	FromStatus string    `json:"from_status,omitempty"`
+	ToStatus   string    `json:"to_status"`
+	Comment    string    `json:"comment,omitempty" pii:"text"`
+	ChangedBy  string    `json:"changed_by"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+// Заявка с привязанными запросами скоринга (новые первыми) и историей статусов
+type LoanApplicationDetails struct {
+	LoanApplication
+	Scores  []ScoreInquiry                `json:"scores"`
+	History []LoanApplicationStatusChange `json:"history"`
+}
+
+type LoanApplicationFilter struct {
+	Status       string
+	ApplicantIin string `pii:"iin"`
+	UserId       string
+	Team         string
+	Limit        int
+	Offset       int
+}
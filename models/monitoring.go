@@ -0,0 +1,109 @@
+package models
+
+import "time"
+
+// Типы правил оповещения мониторинга портфеля
+const (
+	// Класс риска ухудшился не меньше чем на Notches ступеней
+	AlertRuleRiskGradeWorsened = "risk_grade_worsened"
+	// PD перешла в более высокий диапазон (не меньше чем на Notches диапазонов)
+	AlertRulePdBandIncreased = "pd_band_increased"
+	// Балл снизился не меньше чем на Points
+	AlertRuleScoreDropped = "score_dropped"
+)
+
+// Правило оповещения: сравнивает новый результат субъекта с предыдущим
+type MonitoringAlertRule struct {
+	Type string `json:"type" binding:"required"`
+	// Сравниваемое поле: для классов риска RiskGrade (по умолчанию) или RiskGradeByML,
+	// для PD — OneYearProbabilityOfDefault или OneYearProbabilityOfDefaultByML, для балла — Score или ScoreByML
+	Field string `json:"field,omitempty"`
+	// Минимальное ухудшение класса риска или PD-диапазона; по умолчанию 1
+	Notches int `json:"notches,omitempty"`
+	// Минимальное снижение балла для score_dropped
+	Points float64 `json:"points,omitempty"`
+}
+
+// Список наблюдения (таблица watchlists): ИИН заёмщиков, которых периодически перескорят по карте
+type Watchlist struct {
+	Id         int64                 `json:"id"`
+	Name       string                `json:"name"`
+	ScoreCard  string                `json:"score_card"`
+	Interval   string                `json:"interval"`
+	AlertRules []MonitoringAlertRule `json:"alert_rules"`
+	Active     bool                  `json:"active"`
+	Team       string                `json:"team"`
+	CreatedBy  string                `json:"created_by"`
+	// Автор списка, его группы и роли: от его имени идёт перескоринг — с его учётными данными бюро и квотой
+	UserId    string     `json:"-"`
+	Groups    []string   `json:"-"`
+	Roles     []string   `json:"-"`
+	Members   int        `json:"members"`
+	NextRunAt time.Time  `json:"next_run_at"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Субъект списка наблюдения и его последний успешный результат, с которым сравнивается следующий
+type WatchlistMember struct {
+	Id            int64      `json:"id"`
+	WatchlistId   int64      `json:"watchlist_id"`
+	SubjectIin    string     `json:"subject_iin" pii:"iin"`
+	LastInquiryId *int64     `json:"last_inquiry_id,omitempty"`
+	LastScoredAt  *time.Time `json:"last_scored_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty" pii:"text"`
+	AddedAt       time.Time  `json:"added_at"`
+}
+
+type WatchlistRequest struct {
+	Name      string `json:"name" binding:"required"`
+	ScoreCard string `json:"score_card" binding:"required"`
+	// Периодичность перескоринга: длительность Go ("720h") или дни ("30d")
+	Interval   string                `json:"interval" binding:"required"`
+	AlertRules []MonitoringAlertRule `json:"alert_rules"`
+	// По умолчанию список активен
+	Active *bool `json:"active"`
+	// Начальный состав списка
+	SubjectIins []string `json:"subject_iins" pii:"iin"`
+}
+
+type WatchlistMembersRequest struct {
+	SubjectIins []string `json:"subject_iins" binding:"required,min=1" pii:"iin"`
+}
+
+// Список наблюдения с составом
+type WatchlistDetails struct {
+	Watchlist
+	MemberList []WatchlistMember `json:"member_list"`
+}
+
+// Оповещение мониторинга (таблица monitoring_alerts) и состояние его доставки
+type MonitoringAlert struct {
+	Id                int64      `json:"id"`
+	WatchlistId       int64      `json:"watchlist_id"`
+	WatchlistName     string     `json:"watchlist_name"`
+	MemberId          int64      `json:"member_id"`
+	SubjectIin        string     `json:"subject_iin" pii:"iin"`
+	ScoreCard         string     `json:"score_card"`
+	Rule              string     `json:"rule"`
+	Field             string     `json:"field"`
+	PreviousValue     string     `json:"previous_value"`
+	CurrentValue      string     `json:"current_value"`
+	Message           string     `json:"message"`
+	PreviousInquiryId int64      `json:"previous_inquiry_id"`
+	InquiryId         int64      `json:"inquiry_id"`
+	CreatedAt         time.Time  `json:"created_at"`
+	DeliveredAt       *time.Time `json:"delivered_at,omitempty"`
+	DeliveryAttempts  int        `json:"delivery_attempts"`
+	DeliveryError     string     `json:"delivery_error,omitempty"`
+}
+
+// Фильтр оповещений; WatchlistId = 0 — по всем спискам
+type MonitoringAlertFilter struct {
+	WatchlistId int64
+	SubjectIin  string `pii:"iin"`
+	Undelivered bool
+	From        *time.Time
+	To          *time.Time
+	Limit       int
+}
@@ -0,0 +1,43 @@
+package models
+
+// Роли Keycloak, которые понимает сервис
+const (
+	RoleSupervisor = "score_supervisor"
+	// Может запросить свежий результат в обход кэша
+	RoleCacheBypass = "score_cache_bypass"
+	// Администрирование сервиса (учётные данные бюро и т.п.)
+	RoleAdmin = "score_admin"
+	// Доступ к архиву сырых ответов бюро
+	RoleAuditor = "score_auditor"
+	// Видит персональные данные субъектов (ИИН и т.п.) без маскирования
+	RolePIIViewer = "score_pii_viewer"
+	// Аналитика по результатам скоринга (валидация моделей)
+	RoleAnalyst = "score_analyst"
+	// Списки наблюдения и оповещения мониторинга портфеля
+	RoleMonitoring = "score_monitoring"
+)
+
+// Пользователь, от имени которого выполняется запрос (из JWT)
+type Principal struct {
+	UserId   string   `json:"user_id" pii:"id"`
+	UserName string   `json:"user_name" pii:"name"`
+	Team     string   `json:"team,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+	Roles    []string `json:"roles,omitempty"`
+	// Язык из профиля Keycloak (атрибут locale)
+	Locale string `json:"locale,omitempty"`
+}
+
+func (p Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// CanViewPII — пользователю можно показывать персональные данные без маскирования
+func (p Principal) CanViewPII() bool {
+	return p.HasRole(RolePIIViewer) || p.HasRole(RoleAuditor)
+}
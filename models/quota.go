@@ -0,0 +1,88 @@
+package models
+
+import "time"
+
+// Области квот: пользователь или организация (команда/департамент из токена)
+const (
+	QuotaScopeUser = "user"
+	QuotaScopeTeam = "team"
+
+	QuotaPeriodDay   = "day"
+	QuotaPeriodMonth = "month"
+
+	// Scope или ScoreCard, подходящие под любого пользователя/команду или любую карту
+	QuotaAny = "*"
+)
+
+// Квота на платные запросы скоринга (таблица score_quotas).
+// Scope "*" задаёт квоту каждому пользователю (или каждой команде) отдельно; ScoreCard "*" — по всем картам вместе.
+type ScoreQuota struct {
+	Id        int64     `json:"id"`
+	ScopeType string    `json:"scope_type"`
+	Scope     string    `json:"scope"`
+	ScoreCard string    `json:"score_card"`
+	Period    string    `json:"period"`
+	Limit     int64     `json:"limit"`
+	UpdatedBy string    `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ScoreQuotaRequest struct {
+	ScopeType string `json:"scope_type" binding:"required,oneof=user team"`
+	Scope     string `json:"scope" binding:"required"`
+	// По умолчанию "*" — все карты
+	ScoreCard string `json:"score_card"`
+	Period    string `json:"period" binding:"required,oneof=day month"`
+	Limit     int64  `json:"limit" binding:"min=0"`
+}
+
+// Состояние квоты для конкретного пользователя или команды
+type ScoreQuotaStatus struct {
+	Quota     ScoreQuota `json:"quota"`
+	Subject   string     `json:"subject"`
+	Used      int64      `json:"used"`
+	Remaining int64      `json:"remaining"`
+	ResetsAt  time.Time  `json:"resets_at"`
+}
+
+// QuotaExceededError — квота исчерпана, запрос к провайдеру не выполнялся
+type QuotaExceededError struct {
+	Status ScoreQuotaStatus
+}
+
+func (e *QuotaExceededError) Error() string {
+	return "score quota exceeded: " + e.Status.Quota.Period + " limit for " + e.Status.Quota.ScopeType + " " +
+		e.Status.Subject
+}
+
+// Фильтр учёта использования; платные запросы — ответы провайдера, не из кэша
+type ScoreUsageFilter struct {
+	From      *time.Time
+	To        *time.Time
+	Team      string
+	UserId    string
+	ScoreCard string
+}
+
+// Использование по пользователю, команде, карте и провайдеру
+type ScoreUsageRow struct {
+	Team          string `json:"team"`
+	UserId        string `json:"user_id"`
+	UserName      string `json:"user_name" pii:"name"`
+	ScoreCard     string `json:"score_card"`
+	Provider      string `json:"provider"`
+	BillableCalls int64  `json:"billable_calls"`
+	CachedCalls   int64  `json:"cached_calls"`
+	FailedCalls   int64  `json:"failed_calls"`
+}
+
+// Строка счёта за месяц по департаменту
+type ScoreBillingRow struct {
+	Month     string   `json:"month"`
+	Team      string   `json:"team"`
+	ScoreCard string   `json:"score_card"`
+	Provider  string   `json:"provider"`
+	Calls     int64    `json:"calls"`
+	UnitPrice *float64 `json:"unit_price,omitempty"`
+	Amount    *float64 `json:"amount,omitempty"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Код причины из каталога (таблица score_reason_codes): серьёзность для ранжирования,
+// текст для клиента на каждом языке и пояснение для сотрудников
+type ReasonCode struct {
+	Code string `json:"code"`
+	// 1 — наименее, 5 — наиболее существенная причина
+	Severity int `json:"severity"`
+	// Текст для клиента по языкам: {"ru": "...", "kk": "...", "en": "..."}
+	CustomerText        map[string]string `json:"customer_text"`
+	InternalExplanation string            `json:"internal_explanation"`
+	UpdatedBy           string            `json:"updated_by"`
+	UpdatedAt           time.Time         `json:"updated_at"`
+}
+
+type ReasonCodeRequest struct {
+	Code                string            `json:"code" binding:"required"`
+	Severity            int               `json:"severity" binding:"min=1,max=5"`
+	CustomerText        map[string]string `json:"customer_text" binding:"required"`
+	InternalExplanation string            `json:"internal_explanation"`
+}
+
+// Причина в объяснении результата скоринга, в порядке значимости
+type ScoreReason struct {
+	Rank     int    `json:"rank"`
+	Code     string `json:"code"`
+	Severity int    `json:"severity"`
+	// Текст для письма клиенту; для кода вне каталога — текст бюро
+	Text                string `json:"text"`
+	InternalExplanation string `json:"internal_explanation,omitempty"`
+	BureauText          string `json:"bureau_text"`
+	// false — кода нет в каталоге, его стоит добавить
+	Cataloged bool `json:"cataloged"`
+}
+
+// Объяснение результата скоринга для уведомления заявителя об отказе
+type ScoreExplanation struct {
+	InquiryId     int64         `json:"inquiry_id"`
+	SubjectIin    string        `json:"subject_iin" pii:"iin"`
+	ScoreCard     string        `json:"score_card"`
+	Provider      string        `json:"provider"`
+	Score         string        `json:"score"`
+	RiskGrade     string        `json:"risk_grade"`
+	ScoreByML     string        `json:"score_by_ml"`
+	RiskGradeByML string        `json:"risk_grade_by_ml"`
+	Decision      string        `json:"decision,omitempty"`
+	Locale        string        `json:"locale"`
+	Reasons       []ScoreReason `json:"reasons"`
+	InquiredAt    time.Time     `json:"inquired_at"`
+}
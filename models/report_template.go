@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Оформление отчёта о скоринге (PDF и XLSX). Администратор может заменить его целиком;
+// незаданные подписи берутся из шаблона по умолчанию.
+type ReportTemplate struct {
+	Title string `json:"title" yaml:"title"`
+	Brand string `json:"brand" yaml:"brand"`
+	// Цвет шапки и заголовков таблиц, #RRGGBB
+	BrandColor string `json:"brand_color" yaml:"brand_color"`
+	// Логотип в PNG, base64
+	LogoPng string `json:"logo_png,omitempty" yaml:"logo_png"`
+	// text/template; доступны .Officer, .GeneratedBy, .GeneratedAt, .InquiryId, .IdQuery
+	Footer string            `json:"footer" yaml:"footer"`
+	Labels map[string]string `json:"labels" yaml:"labels"`
+	// Диапазоны вероятности дефолта в тех же единицах, что возвращает бюро
+	PdRanges  []ReportPdRange `json:"pd_ranges,omitempty" yaml:"pd_ranges"`
+	UpdatedBy string          `json:"updated_by,omitempty" yaml:"-"`
+	UpdatedAt *time.Time      `json:"updated_at,omitempty" yaml:"-"`
+}
+
+// Диапазон PD (From, To] в долях единицы с подписью, например "Низкий риск": 0.01–0.03 — это 1%–3%.
+// PD бюро "2% - 3%" сравнивается по верхней границе и попадает в диапазон 0.01–0.03
+type ReportPdRange struct {
+	Label string  `json:"label" yaml:"label"`
+	From  float64 `json:"from" yaml:"from"`
+	To    float64 `json:"to" yaml:"to"`
+}
@@ -22,7 +22,7 @@ type ScoreCardsBodyXml struct {
 }
 
 type GetScoreCardsResponseXml struct {
-	ReturnData ScoreCardsReturnDataXml `xml:"return"`
+	ReturnData []ScoreCardsReturnDataXml `xml:"return"`
 }
 
 type ScoreCardsReturnDataXml struct {
@@ -39,7 +39,7 @@ type ScoreRequest struct {
 		ScoreCard  string `json:"ScoreCard"`
 		Attributes struct {
 			Name   string `json:"name"`
-			Value  string `json:"value"`
+			Value  string `json:"value" pii:"text"`
 			Values struct {
 				Id    string `json:"id"`
 				Value string `json:"value"`
@@ -53,35 +53,79 @@ type ScoreResponseXml struct {
 }
 
 type EnvelopeScoreXml struct {
-	Body    ScoreBodyXml `json:"Body"`
-	XmlnsS  string       `json:"_xmlns:S"`
-	PrefixS string       `json:"__prefix"`
+	Body    ScoreBodyXml `json:"Body" xml:"Body"`
+	XmlnsS  string       `json:"_xmlns:S" xml:"-"`
+	PrefixS string       `json:"__prefix" xml:"-"`
 }
 
 type ScoreBodyXml struct {
-	ScoreResponse ScoreResponseDetailsXml `json:"ScoreResponse"`
-	PrefixS       string                  `json:"__prefix"`
+	ScoreResponse ScoreResponseDetailsXml `json:"ScoreResponse" xml:"ScoreResponse"`
+	PrefixS       string                  `json:"__prefix" xml:"-"`
 }
 
 type ScoreResponseDetailsXml struct {
-	Return ReturnDetailsXml `json:"return"`
-	Xmlns  string           `json:"_xmlns"`
+	Return ReturnDetailsXml `json:"return" xml:"return"`
+	Xmlns  string           `json:"_xmlns" xml:"-"`
 }
 
 type ReturnDetailsXml struct {
-	IdQuery                         string `json:"IdQuery"`
-	ErrorCode                       string `json:"ErrorCode"`
-	ErrorString                     string `json:"ErrorString"`
-	Score                           string `json:"Score"`
-	OneYearProbabilityOfDefault     string `json:"OneYearProbabilityOfDefault"`
-	RiskGrade                       string `json:"RiskGrade"`
-	ScoreByML                       string `json:"ScoreByML"`
-	OneYearProbabilityOfDefaultByML string `json:"OneYearProbabilityOfDefaultByML"`
-	RiskGradeByML                   string `json:"RiskGradeByML"`
-	Causes                          Causes `json:"Causes"`
+	IdQuery                         string   `json:"IdQuery"`
+	ErrorCode                       string   `json:"ErrorCode"`
+	ErrorString                     string   `json:"ErrorString"`
+	Score                           string   `json:"Score"`
+	OneYearProbabilityOfDefault     string   `json:"OneYearProbabilityOfDefault"`
+	RiskGrade                       string   `json:"RiskGrade"`
+	ScoreByML                       string   `json:"ScoreByML"`
+	OneYearProbabilityOfDefaultByML string   `json:"OneYearProbabilityOfDefaultByML"`
+	RiskGradeByML                   string   `json:"RiskGradeByML"`
+	Causes                          []Causes `json:"Causes"`
 }
 
+// Причина низкого балла; бюро возвращает их несколько, name — код причины
 type Causes struct {
-	Name      string `json:"name"`
-	CauseText string `json:"causeText"`
+	Name      string `json:"name" xml:"name"`
+	CauseText string `json:"causeText" xml:"causeText"`
+}
+
+// Структуры для SOAP-запросов в бюро
+type SoapRequestEnvelopeXml struct {
+	XMLName xml.Name           `xml:"S:Envelope"`
+	XmlnsS  string             `xml:"xmlns:S,attr"`
+	Body    SoapRequestBodyXml `xml:"S:Body"`
+}
+
+type SoapRequestBodyXml struct {
+	GetScoreCards *GetScoreCardsRequestXml `xml:"GetScoreCards,omitempty"`
+	Score         *ScoreRequestXml         `xml:"Score,omitempty"`
+}
+
+type GetScoreCardsRequestXml struct {
+	Xmlns string `xml:"xmlns,attr"`
+}
+
+type ScoreRequestXml struct {
+	Xmlns      string                   `xml:"xmlns,attr"`
+	ScoreCard  string                   `xml:"ScoreCard"`
+	Attributes ScoreRequestAttributeXml `xml:"attributes"`
+}
+
+type ScoreRequestAttributeXml struct {
+	Name   string                          `xml:"name"`
+	Value  string                          `xml:"value,omitempty"`
+	Values *ScoreRequestAttributeValuesXml `xml:"values,omitempty"`
+}
+
+type ScoreRequestAttributeValuesXml struct {
+	Id    string `xml:"id"`
+	Value string `xml:"value"`
+}
+
+// SOAP Fault, который бюро возвращает при ошибке обработки запроса
+type SoapFaultEnvelopeXml struct {
+	Body struct {
+		Fault *struct {
+			Code   string `xml:"faultcode"`
+			String string `xml:"faultstring"`
+		} `xml:"Fault"`
+	} `xml:"Body"`
 }
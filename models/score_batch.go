@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// Статусы пакетного задания и его строк
+const (
+	ScoreBatchPending  = "pending"
+	ScoreBatchRunning  = "running"
+	ScoreBatchFinished = "finished"
+
+	ScoreBatchItemPending = "pending"
+	ScoreBatchItemDone    = "done"
+	ScoreBatchItemFailed  = "failed"
+)
+
+// Пакетное задание скоринга (таблица score_batches)
+type ScoreBatch struct {
+	Id       int64  `json:"id"`
+	UserId   string `json:"user_id"`
+	UserName string `json:"user_name"`
+	Team     string `json:"team"`
+	// Группы и роли автора на момент загрузки: от его имени строки скорятся в фоне
+	Groups        []string   `json:"-"`
+	Roles         []string   `json:"-"`
+	ScoreCard     string     `json:"score_card"`
+	AttributeName string     `json:"attribute_name"`
+	FileName      string     `json:"file_name"`
+	Status        string     `json:"status"`
+	Total         int        `json:"total"`
+	Processed     int        `json:"processed"`
+	Failed        int        `json:"failed"`
+	CreatedAt     time.Time  `json:"created_at"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+}
+
+// Строка пакетного задания — один субъект из загруженного файла
+type ScoreBatchItem struct {
+	Id        int64  `json:"id"`
+	BatchId   int64  `json:"batch_id"`
+	RowNumber int    `json:"row_number"`
+	Subject   string `json:"subject" pii:"iin"`
+	Status    string `json:"status"`
+	InquiryId *int64 `json:"inquiry_id,omitempty"`
+	Error     string `json:"error,omitempty" pii:"text"`
+}
+
+// Строка результата пакетного задания для выгрузки
+type ScoreBatchResultRow struct {
+	RowNumber                       int
+	Subject                         string `pii:"iin"`
+	Status                          string
+	InquiryId                       *int64
+	IdQuery                         string
+	Score                           string
+	OneYearProbabilityOfDefault     string
+	RiskGrade                       string
+	ScoreByML                       string
+	OneYearProbabilityOfDefaultByML string
+	RiskGradeByML                   string
+	Error                           string `pii:"text"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// Фильтр аналитики расхождений классического и ML-скоринга.
+// Учитываются только успешные запросы, реально ушедшие к провайдеру (не из кэша).
+type ScoreDivergenceFilter struct {
+	// Шкала классов риска от лучшего к худшему; расхождение — разница позиций на ней
+	RiskGrades []string
+	ScoreCard  string
+	Provider   string
+	From       *time.Time
+	To         *time.Time
+	// Шаг динамики: day, week или month
+	Interval string
+	// Количество случаев с наибольшим расхождением в отчёте
+	Top int
+	// Для выгрузки: только сравнимые запросы с разными классами риска
+	MismatchesOnly bool
+}
+
+// Сводка расхождений по группе запросов. Баллы классической и ML-модели в разных шкалах и не сравниваются;
+// GradeNotchDelta = позиция RiskGradeByML − позиция RiskGrade на шкале классов (больше нуля — ML оценивает риск выше).
+// Несравнимые — запросы, где одного из классов нет или его нет на шкале; в доле расхождений они не участвуют.
+type ScoreDivergenceStats struct {
+	ScoreCard              string     `json:"score_card,omitempty"`
+	Period                 *time.Time `json:"period,omitempty"`
+	Total                  int64      `json:"total"`
+	Comparable             int64      `json:"comparable"`
+	NotComparable          int64      `json:"not_comparable"`
+	GradeMismatches        int64      `json:"grade_mismatches"`
+	MismatchRate           float64    `json:"mismatch_rate"`
+	GradeNotchDeltaMean    *float64   `json:"grade_notch_delta_mean"`
+	GradeNotchDeltaMeanAbs *float64   `json:"grade_notch_delta_mean_abs"`
+}
+
+// Количество запросов с данной парой классов риска (классический и ML) по карте
+type ScoreGradePair struct {
+	ScoreCard     string `json:"score_card"`
+	RiskGrade     string `json:"RiskGrade"`
+	RiskGradeByML string `json:"RiskGradeByML"`
+	Count         int64  `json:"count"`
+}
+
+// Запрос, в котором классический и ML-результаты расходятся
+type ScoreDivergenceCase struct {
+	InquiryId     int64  `json:"inquiry_id"`
+	ScoreCard     string `json:"score_card"`
+	Provider      string `json:"provider"`
+	SubjectIin    string `json:"subject_iin" pii:"iin"`
+	Score         string `json:"Score"`
+	RiskGrade     string `json:"RiskGrade"`
+	ScoreByML     string `json:"ScoreByML"`
+	RiskGradeByML string `json:"RiskGradeByML"`
+	Comparable    bool   `json:"comparable"`
+	GradeMismatch bool   `json:"grade_mismatch"`
+	// Разница позиций классов риска (ML − классический); нет для несравнимых
+	GradeNotchDelta *int      `json:"grade_notch_delta"`
+	InquiredAt      time.Time `json:"inquired_at"`
+}
+
+// Отчёт о расхождении классического и ML-скоринга
+type ScoreDivergenceReport struct {
+	Summary     ScoreDivergenceStats   `json:"summary"`
+	ByScoreCard []ScoreDivergenceStats `json:"by_score_card"`
+	ByPeriod    []ScoreDivergenceStats `json:"by_period"`
+	GradePairs  []ScoreGradePair       `json:"grade_pairs"`
+	TopCases    []ScoreDivergenceCase  `json:"top_cases"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Запись о запросе скоринга и его результате (таблица score_inquiries).
+// Если ответ отдан из кэша, CachedFromId указывает на исходный запрос,
+// а InquiredAt — на время, когда бюро реально вызывалось.
+type ScoreInquiry struct {
+	Id                              int64           `json:"id"`
+	UserId                          string          `json:"user_id"`
+	UserName                        string          `json:"user_name"`
+	Team                            string          `json:"team"`
+	SubjectIin                      string          `json:"subject_iin" pii:"iin"`
+	ScoreCard                       string          `json:"score_card"`
+	Provider                        string          `json:"provider"`
+	Attributes                      json.RawMessage `json:"attributes" swaggertype:"object" pii:"text"`
+	IdQuery                         string          `json:"IdQuery"`
+	ErrorCode                       string          `json:"ErrorCode"`
+	ErrorString                     string          `json:"ErrorString" pii:"text"`
+	Score                           string          `json:"Score"`
+	OneYearProbabilityOfDefault     string          `json:"OneYearProbabilityOfDefault"`
+	RiskGrade                       string          `json:"RiskGrade"`
+	ScoreByML                       string          `json:"ScoreByML"`
+	OneYearProbabilityOfDefaultByML string          `json:"OneYearProbabilityOfDefaultByML"`
+	RiskGradeByML                   string          `json:"RiskGradeByML"`
+	Causes                          []Causes        `json:"Causes"`
+	LatencyMs                       int64           `json:"latency_ms"`
+	CachedFromId                    *int64          `json:"cached_from_id,omitempty"`
+	ConsentId                       *int64          `json:"consent_id,omitempty"`
+	Decision                        string          `json:"decision,omitempty"`
+	DecisionRule                    string          `json:"decision_rule,omitempty"`
+	DecisionRulesVersion            *int            `json:"decision_rules_version,omitempty"`
+	InquiredAt                      time.Time       `json:"inquired_at"`
+	CreatedAt                       time.Time       `json:"created_at"`
+}
+
+// Billable — запрос оплачивается: ушёл к провайдеру (не из кэша) и провайдер ответил
+func (i ScoreInquiry) Billable() bool {
+	return i.Id != 0 && i.CachedFromId == nil && i.ErrorCode != "-1"
+}
+
+// Фильтр для выборки из истории запросов скоринга
+type ScoreInquiryFilter struct {
+	SubjectIin string `pii:"iin"`
+	UserId     string
+	Team       string
+	ScoreCard  string
+	RiskGrade  string
+	ErrorCode  string
+	From       *time.Time
+	To         *time.Time
+	// Поле сортировки (created_at или latency_ms) и направление (asc или desc)
+	Sort   string
+	Order  string
+	Cursor string
+	Limit  int
+}
+
+// Страница истории запросов; NextCursor пуст, если дальше записей нет
+type ScoreInquiryPage struct {
+	Data       []ScoreInquiry `json:"data"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// Закэшированный результат провайдера
+type ScoreCacheEntry struct {
+	Result     ScoreResult `json:"result"`
+	InquiryId  int64       `json:"inquiry_id"`
+	InquiredAt time.Time   `json:"inquired_at"`
+}
@@ -0,0 +1,84 @@
+package models
+
+// Провайдер по умолчанию — SOAP-сервис Creditinfo
+const ProviderCreditinfo = "creditinfo"
+
+// Нормализованный результат скоринга: одинаков для любого провайдера (бюро или внутренней модели).
+// Имена полей совпадают с ответом Creditinfo, чтобы клиентам не пришлось их менять.
+type ScoreResult struct {
+	Provider                        string   `json:"provider"`
+	ScoreCard                       string   `json:"score_card"`
+	IdQuery                         string   `json:"IdQuery"`
+	ErrorCode                       string   `json:"ErrorCode"`
+	ErrorString                     string   `json:"ErrorString"`
+	Score                           string   `json:"Score"`
+	OneYearProbabilityOfDefault     string   `json:"OneYearProbabilityOfDefault"`
+	RiskGrade                       string   `json:"RiskGrade"`
+	ScoreByML                       string   `json:"ScoreByML"`
+	OneYearProbabilityOfDefaultByML string   `json:"OneYearProbabilityOfDefaultByML"`
+	RiskGradeByML                   string   `json:"RiskGradeByML"`
+	Causes                          []Causes `json:"Causes"`
+}
+
+// Скоринговая карта провайдера и атрибуты, которые она принимает
+type ScoreCard struct {
+	Name       string   `json:"name"`
+	Provider   string   `json:"provider"`
+	Attributes []string `json:"attributes"`
+}
+
+// ScoreResultFromEnvelope переводит ответ Creditinfo в нормализованный вид
+func ScoreResultFromEnvelope(provider string, scoreCard string, response ScoreResponseXml) ScoreResult {
+	r := response.Envelope.Body.ScoreResponse.Return
+	result := ScoreResult{
+		Provider:                        provider,
+		ScoreCard:                       scoreCard,
+		IdQuery:                         r.IdQuery,
+		ErrorCode:                       r.ErrorCode,
+		ErrorString:                     r.ErrorString,
+		Score:                           r.Score,
+		OneYearProbabilityOfDefault:     r.OneYearProbabilityOfDefault,
+		RiskGrade:                       r.RiskGrade,
+		ScoreByML:                       r.ScoreByML,
+		OneYearProbabilityOfDefaultByML: r.OneYearProbabilityOfDefaultByML,
+		RiskGradeByML:                   r.RiskGradeByML,
+		Causes:                          r.Causes,
+	}
+	return result
+}
+
+// Envelope — результат в прежнем формате ответа Creditinfo для поля response в POST /score
+func (r ScoreResult) Envelope() ScoreResponseXml {
+	var response ScoreResponseXml
+	response.Envelope.Body.ScoreResponse.Return = ReturnDetailsXml{
+		IdQuery:                         r.IdQuery,
+		ErrorCode:                       r.ErrorCode,
+		ErrorString:                     r.ErrorString,
+		Score:                           r.Score,
+		OneYearProbabilityOfDefault:     r.OneYearProbabilityOfDefault,
+		RiskGrade:                       r.RiskGrade,
+		ScoreByML:                       r.ScoreByML,
+		OneYearProbabilityOfDefaultByML: r.OneYearProbabilityOfDefaultByML,
+		RiskGradeByML:                   r.RiskGradeByML,
+		Causes:                          r.Causes,
+	}
+	return response
+}
+
+// ScoreCardFromXml переводит описание карты Creditinfo в нормализованный вид
+func ScoreCardFromXml(provider string, card ScoreCardsReturnDataXml) ScoreCard {
+	result := ScoreCard{Name: card.Name, Provider: provider, Attributes: []string{}}
+	for _, attribute := range card.Attributes {
+		result.Attributes = append(result.Attributes, attribute.Name)
+	}
+	return result
+}
+
+// Xml — карта в прежнем формате ответа Creditinfo для поля response в POST /get-score-cards
+func (c ScoreCard) Xml() ScoreCardsReturnDataXml {
+	card := ScoreCardsReturnDataXml{Name: c.Name}
+	for _, attribute := range c.Attributes {
+		card.Attributes = append(card.Attributes, ScoreCardsAttributeXml{Name: attribute})
+	}
+	return card
+}
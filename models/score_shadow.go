@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Результат теневого скоринга (таблица score_shadow_results): тот же запрос,
+// отправленный вторичному провайдеру. Вызывающему не возвращается, нужен только для сравнения.
+type ScoreShadowResult struct {
+	Id                              int64     `json:"id"`
+	InquiryId                       int64     `json:"inquiry_id"`
+	Provider                        string    `json:"provider"`
+	ScoreCard                       string    `json:"score_card"`
+	IdQuery                         string    `json:"IdQuery"`
+	ErrorCode                       string    `json:"ErrorCode"`
+	ErrorString                     string    `json:"ErrorString" pii:"text"`
+	Score                           string    `json:"Score"`
+	OneYearProbabilityOfDefault     string    `json:"OneYearProbabilityOfDefault"`
+	RiskGrade                       string    `json:"RiskGrade"`
+	ScoreByML                       string    `json:"ScoreByML"`
+	OneYearProbabilityOfDefaultByML string    `json:"OneYearProbabilityOfDefaultByML"`
+	RiskGradeByML                   string    `json:"RiskGradeByML"`
+	LatencyMs                       int64     `json:"latency_ms"`
+	CreatedAt                       time.Time `json:"created_at"`
+}
+
+// Фильтр для сравнения основного и теневого провайдеров
+type ScoreShadowFilter struct {
+	Provider       string
+	ShadowProvider string
+	ScoreCard      string
+	From           *time.Time
+	To             *time.Time
+}
+
+// Сравнение основного и теневого провайдеров по одной паре провайдеров и карте.
+// Совпадение класса риска и разница баллов считаются только по парам, где оба ответили без ошибки;
+// классы сравниваются, только если оба провайдера их вернули: RiskGrade — классические, RiskGradeByML — ML
+// (для теневой ML-модели). ScoreDelta = теневой балл − основной, PdByMLDelta — то же для ML-вероятности
+// дефолта в долях единицы (для диапазона бюро — его верхняя граница, см. pd.Parse).
+type ScoreShadowComparison struct {
+	Provider               string   `json:"provider"`
+	ShadowProvider         string   `json:"shadow_provider"`
+	ScoreCard              string   `json:"score_card"`
+	Total                  int64    `json:"total"`
+	ShadowErrors           int64    `json:"shadow_errors"`
+	Compared               int64    `json:"compared"`
+	RiskGradeCompared      int64    `json:"risk_grade_compared"`
+	RiskGradeMatches       int64    `json:"risk_grade_matches"`
+	RiskGradeAgreement     *float64 `json:"risk_grade_agreement"`
+	RiskGradeByMLCompared  int64    `json:"risk_grade_by_ml_compared"`
+	RiskGradeByMLMatches   int64    `json:"risk_grade_by_ml_matches"`
+	RiskGradeByMLAgreement *float64 `json:"risk_grade_by_ml_agreement"`
+	ScoreDeltaMean         *float64 `json:"score_delta_mean"`
+	ScoreDeltaMeanAbs      *float64 `json:"score_delta_mean_abs"`
+	ScoreDeltaMin          *float64 `json:"score_delta_min"`
+	ScoreDeltaMax          *float64 `json:"score_delta_max"`
+	PdByMLDeltaMean        *float64 `json:"pd_by_ml_delta_mean"`
+	PdByMLDeltaMeanAbs     *float64 `json:"pd_by_ml_delta_mean_abs"`
+	PrimaryLatencyMeanMs   *float64 `json:"primary_latency_mean_ms"`
+	ShadowLatencyMeanMs    *float64 `json:"shadow_latency_mean_ms"`
+}
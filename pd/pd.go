@@ -0,0 +1,52 @@
+// Package pd разбирает вероятность дефолта (PD) в том виде, как её возвращают провайдеры скоринга.
+// Бюро присылает диапазон в процентах ("2% - 3%"), внутренние модели — процент ("2.5%") или долю ("0.025").
+// Значение приводится к доле единицы; для диапазона берётся верхняя граница — самая осторожная оценка риска.
+// Поэтому пороги и диапазоны PD сравниваются с ним как (от, до]: "2% - 3%" попадает в (0.01, 0.03].
+package pd
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var ErrFormat = errors.New(`must be a percent range like "2% - 3%", a percent or a fraction from 0 to 1`)
+
+// Parse — PD в долях единицы: верхняя граница диапазона "a% - b%", процент "b%" или доля
+func Parse(value string) (float64, error) {
+	value = strings.TrimSpace(strings.ReplaceAll(value, ",", "."))
+	percent := strings.HasSuffix(value, "%")
+
+	if i := strings.LastIndex(value, "-"); i > 0 {
+		// Диапазон записывается только в процентах; у нижней границы знак % необязателен
+		if !percent {
+			return 0, ErrFormat
+		}
+		lower, err := parseNumber(value[:i], true)
+		if err != nil {
+			return 0, err
+		}
+		to, err := parseNumber(value[i+1:], true)
+		if err != nil || to < lower {
+			return 0, ErrFormat
+		}
+		return to, nil
+	}
+	return parseNumber(value, percent)
+}
+
+func parseNumber(value string, percent bool) (float64, error) {
+	value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "%"))
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, ErrFormat
+	}
+	if percent {
+		number /= 100
+	}
+	if math.IsNaN(number) || number < 0 || number > 1 {
+		return 0, ErrFormat
+	}
+	return number, nil
+}
@@ -0,0 +1,38 @@
+package pd
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float64
+		err   error
+	}{
+		{value: "2% - 3%", want: 0.03},
+		{value: "0% - 1%", want: 0.01},
+		{value: "10%-15%", want: 0.15},
+		{value: "2 - 3%", want: 0.03},
+		{value: "2,5%", want: 0.025},
+		{value: "0.025", want: 0.025},
+		{value: "", err: ErrFormat},
+		{value: "2 - 3", err: ErrFormat},
+		{value: "3% - 2%", err: ErrFormat},
+		{value: "150%", err: ErrFormat},
+		{value: "12", err: ErrFormat},
+		{value: "NaN", err: ErrFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := Parse(tt.value)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.value, err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
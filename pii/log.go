@@ -0,0 +1,21 @@
+package pii
+
+import "io"
+
+// logWriter маскирует ИИН и токены во всём, что пишется в лог
+type logWriter struct {
+	next io.Writer
+}
+
+// NewLogWriter оборачивает вывод логов (log.SetOutput, gin.DefaultWriter)
+func NewLogWriter(next io.Writer) io.Writer {
+	return logWriter{next: next}
+}
+
+func (w logWriter) Write(p []byte) (int, error) {
+	if _, err := w.next.Write([]byte(RedactText(string(p)))); err != nil {
+		return 0, err
+	}
+	// Вызывающему сообщаем исходную длину: маскирование меняет размер строки
+	return len(p), nil
+}
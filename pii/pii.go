@@ -0,0 +1,92 @@
+// Package pii классифицирует персональные данные и маскирует их в логах, ответах и выгрузках.
+//
+// Поля моделей размечаются тегом `pii`:
+//
+//	iin    — ИИН/БИН: 8501******34
+//	name   — имя: первая буква каждого слова, остальное звёздочки
+//	id     — идентификатор пользователя: первые и последние символы
+//	secret — пароль, токен: скрывается целиком
+//	text   — свободный текст (JSON атрибутов, сообщение об ошибке): маскируются найденные в нём ИИН и токены
+package pii
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Классы персональных данных (значения тега pii)
+const (
+	ClassIIN    = "iin"
+	ClassName   = "name"
+	ClassID     = "id"
+	ClassSecret = "secret"
+	ClassText   = "text"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	// Числа ищутся целиком: ИИН или БИН — ровно 12 цифр, не часть более длинного числа
+	digitsPattern = regexp.MustCompile(`[0-9]+`)
+	// Bearer-токены и JWT в любом месте строки
+	bearerPattern = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`)
+	jwtPattern    = regexp.MustCompile(`eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+)
+
+// MaskIIN оставляет первые 4 и последние 2 цифры: 850101300025 → 8501******25
+func MaskIIN(value string) string {
+	if len(value) <= 6 {
+		return strings.Repeat("*", len(value))
+	}
+	return value[:4] + strings.Repeat("*", len(value)-6) + value[len(value)-2:]
+}
+
+// MaskName оставляет первую букву каждого слова: Иван Петров → И*** П*****
+func MaskName(value string) string {
+	words := strings.Fields(value)
+	for i, word := range words {
+		first, size := utf8.DecodeRuneInString(word)
+		words[i] = string(first) + strings.Repeat("*", utf8.RuneCountInString(word[size:]))
+	}
+	return strings.Join(words, " ")
+}
+
+// MaskID оставляет по два символа с краёв, чтобы записи можно было сопоставить
+func MaskID(value string) string {
+	if utf8.RuneCountInString(value) <= 4 {
+		return strings.Repeat("*", utf8.RuneCountInString(value))
+	}
+	runes := []rune(value)
+	return string(runes[:2]) + strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-2:])
+}
+
+// RedactText маскирует ИИН/БИН, Bearer-токены и JWT внутри произвольного текста
+func RedactText(value string) string {
+	value = bearerPattern.ReplaceAllString(value, "${1}"+redacted)
+	value = jwtPattern.ReplaceAllString(value, redacted)
+	return digitsPattern.ReplaceAllStringFunc(value, func(digits string) string {
+		if len(digits) != 12 {
+			return digits
+		}
+		return MaskIIN(digits)
+	})
+}
+
+// MaskString маскирует значение по классу; неизвестный класс считается секретом
+func MaskString(class string, value string) string {
+	if value == "" {
+		return value
+	}
+	switch class {
+	case ClassIIN:
+		return MaskIIN(value)
+	case ClassName:
+		return MaskName(value)
+	case ClassID:
+		return MaskID(value)
+	case ClassText:
+		return RedactText(value)
+	}
+	return redacted
+}
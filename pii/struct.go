@@ -0,0 +1,83 @@
+package pii
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Mask возвращает копию значения, в которой поля с тегом `pii` замаскированы.
+// Обходит вложенные структуры, указатели, срезы и карты; исходное значение не меняется.
+func Mask[T any](value T) T {
+	masked := maskValue(reflect.ValueOf(&value).Elem(), "")
+	return masked.Interface().(T)
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+func maskValue(v reflect.Value, class string) reflect.Value {
+	switch v.Kind() {
+	case reflect.String:
+		out := reflect.New(v.Type()).Elem()
+		if class != "" {
+			out.SetString(MaskString(class, v.String()))
+		} else {
+			out.SetString(v.String())
+		}
+		return out
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			out.Field(i).Set(maskValue(v.Field(i), field.Tag.Get("pii")))
+		}
+		return out
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(maskValue(v.Elem(), class))
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		// JSON атрибутов маскируется как текст, чтобы ИИН внутри него тоже скрывался
+		if v.Type() == rawMessageType {
+			if class == "" {
+				return v
+			}
+			return reflect.ValueOf(json.RawMessage(MaskString(ClassText, string(v.Bytes()))))
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(maskValue(v.Index(i), class))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), maskValue(iter.Value(), class))
+		}
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(maskValue(v.Elem(), class))
+		return out
+	}
+	return v
+}
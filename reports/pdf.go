@@ -0,0 +1,142 @@
+package reports
+
+import (
+	"bytes"
+	_ "embed"
+	"github.com/jung-kurt/gofpdf"
+	"go-keycloak-jwt/models"
+	"io"
+)
+
+//go:embed fonts/DejaVuSansCondensed.ttf
+var fontRegular []byte
+
+//go:embed fonts/DejaVuSansCondensed-Bold.ttf
+var fontBold []byte
+
+const (
+	pdfFont      = "DejaVu"
+	pdfMargin    = 15.0
+	pdfLineH     = 7.0
+	pdfLabelW    = 60.0
+	pdfPageWidth = 210.0
+)
+
+// WritePDF формирует отчёт A4 в PDF
+func WritePDF(w io.Writer, tpl models.ReportTemplate, data Data) error {
+	tpl = withDefaults(tpl)
+	c := buildContent(tpl, data)
+	r, g, b, err := parseColor(tpl.BrandColor)
+	if err != nil {
+		return err
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes(pdfFont, "", fontRegular)
+	pdf.AddUTF8FontFromBytes(pdfFont, "B", fontBold)
+	pdf.SetTitle(fileTitle(data), true)
+	pdf.SetAuthor(tpl.Brand, true)
+	pdf.SetCreationDate(data.GeneratedAt)
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont(pdfFont, "", 8)
+		pdf.SetTextColor(110, 110, 110)
+		pdf.MultiCell(0, 4, c.footer, "", "L", false)
+	})
+	pdf.AddPage()
+	width := pdfPageWidth - 2*pdfMargin
+
+	// Шапка: полоса фирменного цвета с названием и логотипом
+	pdf.SetFillColor(r, g, b)
+	pdf.Rect(0, 0, pdfPageWidth, 28, "F")
+	if tpl.LogoPng != "" {
+		if logo, err := decodeLogo(tpl.LogoPng); err == nil {
+			pdf.RegisterImageOptionsReader("logo", gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(logo))
+			pdf.ImageOptions("logo", pdfPageWidth-pdfMargin-30, 5, 0, 18, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+		}
+	}
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetFont(pdfFont, "", 10)
+	pdf.SetXY(pdfMargin, 6)
+	pdf.CellFormat(width-35, 5, c.brand, "", 1, "L", false, 0, "")
+	pdf.SetFont(pdfFont, "B", 16)
+	pdf.SetX(pdfMargin)
+	pdf.CellFormat(width-35, 10, c.title, "", 1, "L", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetY(34)
+
+	section := func(title string) {
+		pdf.Ln(3)
+		pdf.SetFont(pdfFont, "B", 12)
+		pdf.SetTextColor(r, g, b)
+		pdf.CellFormat(width, pdfLineH+1, title, "B", 1, "L", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+		pdf.Ln(1)
+	}
+	fields := func(rows []field) {
+		for _, row := range rows {
+			pdf.SetFont(pdfFont, "B", 10)
+			pdf.CellFormat(pdfLabelW, pdfLineH, row.label, "", 0, "L", false, 0, "")
+			pdf.SetFont(pdfFont, "", 10)
+			pdf.MultiCell(width-pdfLabelW, pdfLineH, row.value, "", "L", false)
+		}
+	}
+	table := func(rows [][]string, widths []float64) {
+		for i, row := range rows {
+			header := i == 0
+			if header {
+				pdf.SetFont(pdfFont, "B", 10)
+				pdf.SetFillColor(r, g, b)
+				pdf.SetTextColor(255, 255, 255)
+			} else {
+				pdf.SetFont(pdfFont, "", 10)
+				pdf.SetTextColor(0, 0, 0)
+			}
+			for j, value := range row {
+				pdf.CellFormat(widths[j], pdfLineH, value, "1", 0, "L", header, 0, "")
+			}
+			pdf.Ln(-1)
+		}
+		pdf.SetTextColor(0, 0, 0)
+	}
+
+	section(tpl.Labels["subject_section"])
+	fields(c.subject)
+
+	section(tpl.Labels["result_section"])
+	comparison := make([][]string, len(c.comparison))
+	for i, row := range c.comparison {
+		comparison[i] = row[:]
+	}
+	table(comparison, []float64{width * 0.4, width * 0.3, width * 0.3})
+	if len(c.outcome) > 0 {
+		pdf.Ln(2)
+		fields(c.outcome)
+	}
+
+	if len(c.pdRanges) > 0 {
+		section(tpl.Labels["pd_ranges_section"])
+		ranges := make([][]string, len(c.pdRanges))
+		for i, row := range c.pdRanges {
+			ranges[i] = row[:]
+		}
+		table(ranges, []float64{width * 0.5, width * 0.2, width * 0.2, width * 0.1})
+	}
+
+	section(tpl.Labels["causes_section"])
+	if len(c.causes) == 0 {
+		pdf.SetFont(pdfFont, "", 10)
+		pdf.CellFormat(width, pdfLineH, tpl.Labels["no_causes"], "", 1, "L", false, 0, "")
+	}
+	for _, cause := range c.causes {
+		pdf.SetFont(pdfFont, "B", 10)
+		pdf.CellFormat(pdfLabelW/2, pdfLineH, cause[0], "", 0, "L", false, 0, "")
+		pdf.SetFont(pdfFont, "", 10)
+		pdf.MultiCell(width-pdfLabelW/2, pdfLineH, cause[1], "", "L", false)
+	}
+
+	return pdf.Output(w)
+}
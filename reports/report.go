@@ -0,0 +1,112 @@
+package reports
+
+import (
+	"fmt"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/pd"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Данные отчёта: запрос скоринга (уже замаскированный для пользователя, если нужно)
+// и кто и когда сформировал отчёт
+type Data struct {
+	Inquiry     models.ScoreInquiry
+	GeneratedBy string
+	GeneratedAt time.Time
+}
+
+// Строка «подпись — значение» в разделе отчёта
+type field struct {
+	label string
+	value string
+}
+
+// Содержимое отчёта, общее для PDF и XLSX
+type content struct {
+	title      string
+	brand      string
+	subject    []field
+	comparison [][3]string
+	outcome    []field
+	pdRanges   [][4]string
+	causes     [][2]string
+	footer     string
+}
+
+func buildContent(tpl models.ReportTemplate, data Data) content {
+	l := tpl.Labels
+	inquiry := data.Inquiry
+
+	c := content{
+		title: tpl.Title,
+		brand: tpl.Brand,
+		subject: []field{
+			{l["subject"], inquiry.SubjectIin},
+			{l["score_card"], inquiry.ScoreCard},
+			{l["inquiry_id"], strconv.FormatInt(inquiry.Id, 10)},
+			{l["id_query"], inquiry.IdQuery},
+			{l["inquired_at"], inquiry.InquiredAt.Format("02.01.2006 15:04:05")},
+			{l["officer"], inquiry.UserName},
+		},
+		comparison: [][3]string{
+			{l["indicator"], l["classic"], l["ml"]},
+			{l["score"], inquiry.Score, inquiry.ScoreByML},
+			{l["risk_grade"], inquiry.RiskGrade, inquiry.RiskGradeByML},
+			{l["pd"], inquiry.OneYearProbabilityOfDefault, inquiry.OneYearProbabilityOfDefaultByML},
+		},
+		footer: renderFooter(tpl, data),
+	}
+
+	if len(tpl.PdRanges) > 0 {
+		c.comparison = append(c.comparison, [3]string{
+			l["pd_range"],
+			pdRangeLabel(tpl.PdRanges, inquiry.OneYearProbabilityOfDefault),
+			pdRangeLabel(tpl.PdRanges, inquiry.OneYearProbabilityOfDefaultByML),
+		})
+		c.pdRanges = append(c.pdRanges, [4]string{l["range"], l["from"], l["to"], ""})
+		for _, r := range tpl.PdRanges {
+			mark := ""
+			if inRange(r, inquiry.OneYearProbabilityOfDefault) || inRange(r, inquiry.OneYearProbabilityOfDefaultByML) {
+				mark = "●"
+			}
+			c.pdRanges = append(c.pdRanges, [4]string{r.Label, formatNumber(r.From), formatNumber(r.To), mark})
+		}
+	}
+
+	if inquiry.Decision != "" {
+		c.outcome = append(c.outcome, field{l["decision"], inquiry.Decision}, field{l["decision_rule"], inquiry.DecisionRule})
+	}
+	if inquiry.ErrorCode != "" && inquiry.ErrorCode != "0" {
+		c.outcome = append(c.outcome, field{l["error"], strings.TrimSpace(inquiry.ErrorCode + " " + inquiry.ErrorString)})
+	}
+
+	for _, cause := range inquiry.Causes {
+		c.causes = append(c.causes, [2]string{cause.Name, cause.CauseText})
+	}
+	return c
+}
+
+func pdRangeLabel(ranges []models.ReportPdRange, value string) string {
+	for _, r := range ranges {
+		if inRange(r, value) {
+			return r.Label
+		}
+	}
+	return ""
+}
+
+// inRange — PD попадает в диапазон (From, To]; у диапазона бюро "2% - 3%" сравнивается верхняя граница (см. pd.Parse)
+func inRange(r models.ReportPdRange, value string) bool {
+	probability, err := pd.Parse(value)
+	return err == nil && probability > r.From && probability <= r.To
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func fileTitle(data Data) string {
+	return fmt.Sprintf("score-report-%d", data.Inquiry.Id)
+}
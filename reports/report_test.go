@@ -0,0 +1,37 @@
+package reports
+
+import (
+	"go-keycloak-jwt/models"
+	"testing"
+)
+
+func TestBuildContentPdRanges(t *testing.T) {
+	tpl := withDefaults(models.ReportTemplate{PdRanges: []models.ReportPdRange{
+		{Label: "Low", From: 0, To: 0.03},
+		{Label: "Medium", From: 0.03, To: 0.1},
+		{Label: "High", From: 0.1, To: 1},
+	}})
+	inquiry := models.ScoreInquiry{OneYearProbabilityOfDefault: "2% - 3%", OneYearProbabilityOfDefaultByML: "10% - 15%"}
+
+	c := buildContent(tpl, Data{Inquiry: inquiry})
+
+	row := c.comparison[len(c.comparison)-1]
+	if row[1] != "Low" || row[2] != "High" {
+		t.Errorf("PD range row = %q, want Low for 2%% - 3%% and High for 10%% - 15%%", row)
+	}
+	marked := map[string]bool{}
+	for _, r := range c.pdRanges[1:] {
+		marked[r[0]] = r[3] != ""
+	}
+	if !marked["Low"] || marked["Medium"] || !marked["High"] {
+		t.Errorf("marked PD ranges = %v, want Low and High", marked)
+	}
+}
+
+func TestValidateRejectsPercentPdRanges(t *testing.T) {
+	tpl := DefaultTemplate()
+	tpl.PdRanges = []models.ReportPdRange{{Label: "Low", From: 1, To: 3}}
+	if err := Validate(tpl); err == nil {
+		t.Error("Validate accepted a PD range given in percents")
+	}
+}
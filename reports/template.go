@@ -0,0 +1,117 @@
+// Package reports формирует печатный отчёт о результате скоринга в PDF и XLSX без внешних сервисов.
+package reports
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/base64"
+	"fmt"
+	"go-keycloak-jwt/models"
+	"gopkg.in/yaml.v3"
+	"image/png"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+//go:embed default_template.yaml
+var defaultTemplateYaml []byte
+
+// DefaultTemplate — оформление, которое используется, пока администратор не загрузил своё
+func DefaultTemplate() models.ReportTemplate {
+	var tpl models.ReportTemplate
+	if err := yaml.Unmarshal(defaultTemplateYaml, &tpl); err != nil {
+		panic(fmt.Sprintf("invalid default report template: %v", err))
+	}
+	return tpl
+}
+
+// Validate проверяет шаблон до сохранения: цвет, логотип, подвал и диапазоны PD
+func Validate(tpl models.ReportTemplate) error {
+	if strings.TrimSpace(tpl.Title) == "" {
+		return fmt.Errorf("title is required")
+	}
+	if tpl.BrandColor != "" {
+		if _, _, _, err := parseColor(tpl.BrandColor); err != nil {
+			return err
+		}
+	}
+	if tpl.LogoPng != "" {
+		if _, err := decodeLogo(tpl.LogoPng); err != nil {
+			return err
+		}
+	}
+	if _, err := template.New("footer").Parse(tpl.Footer); err != nil {
+		return fmt.Errorf("invalid footer: %v", err)
+	}
+	for _, r := range tpl.PdRanges {
+		if r.Label == "" || r.To <= r.From {
+			return fmt.Errorf("pd range %q: label is required and to must be greater than from", r.Label)
+		}
+		if r.From < 0 || r.To > 1 {
+			return fmt.Errorf("pd range %q: from and to are fractions from 0 to 1", r.Label)
+		}
+	}
+	return nil
+}
+
+// withDefaults дополняет шаблон подписями и цветом из шаблона по умолчанию
+func withDefaults(tpl models.ReportTemplate) models.ReportTemplate {
+	defaults := DefaultTemplate()
+	labels := make(map[string]string, len(defaults.Labels))
+	for key, value := range defaults.Labels {
+		labels[key] = value
+	}
+	for key, value := range tpl.Labels {
+		if value != "" {
+			labels[key] = value
+		}
+	}
+	tpl.Labels = labels
+	if tpl.BrandColor == "" {
+		tpl.BrandColor = defaults.BrandColor
+	}
+	return tpl
+}
+
+func parseColor(value string) (int, int, int, error) {
+	hex := strings.TrimPrefix(value, "#")
+	if len(hex) != 6 {
+		return 0, 0, 0, fmt.Errorf("brand_color must be #RRGGBB")
+	}
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("brand_color must be #RRGGBB")
+	}
+	return int(rgb >> 16 & 0xff), int(rgb >> 8 & 0xff), int(rgb & 0xff), nil
+}
+
+func decodeLogo(encoded string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("logo_png must be base64: %v", err)
+	}
+	if _, err := png.DecodeConfig(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("logo_png is not a PNG image: %v", err)
+	}
+	return data, nil
+}
+
+func renderFooter(tpl models.ReportTemplate, data Data) string {
+	parsed, err := template.New("footer").Parse(tpl.Footer)
+	if err != nil {
+		return ""
+	}
+	var out bytes.Buffer
+	err = parsed.Execute(&out, map[string]interface{}{
+		"Officer":     data.Inquiry.UserName,
+		"GeneratedBy": data.GeneratedBy,
+		"GeneratedAt": data.GeneratedAt.Format("02.01.2006 15:04"),
+		"InquiryId":   data.Inquiry.Id,
+		"IdQuery":     data.Inquiry.IdQuery,
+	})
+	if err != nil {
+		return ""
+	}
+	return out.String()
+}
@@ -0,0 +1,194 @@
+package reports
+
+import (
+	"github.com/xuri/excelize/v2"
+	"go-keycloak-jwt/models"
+	"io"
+	"strings"
+)
+
+// WriteXLSX формирует отчёт на одном листе: разделы друг под другом, как в PDF
+func WriteXLSX(w io.Writer, tpl models.ReportTemplate, data Data) error {
+	tpl = withDefaults(tpl)
+	c := buildContent(tpl, data)
+	color := strings.ToUpper(strings.TrimPrefix(tpl.BrandColor, "#"))
+
+	f := excelize.NewFile()
+	defer func() {
+		_ = f.Close()
+	}()
+	sheet := f.GetSheetName(0)
+
+	banner, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Size: 16, Color: "FFFFFF"},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{color}},
+	})
+	if err != nil {
+		return err
+	}
+	sectionStyle, err := f.NewStyle(&excelize.Style{
+		Font:   &excelize.Font{Bold: true, Size: 12, Color: color},
+		Border: []excelize.Border{{Type: "bottom", Color: color, Style: 1}},
+	})
+	if err != nil {
+		return err
+	}
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Color: "FFFFFF"},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{color}},
+	})
+	if err != nil {
+		return err
+	}
+	labelStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	footerStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Italic: true, Color: "6E6E6E"}})
+	if err != nil {
+		return err
+	}
+
+	row := 1
+	set := func(col int, value string, style int) error {
+		cell, err := excelize.CoordinatesToCellName(col, row)
+		if err != nil {
+			return err
+		}
+		if err := f.SetCellStr(sheet, cell, value); err != nil {
+			return err
+		}
+		if style != 0 {
+			return f.SetCellStyle(sheet, cell, cell, style)
+		}
+		return nil
+	}
+	section := func(title string) error {
+		row++
+		if err := set(1, title, sectionStyle); err != nil {
+			return err
+		}
+		row++
+		return nil
+	}
+	fields := func(items []field) error {
+		for _, item := range items {
+			if err := set(1, item.label, labelStyle); err != nil {
+				return err
+			}
+			if err := set(2, item.value, 0); err != nil {
+				return err
+			}
+			row++
+		}
+		return nil
+	}
+	table := func(rows [][]string) error {
+		for i, values := range rows {
+			style := 0
+			if i == 0 {
+				style = headerStyle
+			}
+			for j, value := range values {
+				if err := set(j+1, value, style); err != nil {
+					return err
+				}
+			}
+			row++
+		}
+		return nil
+	}
+
+	if err := set(1, c.brand, banner); err != nil {
+		return err
+	}
+	for col := 2; col <= 4; col++ {
+		if err := set(col, "", banner); err != nil {
+			return err
+		}
+	}
+	row++
+	if err := set(1, c.title, banner); err != nil {
+		return err
+	}
+	for col := 2; col <= 4; col++ {
+		if err := set(col, "", banner); err != nil {
+			return err
+		}
+	}
+	if tpl.LogoPng != "" {
+		if logo, err := decodeLogo(tpl.LogoPng); err == nil {
+			picture := &excelize.Picture{Extension: ".png", File: logo, Format: &excelize.GraphicOptions{ScaleX: 0.3, ScaleY: 0.3}}
+			if err := f.AddPictureFromBytes(sheet, "E1", picture); err != nil {
+				return err
+			}
+		}
+	}
+	row++
+
+	if err := section(tpl.Labels["subject_section"]); err != nil {
+		return err
+	}
+	if err := fields(c.subject); err != nil {
+		return err
+	}
+
+	if err := section(tpl.Labels["result_section"]); err != nil {
+		return err
+	}
+	comparison := make([][]string, len(c.comparison))
+	for i, values := range c.comparison {
+		comparison[i] = values[:]
+	}
+	if err := table(comparison); err != nil {
+		return err
+	}
+	if len(c.outcome) > 0 {
+		row++
+		if err := fields(c.outcome); err != nil {
+			return err
+		}
+	}
+
+	if len(c.pdRanges) > 0 {
+		if err := section(tpl.Labels["pd_ranges_section"]); err != nil {
+			return err
+		}
+		ranges := make([][]string, len(c.pdRanges))
+		for i, values := range c.pdRanges {
+			ranges[i] = values[:]
+		}
+		if err := table(ranges); err != nil {
+			return err
+		}
+	}
+
+	if err := section(tpl.Labels["causes_section"]); err != nil {
+		return err
+	}
+	causes := [][]string{{tpl.Labels["cause_name"], tpl.Labels["cause_text"]}}
+	for _, cause := range c.causes {
+		causes = append(causes, cause[:])
+	}
+	if len(c.causes) == 0 {
+		causes = append(causes, []string{tpl.Labels["no_causes"], ""})
+	}
+	if err := table(causes); err != nil {
+		return err
+	}
+
+	row++
+	if err := set(1, c.footer, footerStyle); err != nil {
+		return err
+	}
+
+	if err := f.SetColWidth(sheet, "A", "A", 34); err != nil {
+		return err
+	}
+	if err := f.SetColWidth(sheet, "B", "D", 28); err != nil {
+		return err
+	}
+	f.SetActiveSheet(0)
+
+	return f.Write(w)
+}
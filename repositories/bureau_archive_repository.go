@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"go-keycloak-jwt/db"
+	"go-keycloak-jwt/models"
+)
+
+// CreateBureauArchiveRecord сохраняет зашифрованный ответ; вызывается в транзакции вместе с запросом скоринга
+func CreateBureauArchiveRecord(ctx context.Context, q db.Querier, record *models.BureauArchiveRecord) error {
+	return q.QueryRow(ctx, `
+		INSERT INTO bureau_archive (inquiry_id, operation, status_code, key_id, wrapped_key, ciphertext, sha256)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at`,
+		record.InquiryId, record.Operation, record.StatusCode, record.KeyId, record.WrappedKey, record.Ciphertext,
+		record.Sha256,
+	).Scan(&record.Id, &record.CreatedAt)
+}
+
+func GetBureauArchiveRecordByInquiryId(ctx context.Context, inquiryId int64) (models.BureauArchiveRecord, error) {
+	var record models.BureauArchiveRecord
+	err := db.DB.QueryRow(ctx, `
+		SELECT id, inquiry_id, operation, status_code, key_id, wrapped_key, ciphertext, sha256, created_at
+		FROM bureau_archive WHERE inquiry_id=$1`, inquiryId,
+	).Scan(&record.Id, &record.InquiryId, &record.Operation, &record.StatusCode, &record.KeyId, &record.WrappedKey,
+		&record.Ciphertext, &record.Sha256, &record.CreatedAt)
+	return record, err
+}
+
+func CreateBureauArchiveAccess(ctx context.Context, access *models.BureauArchiveAccess) error {
+	return db.DB.QueryRow(ctx, `
+		INSERT INTO bureau_archive_access (archive_id, inquiry_id, user_id, user_name, reason, outcome, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, accessed_at`,
+		access.ArchiveId, access.InquiryId, access.UserId, access.UserName, access.Reason, access.Outcome, access.Error,
+	).Scan(&access.Id, &access.AccessedAt)
+}
+
+// FindBureauArchiveAccess возвращает журнал обращений к архиву; inquiryId = 0 — по всем запросам
+func FindBureauArchiveAccess(ctx context.Context, inquiryId int64, limit int) ([]models.BureauArchiveAccess, error) {
+	rows, err := db.DB.Query(ctx, `
+		SELECT id, archive_id, inquiry_id, user_id, user_name, reason, outcome, error, accessed_at
+		FROM bureau_archive_access WHERE $1 = 0 OR inquiry_id = $1
+		ORDER BY accessed_at DESC, id DESC LIMIT $2`, inquiryId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accesses := []models.BureauArchiveAccess{}
+	for rows.Next() {
+		var a models.BureauArchiveAccess
+		if err := rows.Scan(&a.Id, &a.ArchiveId, &a.InquiryId, &a.UserId, &a.UserName, &a.Reason,
+			&a.Outcome, &a.Error, &a.AccessedAt); err != nil {
+			return nil, err
+		}
+		accesses = append(accesses, a)
+	}
+	return accesses, rows.Err()
+}
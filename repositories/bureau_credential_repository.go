@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"go-keycloak-jwt/db"
+	"go-keycloak-jwt/models"
+)
+
+// Учётные данные бюро в том виде, как они лежат в базе: пароль зашифрован
+type EncryptedBureauCredential struct {
+	models.BureauCredential
+	PasswordEnc []byte
+}
+
+const bureauCredentialColumns = `id, subject_type, subject, user_name, password_enc, culture, version, updated_by, updated_at`
+
+// FindBureauCredentials возвращает записи, подходящие пользователю: его собственную,
+// записи его групп и запись по умолчанию. Выбор между ними делает вызывающий.
+func FindBureauCredentials(ctx context.Context, userId string, groups []string) ([]EncryptedBureauCredential, error) {
+	if groups == nil {
+		groups = []string{}
+	}
+	return queryBureauCredentials(ctx, `
+		SELECT `+bureauCredentialColumns+` FROM bureau_credentials
+		WHERE (subject_type = $1 AND subject = $2) OR (subject_type = $3 AND subject = ANY($4)) OR subject_type = $5`,
+		models.CredentialSubjectUser, userId, models.CredentialSubjectGroup, groups, models.CredentialSubjectDefault)
+}
+
+func ListBureauCredentials(ctx context.Context) ([]EncryptedBureauCredential, error) {
+	return queryBureauCredentials(ctx,
+		"SELECT "+bureauCredentialColumns+" FROM bureau_credentials ORDER BY subject_type, subject")
+}
+
+// UpsertBureauCredential создаёт запись или заменяет учётные данные существующей (ротация)
+func UpsertBureauCredential(ctx context.Context, credential *EncryptedBureauCredential) error {
+	return db.DB.QueryRow(ctx, `
+		INSERT INTO bureau_credentials (subject_type, subject, user_name, password_enc, culture, version, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (subject_type, subject) DO UPDATE SET user_name = EXCLUDED.user_name,
+			password_enc = EXCLUDED.password_enc, culture = EXCLUDED.culture, version = EXCLUDED.version,
+			updated_by = EXCLUDED.updated_by, updated_at = now()
+		RETURNING id, updated_at`,
+		credential.SubjectType, credential.Subject, credential.UserName, credential.PasswordEnc, credential.Culture,
+		credential.Version, credential.UpdatedBy,
+	).Scan(&credential.Id, &credential.UpdatedAt)
+}
+
+func DeleteBureauCredential(ctx context.Context, id int64) (bool, error) {
+	tag, err := db.DB.Exec(ctx, "DELETE FROM bureau_credentials WHERE id=$1", id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func queryBureauCredentials(ctx context.Context, query string, args ...interface{}) ([]EncryptedBureauCredential, error) {
+	rows, err := db.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var credentials []EncryptedBureauCredential
+	for rows.Next() {
+		var c EncryptedBureauCredential
+		err := rows.Scan(&c.Id, &c.SubjectType, &c.Subject, &c.UserName, &c.PasswordEnc, &c.Culture, &c.Version,
+			&c.UpdatedBy, &c.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		credentials = append(credentials, c)
+	}
+	return credentials, rows.Err()
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v4"
+	"go-keycloak-jwt/db"
+	"go-keycloak-jwt/models"
+)
+
+func CreateBureauTrafficRecord(ctx context.Context, record *models.BureauTrafficRecord) error {
+	return db.DB.QueryRow(ctx, `
+		INSERT INTO bureau_traffic (operation, match_key, attributes, request_xml, response_xml, status_code, headers,
+			recorded_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`,
+		record.Operation, record.MatchKey, record.Attributes, record.RequestXml, record.ResponseXml,
+		record.StatusCode, record.Headers, record.RecordedAt,
+	).Scan(&record.Id)
+}
+
+// GetLatestBureauTrafficRecord возвращает последнюю запись с данным ключом сопоставления
+func GetLatestBureauTrafficRecord(ctx context.Context, matchKey string) (models.BureauTrafficRecord, bool, error) {
+	var record models.BureauTrafficRecord
+	err := db.DB.QueryRow(ctx, `
+		SELECT id, operation, match_key, attributes, request_xml, response_xml, status_code, headers, recorded_at
+		FROM bureau_traffic WHERE match_key=$1 ORDER BY recorded_at DESC, id DESC LIMIT 1`, matchKey,
+	).Scan(&record.Id, &record.Operation, &record.MatchKey, &record.Attributes, &record.RequestXml,
+		&record.ResponseXml, &record.StatusCode, &record.Headers, &record.RecordedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.BureauTrafficRecord{}, false, nil
+	}
+	if err != nil {
+		return models.BureauTrafficRecord{}, false, err
+	}
+	return record, true, nil
+}
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+	"go-keycloak-jwt/db"
+	"go-keycloak-jwt/models"
+)
+
+const consentColumns = `id, subject_iin, scope, channel, granted_at, expires_at, document_ref, created_by, created_at,
+	revoked_at, revoked_by, revoke_reason`
+
+func CreateConsent(ctx context.Context, consent *models.Consent) error {
+	return db.DB.QueryRow(ctx, `
+		INSERT INTO consents (subject_iin, scope, channel, granted_at, expires_at, document_ref, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at`,
+		consent.SubjectIin, consent.Scope, consent.Channel, consent.GrantedAt, consent.ExpiresAt, consent.DocumentRef,
+		consent.CreatedBy,
+	).Scan(&consent.Id, &consent.CreatedAt)
+}
+
+func GetConsentById(ctx context.Context, id int64) (models.Consent, error) {
+	return scanConsent(db.DB.QueryRow(ctx, "SELECT "+consentColumns+" FROM consents WHERE id=$1", id))
+}
+
+// FindConsentsBySubject возвращает все согласия субъекта, новые сверху
+func FindConsentsBySubject(ctx context.Context, subjectIin string) ([]models.Consent, error) {
+	rows, err := db.DB.Query(ctx,
+		"SELECT "+consentColumns+" FROM consents WHERE subject_iin=$1 ORDER BY granted_at DESC, id DESC", subjectIin)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	consents := []models.Consent{}
+	for rows.Next() {
+		consent, err := scanConsent(rows)
+		if err != nil {
+			return nil, err
+		}
+		consents = append(consents, consent)
+	}
+	return consents, rows.Err()
+}
+
+// RevokeConsent отзывает согласие; повторный отзыв ничего не меняет
+func RevokeConsent(ctx context.Context, id int64, revokedBy string, reason string) (models.Consent, error) {
+	return scanConsent(db.DB.QueryRow(ctx, `
+		UPDATE consents SET revoked_at = COALESCE(revoked_at, now()),
+			revoked_by = CASE WHEN revoked_at IS NULL THEN $2 ELSE revoked_by END,
+			revoke_reason = CASE WHEN revoked_at IS NULL THEN $3 ELSE revoke_reason END
+		WHERE id=$1
+		RETURNING `+consentColumns, id, revokedBy, reason))
+}
+
+func scanConsent(row rowScanner) (models.Consent, error) {
+	var c models.Consent
+	err := row.Scan(&c.Id, &c.SubjectIin, &c.Scope, &c.Channel, &c.GrantedAt, &c.ExpiresAt, &c.DocumentRef,
+		&c.CreatedBy, &c.CreatedAt, &c.RevokedAt, &c.RevokedBy, &c.RevokeReason)
+	return c, err
+}
@@ -29,10 +29,10 @@ func GetAllCountries() (models.Countries, error) {
 	return countries, nil
 }
 
-func GetCountryById(reqId string) (models.Country, error) {
+func GetCountryById(ctx context.Context, reqId string) (models.Country, error) {
 	var id int
 	var name, code string
-	err := db.DB.QueryRow(context.Background(), "SELECT id, name, code FROM countries WHERE id=$1", reqId).Scan(&id, &name, &code)
+	err := db.DB.QueryRow(ctx, "SELECT id, name, code FROM countries WHERE id=$1", reqId).Scan(&id, &name, &code)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"go-keycloak-jwt/db"
+	"go-keycloak-jwt/models"
+)
+
+// Правила хранятся целиком в definition; версия, признак активности и автор — отдельными колонками
+type decisionRuleDefinition struct {
+	Rules   []models.DecisionRule `json:"rules"`
+	Default string                `json:"default"`
+}
+
+const decisionRuleSetColumns = `version, name, definition, active, created_by, created_at`
+
+// CreateDecisionRuleSet сохраняет правила новой версией (следующей за последней).
+// Таблица блокируется от параллельных вставок, иначе два сохранения получат одну версию.
+func CreateDecisionRuleSet(ctx context.Context, ruleSet *models.DecisionRuleSet) error {
+	definition, err := json.Marshal(decisionRuleDefinition{Rules: ruleSet.Rules, Default: ruleSet.Default})
+	if err != nil {
+		return fmt.Errorf("failed to marshal decision rules: %v", err)
+	}
+
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err := tx.Exec(ctx, "LOCK TABLE decision_rule_sets IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return fmt.Errorf("failed to lock decision rule sets: %v", err)
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO decision_rule_sets (version, name, definition, created_by)
+		SELECT COALESCE(MAX(version), 0) + 1, $1, $2, $3 FROM decision_rule_sets
+		RETURNING version, created_at`,
+		ruleSet.Name, definition, ruleSet.CreatedBy,
+	).Scan(&ruleSet.Version, &ruleSet.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func GetDecisionRuleSet(ctx context.Context, version int) (models.DecisionRuleSet, error) {
+	row := db.DB.QueryRow(ctx, "SELECT "+decisionRuleSetColumns+" FROM decision_rule_sets WHERE version=$1", version)
+	return scanDecisionRuleSet(row)
+}
+
+// GetActiveDecisionRuleSet возвращает активную версию; found = false, если ни одна не активна
+func GetActiveDecisionRuleSet(ctx context.Context) (models.DecisionRuleSet, bool, error) {
+	row := db.DB.QueryRow(ctx, "SELECT "+decisionRuleSetColumns+" FROM decision_rule_sets WHERE active")
+	ruleSet, err := scanDecisionRuleSet(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.DecisionRuleSet{}, false, nil
+	}
+	if err != nil {
+		return models.DecisionRuleSet{}, false, err
+	}
+	return ruleSet, true, nil
+}
+
+func ListDecisionRuleSets(ctx context.Context) ([]models.DecisionRuleSet, error) {
+	rows, err := db.DB.Query(ctx, "SELECT "+decisionRuleSetColumns+" FROM decision_rule_sets ORDER BY version DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ruleSets := []models.DecisionRuleSet{}
+	for rows.Next() {
+		ruleSet, err := scanDecisionRuleSet(rows)
+		if err != nil {
+			return nil, err
+		}
+		ruleSets = append(ruleSets, ruleSet)
+	}
+	return ruleSets, rows.Err()
+}
+
+// ActivateDecisionRuleSet делает версию активной, снимая признак с предыдущей
+func ActivateDecisionRuleSet(ctx context.Context, version int) error {
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err := tx.Exec(ctx, "UPDATE decision_rule_sets SET active = false WHERE active AND version <> $1", version); err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx, "UPDATE decision_rule_sets SET active = true WHERE version = $1", version)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return tx.Commit(ctx)
+}
+
+func scanDecisionRuleSet(row rowScanner) (models.DecisionRuleSet, error) {
+	var ruleSet models.DecisionRuleSet
+	var definition []byte
+	if err := row.Scan(&ruleSet.Version, &ruleSet.Name, &definition, &ruleSet.Active, &ruleSet.CreatedBy,
+		&ruleSet.CreatedAt); err != nil {
+		return models.DecisionRuleSet{}, err
+	}
+	var parsed decisionRuleDefinition
+	if err := json.Unmarshal(definition, &parsed); err != nil {
+		return models.DecisionRuleSet{}, fmt.Errorf("invalid decision rules version %d: %v", ruleSet.Version, err)
+	}
+	ruleSet.Rules = parsed.Rules
+	ruleSet.Default = parsed.Default
+	return ruleSet, nil
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v4"
+	"go-keycloak-jwt/db"
+	"go-keycloak-jwt/models"
+	"time"
+)
+
+// ReserveIdempotencyKey занимает ключ под новый запрос. Занятый ключ можно перезаписать, только если он истёк
+// или запрос под ним завис (не завершился за staleAfter). Если ключ занят, возвращает существующую запись.
+func ReserveIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord, staleAfter time.Duration) (bool, models.IdempotencyRecord, error) {
+	err := db.DB.QueryRow(ctx, `
+		INSERT INTO idempotency_keys (user_id, idempotency_key, method, path, request_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, idempotency_key) DO UPDATE SET method = EXCLUDED.method, path = EXCLUDED.path,
+			request_hash = EXCLUDED.request_hash, status_code = NULL, content_type = '', body = NULL, created_at = now(),
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= now()
+			OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at <= now() - $7 * interval '1 second')
+		RETURNING created_at`,
+		record.UserId, record.Key, record.Method, record.Path, record.RequestHash, record.ExpiresAt, int64(staleAfter.Seconds()),
+	).Scan(&record.CreatedAt)
+	if err == nil {
+		return true, models.IdempotencyRecord{}, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return false, models.IdempotencyRecord{}, err
+	}
+
+	var existing models.IdempotencyRecord
+	var statusCode *int
+	err = db.DB.QueryRow(ctx, `
+		SELECT user_id, idempotency_key, method, path, request_hash, status_code, content_type, body, created_at, expires_at
+		FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2`, record.UserId, record.Key,
+	).Scan(&existing.UserId, &existing.Key, &existing.Method, &existing.Path, &existing.RequestHash, &statusCode,
+		&existing.ContentType, &existing.Body, &existing.CreatedAt, &existing.ExpiresAt)
+	if err != nil {
+		return false, models.IdempotencyRecord{}, err
+	}
+	if statusCode != nil {
+		existing.StatusCode = *statusCode
+	}
+	return false, existing, nil
+}
+
+// CompleteIdempotencyKey сохраняет ответ, который будут получать повторы
+func CompleteIdempotencyKey(ctx context.Context, userId string, key string, statusCode int, contentType string, body []byte) error {
+	_, err := db.DB.Exec(ctx, `
+		UPDATE idempotency_keys SET status_code = $3, content_type = $4, body = $5
+		WHERE user_id = $1 AND idempotency_key = $2`, userId, key, statusCode, contentType, body)
+	return err
+}
+
+// ReleaseIdempotencyKey освобождает ключ, если запрос не выполнен и его можно повторить
+func ReleaseIdempotencyKey(ctx context.Context, userId string, key string) error {
+	_, err := db.DB.Exec(ctx, `
+		DELETE FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2 AND status_code IS NULL`, userId, key)
+	return err
+}
+
+func DeleteExpiredIdempotencyKeys(ctx context.Context) error {
+	_, err := db.DB.Exec(ctx, "DELETE FROM idempotency_keys WHERE expires_at <= now()")
+	return err
+}
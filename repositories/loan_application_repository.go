@@ -0,0 +1,233 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"go-keycloak-jwt/db"
+	"go-keycloak-jwt/models"
+	"strings"
+)
+
+const loanApplicationColumns = `a.id, a.applicant_iin, a.applicant_name, a.country_id, COALESCE(c.name, ''), COALESCE(c.code, ''),
+	a.amount, a.currency, a.term_months, a.status, a.decision, a.decision_comment, a.decided_by, a.user_id, a.user_name,
+	a.team, a.created_at, a.updated_at`
+
+const loanApplicationFrom = " FROM loan_applications a LEFT JOIN countries c ON c.id = a.country_id"
+
+// CreateLoanApplication сохраняет черновик заявки и первую запись истории статусов
+func CreateLoanApplication(ctx context.Context, application *models.LoanApplication) error {
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO loan_applications (applicant_iin, applicant_name, country_id, amount, currency, term_months, status,
+			user_id, user_name, team)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at`,
+		application.ApplicantIin, application.ApplicantName, application.CountryId, application.Amount,
+		application.Currency, application.TermMonths, application.Status, application.UserId, application.UserName,
+		application.Team,
+	).Scan(&application.Id, &application.CreatedAt, &application.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert loan application: %v", err)
+	}
+	if err := insertLoanStatusChange(ctx, tx, application.Id, models.LoanApplicationStatusChange{
+		ToStatus:  application.Status,
+		ChangedBy: application.UserName,
+	}); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit loan application: %v", err)
+	}
+	return nil
+}
+
+func GetLoanApplicationById(ctx context.Context, id int64) (models.LoanApplication, error) {
+	row := db.DB.QueryRow(ctx, "SELECT "+loanApplicationColumns+loanApplicationFrom+" WHERE a.id = $1", id)
+	return scanLoanApplication(row)
+}
+
+// FindLoanApplications — заявки по фильтру, новые первыми
+func FindLoanApplications(ctx context.Context, filter models.LoanApplicationFilter) ([]models.LoanApplication, error) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Status != "" {
+		add("a.status = $%d", filter.Status)
+	}
+	if filter.ApplicantIin != "" {
+		add("a.applicant_iin = $%d", filter.ApplicantIin)
+	}
+	if filter.UserId != "" {
+		add("a.user_id = $%d", filter.UserId)
+	}
+	if filter.Team != "" {
+		add("a.team = $%d", filter.Team)
+	}
+	limit := filter.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	query := "SELECT " + loanApplicationColumns + loanApplicationFrom
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY a.id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := db.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applications := []models.LoanApplication{}
+	for rows.Next() {
+		application, err := scanLoanApplication(rows)
+		if err != nil {
+			return nil, err
+		}
+		applications = append(applications, application)
+	}
+	return applications, rows.Err()
+}
+
+// UpdateLoanApplication меняет данные заявки, пока она черновик; updated = false, если заявка уже не черновик
+func UpdateLoanApplication(ctx context.Context, application models.LoanApplication) (bool, error) {
+	tag, err := db.DB.Exec(ctx, `
+		UPDATE loan_applications SET applicant_iin = $2, applicant_name = $3, country_id = $4, amount = $5,
+			currency = $6, term_months = $7, updated_at = now()
+		WHERE id = $1 AND status = $8`,
+		application.Id, application.ApplicantIin, application.ApplicantName, application.CountryId, application.Amount,
+		application.Currency, application.TermMonths, models.LoanStatusDraft)
+	if err != nil {
+		return false, fmt.Errorf("failed to update loan application: %v", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// DeleteLoanApplication удаляет черновик; deleted = false, если заявка уже не черновик
+func DeleteLoanApplication(ctx context.Context, id int64) (bool, error) {
+	tag, err := db.DB.Exec(ctx, "DELETE FROM loan_applications WHERE id = $1 AND status = $2", id, models.LoanStatusDraft)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// TransitionLoanApplication переводит заявку в change.ToStatus, если текущий статус среди allowed,
+// и пишет историю (повтор того же статуса в историю не попадает). Для решения вместе со статусом
+// сохраняются decision, комментарий и автор. changed = false, если текущий статус не подошёл.
+func TransitionLoanApplication(ctx context.Context, q db.Querier, id int64, allowed []string, change models.LoanApplicationStatusChange, decision string) (bool, error) {
+	var from string
+	err := q.QueryRow(ctx, "SELECT status FROM loan_applications WHERE id = $1 FOR UPDATE", id).Scan(&from)
+	if err != nil {
+		return false, err
+	}
+	permitted := false
+	for _, status := range allowed {
+		permitted = permitted || status == from
+	}
+	if !permitted {
+		return false, nil
+	}
+
+	if decision != "" {
+		_, err = q.Exec(ctx, `
+			UPDATE loan_applications SET status = $2, decision = $3, decision_comment = $4, decided_by = $5, updated_at = now()
+			WHERE id = $1`, id, change.ToStatus, decision, change.Comment, change.ChangedBy)
+	} else {
+		_, err = q.Exec(ctx, "UPDATE loan_applications SET status = $2, updated_at = now() WHERE id = $1", id, change.ToStatus)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to update loan application status: %v", err)
+	}
+	if from == change.ToStatus {
+		return true, nil
+	}
+	change.FromStatus = from
+	return true, insertLoanStatusChange(ctx, q, id, change)
+}
+
+func insertLoanStatusChange(ctx context.Context, q db.Querier, applicationId int64, change models.LoanApplicationStatusChange) error {
+	_, err := q.Exec(ctx, `
+		INSERT INTO loan_application_status_history (application_id, from_status, to_status, comment, changed_by)
+		VALUES ($1, $2, $3, $4, $5)`,
+		applicationId, change.FromStatus, change.ToStatus, change.Comment, change.ChangedBy)
+	if err != nil {
+		return fmt.Errorf("failed to insert loan application status change: %v", err)
+	}
+	return nil
+}
+
+func GetLoanApplicationHistory(ctx context.Context, applicationId int64) ([]models.LoanApplicationStatusChange, error) {
+	rows, err := db.DB.Query(ctx, `
+		SELECT id, from_status, to_status, comment, changed_by, changed_at
+		FROM loan_application_status_history WHERE application_id = $1 ORDER BY id`, applicationId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []models.LoanApplicationStatusChange{}
+	for rows.Next() {
+		var change models.LoanApplicationStatusChange
+		if err := rows.Scan(&change.Id, &change.FromStatus, &change.ToStatus, &change.Comment, &change.ChangedBy,
+			&change.ChangedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, change)
+	}
+	return history, rows.Err()
+}
+
+// LinkLoanApplicationScore привязывает запрос скоринга к заявке; повторная привязка ничего не меняет
+func LinkLoanApplicationScore(ctx context.Context, q db.Querier, applicationId int64, inquiryId int64, linkedBy string) error {
+	_, err := q.Exec(ctx, `
+		INSERT INTO loan_application_scores (application_id, inquiry_id, linked_by) VALUES ($1, $2, $3)
+		ON CONFLICT (application_id, inquiry_id) DO NOTHING`, applicationId, inquiryId, linkedBy)
+	if err != nil {
+		return fmt.Errorf("failed to link score inquiry: %v", err)
+	}
+	return nil
+}
+
+// GetLoanApplicationScores — привязанные запросы скоринга, последние первыми (без причин)
+func GetLoanApplicationScores(ctx context.Context, applicationId int64) ([]models.ScoreInquiry, error) {
+	rows, err := db.DB.Query(ctx, "SELECT "+scoreInquiryColumns+` FROM score_inquiries
+		JOIN loan_application_scores s ON s.inquiry_id = score_inquiries.id
+		WHERE s.application_id = $1 ORDER BY s.linked_at DESC, score_inquiries.id DESC`, applicationId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	inquiries := []models.ScoreInquiry{}
+	for rows.Next() {
+		inquiry, err := scanScoreInquiry(rows)
+		if err != nil {
+			return nil, err
+		}
+		inquiries = append(inquiries, inquiry)
+	}
+	return inquiries, rows.Err()
+}
+
+func scanLoanApplication(row rowScanner) (models.LoanApplication, error) {
+	var a models.LoanApplication
+	err := row.Scan(&a.Id, &a.ApplicantIin, &a.ApplicantName, &a.CountryId, &a.CountryName, &a.CountryCode, &a.Amount,
+		&a.Currency, &a.TermMonths, &a.Status, &a.Decision, &a.DecisionComment, &a.DecidedBy, &a.UserId, &a.UserName,
+		&a.Team, &a.CreatedAt, &a.UpdatedAt)
+	return a, err
+}
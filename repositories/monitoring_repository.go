@@ -0,0 +1,308 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"go-keycloak-jwt/db"
+	"go-keycloak-jwt/models"
+	"strings"
+	"time"
+)
+
+const watchlistColumns = `w.id, w.name, w.score_card, w.interval, w.alert_rules, w.active, w.team, w.created_by,
+	w.user_id, w.groups, w.roles, (SELECT count(*) FROM watchlist_members m WHERE m.watchlist_id = w.id), w.next_run_at, w.last_run_at, w.created_at`
+
+// CreateWatchlist сохраняет список наблюдения вместе с начальным составом
+func CreateWatchlist(ctx context.Context, watchlist *models.Watchlist, subjectIins []string) error {
+	rules, err := json.Marshal(watchlist.AlertRules)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert rules: %v", err)
+	}
+
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	groups, roles := watchlist.Groups, watchlist.Roles
+	if groups == nil {
+		groups = []string{}
+	}
+	if roles == nil {
+		roles = []string{}
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO watchlists (name, score_card, interval, alert_rules, active, team, created_by, user_id, groups, roles, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at`,
+		watchlist.Name, watchlist.ScoreCard, watchlist.Interval, rules, watchlist.Active, watchlist.Team,
+		watchlist.CreatedBy, watchlist.UserId, groups, roles, watchlist.NextRunAt,
+	).Scan(&watchlist.Id, &watchlist.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert watchlist: %w", err)
+	}
+	for _, subjectIin := range subjectIins {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO watchlist_members (watchlist_id, subject_iin) VALUES ($1, $2)
+			ON CONFLICT (watchlist_id, subject_iin) DO NOTHING`, watchlist.Id, subjectIin); err != nil {
+			return fmt.Errorf("failed to insert watchlist member: %v", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit watchlist: %v", err)
+	}
+	watchlist.Members = len(subjectIins)
+	return nil
+}
+
+// UpdateWatchlist меняет настройки списка; состав не трогает
+func UpdateWatchlist(ctx context.Context, watchlist models.Watchlist) (models.Watchlist, error) {
+	rules, err := json.Marshal(watchlist.AlertRules)
+	if err != nil {
+		return models.Watchlist{}, fmt.Errorf("failed to marshal alert rules: %v", err)
+	}
+	tag, err := db.DB.Exec(ctx, `
+		UPDATE watchlists SET name = $2, score_card = $3, interval = $4, alert_rules = $5, active = $6, next_run_at = $7
+		WHERE id = $1`,
+		watchlist.Id, watchlist.Name, watchlist.ScoreCard, watchlist.Interval, rules, watchlist.Active,
+		watchlist.NextRunAt)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.Watchlist{}, pgx.ErrNoRows
+	}
+	return GetWatchlistById(ctx, watchlist.Id)
+}
+
+func GetWatchlistById(ctx context.Context, id int64) (models.Watchlist, error) {
+	return scanWatchlist(db.DB.QueryRow(ctx, "SELECT "+watchlistColumns+" FROM watchlists w WHERE w.id=$1", id))
+}
+
+func ListWatchlists(ctx context.Context) ([]models.Watchlist, error) {
+	return queryWatchlists(ctx, "SELECT "+watchlistColumns+" FROM watchlists w ORDER BY w.name")
+}
+
+// FindDueWatchlists возвращает активные списки, которым пора перескоринг
+func FindDueWatchlists(ctx context.Context, now time.Time) ([]models.Watchlist, error) {
+	return queryWatchlists(ctx,
+		"SELECT "+watchlistColumns+" FROM watchlists w WHERE w.active AND w.next_run_at <= $1 ORDER BY w.next_run_at", now)
+}
+
+// ClaimWatchlistRun переносит следующий запуск списка, если его ещё не забрал другой экземпляр сервиса.
+// Возвращает false, если запуск уже забран (next_run_at изменился).
+func ClaimWatchlistRun(ctx context.Context, id int64, dueAt time.Time, nextRunAt time.Time) (bool, error) {
+	tag, err := db.DB.Exec(ctx, `
+		UPDATE watchlists SET next_run_at = $3, last_run_at = now() WHERE id = $1 AND next_run_at = $2 AND active`,
+		id, dueAt, nextRunAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ScheduleWatchlistNow ставит перескоринг списка на ближайший проход планировщика
+func ScheduleWatchlistNow(ctx context.Context, id int64) error {
+	tag, err := db.DB.Exec(ctx, "UPDATE watchlists SET next_run_at = now() WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func DeleteWatchlist(ctx context.Context, id int64) (bool, error) {
+	tag, err := db.DB.Exec(ctx, "DELETE FROM watchlists WHERE id = $1", id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func queryWatchlists(ctx context.Context, query string, args ...interface{}) ([]models.Watchlist, error) {
+	rows, err := db.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	watchlists := []models.Watchlist{}
+	for rows.Next() {
+		watchlist, err := scanWatchlist(rows)
+		if err != nil {
+			return nil, err
+		}
+		watchlists = append(watchlists, watchlist)
+	}
+	return watchlists, rows.Err()
+}
+
+func scanWatchlist(row rowScanner) (models.Watchlist, error) {
+	var w models.Watchlist
+	var rules []byte
+	if err := row.Scan(&w.Id, &w.Name, &w.ScoreCard, &w.Interval, &rules, &w.Active, &w.Team, &w.CreatedBy, &w.UserId, &w.Groups, &w.Roles, &w.Members,
+		&w.NextRunAt, &w.LastRunAt, &w.CreatedAt); err != nil {
+		return models.Watchlist{}, err
+	}
+	if err := json.Unmarshal(rules, &w.AlertRules); err != nil {
+		return models.Watchlist{}, fmt.Errorf("invalid alert rules of watchlist %d: %v", w.Id, err)
+	}
+	return w, nil
+}
+
+// AddWatchlistMembers добавляет субъектов в список; уже добавленные пропускаются. Возвращает число новых.
+func AddWatchlistMembers(ctx context.Context, watchlistId int64, subjectIins []string) (int, error) {
+	added := 0
+	for _, subjectIin := range subjectIins {
+		tag, err := db.DB.Exec(ctx, `
+			INSERT INTO watchlist_members (watchlist_id, subject_iin) VALUES ($1, $2)
+			ON CONFLICT (watchlist_id, subject_iin) DO NOTHING`, watchlistId, subjectIin)
+		if err != nil {
+			return added, err
+		}
+		added += int(tag.RowsAffected())
+	}
+	return added, nil
+}
+
+func RemoveWatchlistMember(ctx context.Context, watchlistId int64, subjectIin string) (bool, error) {
+	tag, err := db.DB.Exec(ctx,
+		"DELETE FROM watchlist_members WHERE watchlist_id = $1 AND subject_iin = $2", watchlistId, subjectIin)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func GetWatchlistMembers(ctx context.Context, watchlistId int64) ([]models.WatchlistMember, error) {
+	rows, err := db.DB.Query(ctx, `
+		SELECT id, watchlist_id, subject_iin, last_inquiry_id, last_scored_at, last_error, added_at
+		FROM watchlist_members WHERE watchlist_id = $1 ORDER BY id`, watchlistId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []models.WatchlistMember{}
+	for rows.Next() {
+		var m models.WatchlistMember
+		if err := rows.Scan(&m.Id, &m.WatchlistId, &m.SubjectIin, &m.LastInquiryId, &m.LastScoredAt, &m.LastError,
+			&m.AddedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// UpdateWatchlistMemberResult запоминает результат перескоринга: inquiryId — новый успешный запрос
+// (с ним будет сравниваться следующий), либо nil и текст ошибки
+// ResetWatchlistMemberResults забывает последние результаты участников: после смены карты
+// их нельзя сравнивать с результатами новой. UpdateWatchlistMemberResult не сбрасывает last_inquiry_id (COALESCE).
+func ResetWatchlistMemberResults(ctx context.Context, watchlistId int64) error {
+	_, err := db.DB.Exec(ctx, `
+		UPDATE watchlist_members SET last_inquiry_id = NULL, last_scored_at = NULL, last_error = ''
+		WHERE watchlist_id = $1`, watchlistId)
+	return err
+}
+
+func UpdateWatchlistMemberResult(ctx context.Context, memberId int64, inquiryId *int64, lastError string) error {
+	_, err := db.DB.Exec(ctx, `
+		UPDATE watchlist_members SET last_inquiry_id = COALESCE($2, last_inquiry_id), last_scored_at = now(),
+			last_error = $3
+		WHERE id = $1`, memberId, inquiryId, lastError)
+	return err
+}
+
+const monitoringAlertColumns = `a.id, a.watchlist_id, w.name, a.member_id, a.subject_iin, a.score_card, a.rule, a.field,
+	a.previous_value, a.current_value, a.message, a.previous_inquiry_id, a.inquiry_id, a.created_at, a.delivered_at,
+	a.delivery_attempts, a.delivery_error`
+
+func CreateMonitoringAlert(ctx context.Context, alert *models.MonitoringAlert) error {
+	return db.DB.QueryRow(ctx, `
+		INSERT INTO monitoring_alerts (watchlist_id, member_id, subject_iin, score_card, rule, field, previous_value,
+			current_value, message, previous_inquiry_id, inquiry_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at`,
+		alert.WatchlistId, alert.MemberId, alert.SubjectIin, alert.ScoreCard, alert.Rule, alert.Field,
+		alert.PreviousValue, alert.CurrentValue, alert.Message, alert.PreviousInquiryId, alert.InquiryId,
+	).Scan(&alert.Id, &alert.CreatedAt)
+}
+
+// FindMonitoringAlerts возвращает оповещения по фильтру, новые сверху
+func FindMonitoringAlerts(ctx context.Context, filter models.MonitoringAlertFilter) ([]models.MonitoringAlert, error) {
+	conditions := []string{"TRUE"}
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.WatchlistId != 0 {
+		add("a.watchlist_id = $%d", filter.WatchlistId)
+	}
+	if filter.SubjectIin != "" {
+		add("a.subject_iin = $%d", filter.SubjectIin)
+	}
+	if filter.Undelivered {
+		conditions = append(conditions, "a.delivered_at IS NULL")
+	}
+	if filter.From != nil {
+		add("a.created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("a.created_at < $%d", *filter.To)
+	}
+	args = append(args, filter.Limit)
+
+	return queryMonitoringAlerts(ctx, fmt.Sprintf(`
+		SELECT `+monitoringAlertColumns+`
+		FROM monitoring_alerts a JOIN watchlists w ON w.id = a.watchlist_id
+		WHERE %s
+		ORDER BY a.created_at DESC, a.id DESC LIMIT $%d`, strings.Join(conditions, " AND "), len(args)), args...)
+}
+
+// FindUndeliveredMonitoringAlerts — недоставленные оповещения, у которых остались попытки, старые первыми
+func FindUndeliveredMonitoringAlerts(ctx context.Context, maxAttempts int, limit int) ([]models.MonitoringAlert, error) {
+	return queryMonitoringAlerts(ctx, `
+		SELECT `+monitoringAlertColumns+`
+		FROM monitoring_alerts a JOIN watchlists w ON w.id = a.watchlist_id
+		WHERE a.delivered_at IS NULL AND a.delivery_attempts < $1
+		ORDER BY a.created_at, a.id LIMIT $2`, maxAttempts, limit)
+}
+
+// SetMonitoringAlertDelivery учитывает попытку доставки; пустая ошибка — оповещение доставлено
+func SetMonitoringAlertDelivery(ctx context.Context, id int64, deliveryError string) error {
+	_, err := db.DB.Exec(ctx, `
+		UPDATE monitoring_alerts SET delivery_attempts = delivery_attempts + 1, delivery_error = $2,
+			delivered_at = CASE WHEN $2 = '' THEN now() END
+		WHERE id = $1`, id, deliveryError)
+	return err
+}
+
+func queryMonitoringAlerts(ctx context.Context, query string, args ...interface{}) ([]models.MonitoringAlert, error) {
+	rows, err := db.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := []models.MonitoringAlert{}
+	for rows.Next() {
+		var a models.MonitoringAlert
+		if err := rows.Scan(&a.Id, &a.WatchlistId, &a.WatchlistName, &a.MemberId, &a.SubjectIin, &a.ScoreCard, &a.Rule,
+			&a.Field, &a.PreviousValue, &a.CurrentValue, &a.Message, &a.PreviousInquiryId, &a.InquiryId, &a.CreatedAt,
+			&a.DeliveredAt, &a.DeliveryAttempts, &a.DeliveryError); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, rows.Err()
+}
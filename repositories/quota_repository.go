@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"go-keycloak-jwt/db"
+	"go-keycloak-jwt/models"
+	"strings"
+	"time"
+)
+
+// Платный запрос — ответ провайдера, полученный не из кэша. Ошибки транспорта (код -1) не оплачиваются.
+const billableScoreInquiry = `cached_from_id IS NULL AND error_code <> '-1'`
+
+const scoreQuotaColumns = `id, scope_type, scope, score_card, period, quota_limit, updated_by, updated_at`
+
+func ListScoreQuotas(ctx context.Context) ([]models.ScoreQuota, error) {
+	rows, err := db.DB.Query(ctx, "SELECT "+scoreQuotaColumns+" FROM score_quotas ORDER BY scope_type, scope, score_card, period")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	quotas := []models.ScoreQuota{}
+	for rows.Next() {
+		quota, err := scanScoreQuota(rows)
+		if err != nil {
+			return nil, err
+		}
+		quotas = append(quotas, quota)
+	}
+	return quotas, rows.Err()
+}
+
+// UpsertScoreQuota создаёт квоту или меняет лимит существующей с теми же областью, картой и периодом
+func UpsertScoreQuota(ctx context.Context, quota models.ScoreQuota) (models.ScoreQuota, error) {
+	return scanScoreQuota(db.DB.QueryRow(ctx, `
+		INSERT INTO score_quotas (scope_type, scope, score_card, period, quota_limit, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (scope_type, scope, score_card, period) DO UPDATE SET quota_limit = EXCLUDED.quota_limit,
+			updated_by = EXCLUDED.updated_by, updated_at = now()
+		RETURNING `+scoreQuotaColumns,
+		quota.ScopeType, quota.Scope, quota.ScoreCard, quota.Period, quota.Limit, quota.UpdatedBy))
+}
+
+func DeleteScoreQuota(ctx context.Context, id int64) (bool, error) {
+	tag, err := db.DB.Exec(ctx, "DELETE FROM score_quotas WHERE id = $1", id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func scanScoreQuota(row rowScanner) (models.ScoreQuota, error) {
+	var q models.ScoreQuota
+	err := row.Scan(&q.Id, &q.ScopeType, &q.Scope, &q.ScoreCard, &q.Period, &q.Limit, &q.UpdatedBy, &q.UpdatedAt)
+	return q, err
+}
+
+// CountBillableScoreCalls считает платные запросы пользователя или команды с момента since;
+// scoreCard "*" — по всем картам
+func CountBillableScoreCalls(ctx context.Context, scopeType string, subject string, scoreCard string, since time.Time) (int64, error) {
+	column := "user_id"
+	if scopeType == models.QuotaScopeTeam {
+		column = "team"
+	}
+	var count int64
+	err := db.DB.QueryRow(ctx, `
+		SELECT count(*) FROM score_inquiries
+		WHERE `+column+` = $1 AND created_at >= $2 AND ($3 = '*' OR score_card = $3) AND `+billableScoreInquiry,
+		subject, since, scoreCard,
+	).Scan(&count)
+	return count, err
+}
+
+// GetScoreUsage — число платных, кэшированных и неуспешных запросов по команде, пользователю, карте и провайдеру
+func GetScoreUsage(ctx context.Context, filter models.ScoreUsageFilter) ([]models.ScoreUsageRow, error) {
+	conditions := []string{"TRUE"}
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Team != "" {
+		add("team = $%d", filter.Team)
+	}
+	if filter.UserId != "" {
+		add("user_id = $%d", filter.UserId)
+	}
+	if filter.ScoreCard != "" {
+		add("score_card = $%d", filter.ScoreCard)
+	}
+	if filter.From != nil {
+		add("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("created_at < $%d", *filter.To)
+	}
+
+	rows, err := db.DB.Query(ctx, `
+		SELECT team, user_id, max(user_name), score_card, provider,
+			count(*) FILTER (WHERE `+billableScoreInquiry+`),
+			count(*) FILTER (WHERE cached_from_id IS NOT NULL),
+			count(*) FILTER (WHERE cached_from_id IS NULL AND error_code = '-1')
+		FROM score_inquiries
+		WHERE `+strings.Join(conditions, " AND ")+`
+		GROUP BY team, user_id, score_card, provider
+		ORDER BY team, user_id, score_card, provider`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := []models.ScoreUsageRow{}
+	for rows.Next() {
+		var u models.ScoreUsageRow
+		if err := rows.Scan(&u.Team, &u.UserId, &u.UserName, &u.ScoreCard, &u.Provider, &u.BillableCalls,
+			&u.CachedCalls, &u.FailedCalls); err != nil {
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
+
+// GetScoreBilling — платные запросы за период по департаменту (команде), карте и провайдеру
+func GetScoreBilling(ctx context.Context, from time.Time, to time.Time) ([]models.ScoreBillingRow, error) {
+	rows, err := db.DB.Query(ctx, `
+		SELECT team, score_card, provider, count(*)
+		FROM score_inquiries
+		WHERE created_at >= $1 AND created_at < $2 AND `+billableScoreInquiry+`
+		GROUP BY team, score_card, provider
+		ORDER BY team, score_card, provider`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	billing := []models.ScoreBillingRow{}
+	for rows.Next() {
+		var b models.ScoreBillingRow
+		if err := rows.Scan(&b.Team, &b.ScoreCard, &b.Provider, &b.Calls); err != nil {
+			return nil, err
+		}
+		billing = append(billing, b)
+	}
+	return billing, rows.Err()
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-keycloak-jwt/db"
+	"go-keycloak-jwt/models"
+)
+
+const reasonCodeColumns = "code, severity, customer_text, internal_explanation, updated_by, updated_at"
+
+func ListReasonCodes(ctx context.Context) ([]models.ReasonCode, error) {
+	rows, err := db.DB.Query(ctx, "SELECT "+reasonCodeColumns+" FROM score_reason_codes ORDER BY code")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	codes := []models.ReasonCode{}
+	for rows.Next() {
+		code, err := scanReasonCode(rows)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, rows.Err()
+}
+
+// FindReasonCodes возвращает коды из каталога по списку; отсутствующих кодов в результате нет
+func FindReasonCodes(ctx context.Context, codes []string) (map[string]models.ReasonCode, error) {
+	rows, err := db.DB.Query(ctx, "SELECT "+reasonCodeColumns+" FROM score_reason_codes WHERE code = ANY($1)", codes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := map[string]models.ReasonCode{}
+	for rows.Next() {
+		code, err := scanReasonCode(rows)
+		if err != nil {
+			return nil, err
+		}
+		found[code.Code] = code
+	}
+	return found, rows.Err()
+}
+
+func UpsertReasonCode(ctx context.Context, code models.ReasonCode) (models.ReasonCode, error) {
+	text, err := json.Marshal(code.CustomerText)
+	if err != nil {
+		return models.ReasonCode{}, err
+	}
+	row := db.DB.QueryRow(ctx, `
+		INSERT INTO score_reason_codes (code, severity, customer_text, internal_explanation, updated_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (code) DO UPDATE SET severity = EXCLUDED.severity, customer_text = EXCLUDED.customer_text,
+			internal_explanation = EXCLUDED.internal_explanation, updated_by = EXCLUDED.updated_by, updated_at = now()
+		RETURNING `+reasonCodeColumns,
+		code.Code, code.Severity, text, code.InternalExplanation, code.UpdatedBy)
+	saved, err := scanReasonCode(row)
+	if err != nil {
+		return models.ReasonCode{}, fmt.Errorf("failed to save reason code: %v", err)
+	}
+	return saved, nil
+}
+
+func DeleteReasonCode(ctx context.Context, code string) (bool, error) {
+	tag, err := db.DB.Exec(ctx, "DELETE FROM score_reason_codes WHERE code = $1", code)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func scanReasonCode(row rowScanner) (models.ReasonCode, error) {
+	var code models.ReasonCode
+	var text []byte
+	if err := row.Scan(&code.Code, &code.Severity, &text, &code.InternalExplanation, &code.UpdatedBy, &code.UpdatedAt); err != nil {
+		return models.ReasonCode{}, err
+	}
+	if err := json.Unmarshal(text, &code.CustomerText); err != nil {
+		return models.ReasonCode{}, err
+	}
+	return code, nil
+}
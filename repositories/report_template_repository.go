@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"go-keycloak-jwt/db"
+	"go-keycloak-jwt/models"
+	"time"
+)
+
+// GetReportTemplate возвращает шаблон, загруженный администратором; found = false, если его нет
+func GetReportTemplate(ctx context.Context, name string) (models.ReportTemplate, bool, error) {
+	var definition []byte
+	var tpl models.ReportTemplate
+	var updatedAt time.Time
+	err := db.DB.QueryRow(ctx, "SELECT definition, updated_by, updated_at FROM report_templates WHERE name=$1", name).
+		Scan(&definition, &tpl.UpdatedBy, &updatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ReportTemplate{}, false, nil
+	}
+	if err != nil {
+		return models.ReportTemplate{}, false, err
+	}
+
+	updatedBy := tpl.UpdatedBy
+	if err := json.Unmarshal(definition, &tpl); err != nil {
+		return models.ReportTemplate{}, false, fmt.Errorf("invalid report template %s: %v", name, err)
+	}
+	tpl.UpdatedBy = updatedBy
+	tpl.UpdatedAt = &updatedAt
+	return tpl, true, nil
+}
+
+func SaveReportTemplate(ctx context.Context, name string, tpl *models.ReportTemplate) error {
+	definition := *tpl
+	definition.UpdatedBy = ""
+	definition.UpdatedAt = nil
+	data, err := json.Marshal(definition)
+	if err != nil {
+		return fmt.Errorf("failed to marshal report template: %v", err)
+	}
+
+	var updatedAt time.Time
+	err = db.DB.QueryRow(ctx, `
+		INSERT INTO report_templates (name, definition, updated_by) VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET definition = EXCLUDED.definition, updated_by = EXCLUDED.updated_by,
+			updated_at = now()
+		RETURNING updated_at`, name, data, tpl.UpdatedBy,
+	).Scan(&updatedAt)
+	if err != nil {
+		return err
+	}
+	tpl.UpdatedAt = &updatedAt
+	return nil
+}
+
+func DeleteReportTemplate(ctx context.Context, name string) error {
+	_, err := db.DB.Exec(ctx, "DELETE FROM report_templates WHERE name=$1", name)
+	return err
+}
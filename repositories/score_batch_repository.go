@@ -0,0 +1,175 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"go-keycloak-jwt/db"
+	"go-keycloak-jwt/models"
+)
+
+const scoreBatchColumns = `id, user_id, user_name, team, groups, roles, score_card, attribute_name, file_name, status,
+	total, processed, failed, created_at, finished_at`
+
+// CreateScoreBatch сохраняет задание и все его строки в одной транзакции
+func CreateScoreBatch(ctx context.Context, batch *models.ScoreBatch, subjects []string) error {
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	groups, roles := batch.Groups, batch.Roles
+	if groups == nil {
+		groups = []string{}
+	}
+	if roles == nil {
+		roles = []string{}
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO score_batches (user_id, user_name, team, groups, roles, score_card, attribute_name, file_name, status, total)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at`,
+		batch.UserId, batch.UserName, batch.Team, groups, roles, batch.ScoreCard,
+		batch.AttributeName, batch.FileName, batch.Status, len(subjects),
+	).Scan(&batch.Id, &batch.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert score batch: %v", err)
+	}
+	batch.Total = len(subjects)
+
+	rows := make([][]interface{}, 0, len(subjects))
+	for i, subject := range subjects {
+		rows = append(rows, []interface{}{batch.Id, i + 1, subject, models.ScoreBatchItemPending})
+	}
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"score_batch_items"},
+		[]string{"batch_id", "row_number", "subject", "status"}, pgx.CopyFromRows(rows))
+	if err != nil {
+		return fmt.Errorf("failed to insert score batch items: %v", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit score batch: %v", err)
+	}
+	return nil
+}
+
+func GetScoreBatchById(ctx context.Context, id int64) (models.ScoreBatch, error) {
+	var batch models.ScoreBatch
+	err := db.DB.QueryRow(ctx, "SELECT "+scoreBatchColumns+" FROM score_batches WHERE id=$1", id).Scan(
+		&batch.Id, &batch.UserId, &batch.UserName, &batch.Team, &batch.Groups, &batch.Roles, &batch.ScoreCard, &batch.AttributeName,
+		&batch.FileName, &batch.Status, &batch.Total, &batch.Processed, &batch.Failed, &batch.CreatedAt,
+		&batch.FinishedAt)
+	return batch, err
+}
+
+// GetUnfinishedScoreBatchIds возвращает задания, которые нужно (до)обработать,
+// например после перезапуска сервиса
+func GetUnfinishedScoreBatchIds(ctx context.Context) ([]int64, error) {
+	rows, err := db.DB.Query(ctx, "SELECT id FROM score_batches WHERE status <> $1 ORDER BY id",
+		models.ScoreBatchFinished)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func GetPendingScoreBatchItems(ctx context.Context, batchId int64) ([]models.ScoreBatchItem, error) {
+	rows, err := db.DB.Query(ctx, `
+		SELECT id, batch_id, row_number, subject, status, inquiry_id, error
+		FROM score_batch_items WHERE batch_id=$1 AND status=$2 ORDER BY row_number`,
+		batchId, models.ScoreBatchItemPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.ScoreBatchItem
+	for rows.Next() {
+		var item models.ScoreBatchItem
+		err := rows.Scan(&item.Id, &item.BatchId, &item.RowNumber, &item.Subject, &item.Status, &item.InquiryId,
+			&item.Error)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func SetScoreBatchStatus(ctx context.Context, id int64, status string) error {
+	_, err := db.DB.Exec(ctx, "UPDATE score_batches SET status=$2 WHERE id=$1", id, status)
+	return err
+}
+
+func FinishScoreBatch(ctx context.Context, id int64) error {
+	_, err := db.DB.Exec(ctx, "UPDATE score_batches SET status=$2, finished_at=now() WHERE id=$1",
+		id, models.ScoreBatchFinished)
+	return err
+}
+
+// SetScoreBatchItemInquiry привязывает запрос к строке сразу после скоринга, до её завершения:
+// после перезапуска строка с запросом не скорится (и не оплачивается) повторно
+func SetScoreBatchItemInquiry(ctx context.Context, itemId int64, inquiryId int64) error {
+	_, err := db.DB.Exec(ctx, "UPDATE score_batch_items SET inquiry_id=$2 WHERE id=$1", itemId, inquiryId)
+	return err
+}
+
+// CompleteScoreBatchItem отмечает строку обработанной и атомарно обновляет счётчики задания
+func CompleteScoreBatchItem(ctx context.Context, item models.ScoreBatchItem) error {
+	failed := 0
+	if item.Status == models.ScoreBatchItemFailed {
+		failed = 1
+	}
+	_, err := db.DB.Exec(ctx, `
+		WITH item AS (
+			UPDATE score_batch_items SET status=$2, inquiry_id=$3, error=$4
+			WHERE id=$1 AND status=$5
+			RETURNING batch_id
+		)
+		UPDATE score_batches SET processed = processed + 1, failed = failed + $6
+		WHERE id = (SELECT batch_id FROM item)`,
+		item.Id, item.Status, item.InquiryId, item.Error, models.ScoreBatchItemPending, failed)
+	return err
+}
+
+func GetScoreBatchResults(ctx context.Context, batchId int64) ([]models.ScoreBatchResultRow, error) {
+	rows, err := db.DB.Query(ctx, `
+		SELECT i.row_number, i.subject, i.status, i.inquiry_id, i.error,
+			COALESCE(s.id_query, ''), COALESCE(s.score, ''), COALESCE(s.one_year_probability_of_default, ''),
+			COALESCE(s.risk_grade, ''), COALESCE(s.score_by_ml, ''),
+			COALESCE(s.one_year_probability_of_default_by_ml, ''), COALESCE(s.risk_grade_by_ml, '')
+		FROM score_batch_items i
+		LEFT JOIN score_inquiries s ON s.id = i.inquiry_id
+		WHERE i.batch_id=$1
+		ORDER BY i.row_number`, batchId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []models.ScoreBatchResultRow
+	for rows.Next() {
+		var r models.ScoreBatchResultRow
+		err := rows.Scan(&r.RowNumber, &r.Subject, &r.Status, &r.InquiryId, &r.Error, &r.IdQuery, &r.Score,
+			&r.OneYearProbabilityOfDefault, &r.RiskGrade, &r.ScoreByML, &r.OneYearProbabilityOfDefaultByML,
+			&r.RiskGradeByML)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
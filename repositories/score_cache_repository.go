@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v4"
+	"go-keycloak-jwt/db"
+	"go-keycloak-jwt/models"
+	"time"
+)
+
+// GetScoreCacheEntry возвращает непросроченную запись кэша по ключу
+func GetScoreCacheEntry(ctx context.Context, key string) (models.ScoreCacheEntry, bool, error) {
+	var entry models.ScoreCacheEntry
+	err := db.DB.QueryRow(ctx,
+		"SELECT response, inquiry_id, inquired_at FROM score_cache WHERE cache_key=$1 AND expires_at > now()", key,
+	).Scan(&entry.Result, &entry.InquiryId, &entry.InquiredAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ScoreCacheEntry{}, false, nil
+	}
+	if err != nil {
+		return models.ScoreCacheEntry{}, false, err
+	}
+	return entry, true, nil
+}
+
+func UpsertScoreCacheEntry(ctx context.Context, key string, scoreCard string, entry models.ScoreCacheEntry, expiresAt time.Time) error {
+	_, err := db.DB.Exec(ctx, `
+		INSERT INTO score_cache (cache_key, score_card, response, inquiry_id, inquired_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (cache_key) DO UPDATE SET score_card = EXCLUDED.score_card, response = EXCLUDED.response,
+			inquiry_id = EXCLUDED.inquiry_id, inquired_at = EXCLUDED.inquired_at, expires_at = EXCLUDED.expires_at`,
+		key, scoreCard, entry.Result, entry.InquiryId, entry.InquiredAt, expiresAt)
+	return err
+}
+
+func DeleteExpiredScoreCacheEntries(ctx context.Context) error {
+	_, err := db.DB.Exec(ctx, "DELETE FROM score_cache WHERE expires_at <= now()")
+	return err
+}
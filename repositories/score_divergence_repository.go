@@ -0,0 +1,168 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"go-keycloak-jwt/db"
+	"go-keycloak-jwt/models"
+	"strings"
+	"time"
+)
+
+// scoreDivergenceBase — успешные запросы, реально ушедшие к провайдеру. Повторы из кэша не учитываются,
+// чтобы один ответ не считался несколько раз. Классы риска переводятся в позиции на шкале filter.RiskGrades;
+// запрос без одного из классов (или с классом не со шкалы) остаётся в выборке как несравнимый.
+func scoreDivergenceBase(filter models.ScoreDivergenceFilter) (string, []interface{}) {
+	conditions := []string{"error_code = '0'", "cached_from_id IS NULL"}
+	args := []interface{}{filter.RiskGrades}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.ScoreCard != "" {
+		add("score_card = $%d", filter.ScoreCard)
+	}
+	if filter.Provider != "" {
+		add("provider = $%d", filter.Provider)
+	}
+	if filter.From != nil {
+		add("inquired_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("inquired_at < $%d", *filter.To)
+	}
+	mismatchesOnly := ""
+	if filter.MismatchesOnly {
+		mismatchesOnly = "WHERE grade_index_by_ml <> grade_index"
+	}
+
+	return `
+		WITH graded AS (
+			SELECT id, score_card, provider, subject_iin, inquired_at, score, risk_grade, score_by_ml, risk_grade_by_ml,
+				array_position($1::text[], upper(trim(risk_grade))) AS grade_index,
+				array_position($1::text[], upper(trim(risk_grade_by_ml))) AS grade_index_by_ml
+			FROM score_inquiries
+			WHERE ` + strings.Join(conditions, " AND ") + `
+		), base AS (
+			SELECT *,
+				grade_index IS NOT NULL AND grade_index_by_ml IS NOT NULL AS comparable,
+				COALESCE(grade_index_by_ml <> grade_index, false) AS mismatch,
+				grade_index_by_ml - grade_index AS notches
+			FROM graded ` + mismatchesOnly + `
+		)`, args
+}
+
+const scoreDivergenceStatsColumns = `count(*), count(*) FILTER (WHERE comparable), count(*) FILTER (WHERE mismatch),
+	avg(notches)::float8, avg(abs(notches))::float8`
+
+// GetScoreDivergenceByScoreCard возвращает сводку по каждой карте и общую (с пустой картой) последней строкой
+func GetScoreDivergenceByScoreCard(ctx context.Context, filter models.ScoreDivergenceFilter) ([]models.ScoreDivergenceStats, models.ScoreDivergenceStats, error) {
+	base, args := scoreDivergenceBase(filter)
+	rows, err := db.DB.Query(ctx, base+`
+		SELECT GROUPING(score_card) = 1, coalesce(score_card, ''), `+scoreDivergenceStatsColumns+`
+		FROM base
+		GROUP BY GROUPING SETS ((score_card), ())
+		ORDER BY GROUPING(score_card), score_card`, args...)
+	if err != nil {
+		return nil, models.ScoreDivergenceStats{}, err
+	}
+	defer rows.Close()
+
+	byCard := []models.ScoreDivergenceStats{}
+	var summary models.ScoreDivergenceStats
+	for rows.Next() {
+		var total bool
+		var s models.ScoreDivergenceStats
+		if err := rows.Scan(&total, &s.ScoreCard, &s.Total, &s.Comparable, &s.GradeMismatches, &s.GradeNotchDeltaMean,
+			&s.GradeNotchDeltaMeanAbs); err != nil {
+			return nil, models.ScoreDivergenceStats{}, err
+		}
+		if total {
+			summary = s
+			continue
+		}
+		byCard = append(byCard, s)
+	}
+	return byCard, summary, rows.Err()
+}
+
+// GetScoreDivergenceByPeriod — динамика расхождений; interval проверяется сервисом (day, week, month)
+func GetScoreDivergenceByPeriod(ctx context.Context, filter models.ScoreDivergenceFilter) ([]models.ScoreDivergenceStats, error) {
+	base, args := scoreDivergenceBase(filter)
+	args = append(args, filter.Interval)
+	rows, err := db.DB.Query(ctx, base+fmt.Sprintf(`
+		SELECT date_trunc($%d, inquired_at) AS period, `+scoreDivergenceStatsColumns+`
+		FROM base
+		GROUP BY period
+		ORDER BY period`, len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	periods := []models.ScoreDivergenceStats{}
+	for rows.Next() {
+		var period time.Time
+		var s models.ScoreDivergenceStats
+		if err := rows.Scan(&period, &s.Total, &s.Comparable, &s.GradeMismatches, &s.GradeNotchDeltaMean,
+			&s.GradeNotchDeltaMeanAbs); err != nil {
+			return nil, err
+		}
+		s.Period = &period
+		periods = append(periods, s)
+	}
+	return periods, rows.Err()
+}
+
+// GetScoreGradePairs — распределение пар классов риска (классический, ML) по картам
+func GetScoreGradePairs(ctx context.Context, filter models.ScoreDivergenceFilter) ([]models.ScoreGradePair, error) {
+	base, args := scoreDivergenceBase(filter)
+	rows, err := db.DB.Query(ctx, base+`
+		SELECT score_card, risk_grade, risk_grade_by_ml, count(*)
+		FROM base
+		GROUP BY score_card, risk_grade, risk_grade_by_ml
+		ORDER BY score_card, risk_grade, risk_grade_by_ml`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pairs := []models.ScoreGradePair{}
+	for rows.Next() {
+		var p models.ScoreGradePair
+		if err := rows.Scan(&p.ScoreCard, &p.RiskGrade, &p.RiskGradeByML, &p.Count); err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, p)
+	}
+	return pairs, rows.Err()
+}
+
+// FindScoreDivergenceCases возвращает запросы по убыванию расхождения — модуля разницы позиций классов риска;
+// несравнимые идут последними
+func FindScoreDivergenceCases(ctx context.Context, filter models.ScoreDivergenceFilter, limit int) ([]models.ScoreDivergenceCase, error) {
+	base, args := scoreDivergenceBase(filter)
+	args = append(args, limit)
+	rows, err := db.DB.Query(ctx, base+fmt.Sprintf(`
+		SELECT id, score_card, provider, subject_iin, score, risk_grade, score_by_ml, risk_grade_by_ml, comparable,
+			mismatch, notches, inquired_at
+		FROM base
+		ORDER BY abs(notches) DESC NULLS LAST, id DESC
+		LIMIT $%d`, len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cases := []models.ScoreDivergenceCase{}
+	for rows.Next() {
+		var d models.ScoreDivergenceCase
+		if err := rows.Scan(&d.InquiryId, &d.ScoreCard, &d.Provider, &d.SubjectIin, &d.Score, &d.RiskGrade,
+			&d.ScoreByML, &d.RiskGradeByML, &d.Comparable, &d.GradeMismatch, &d.GradeNotchDelta, &d.InquiredAt); err != nil {
+			return nil, err
+		}
+		cases = append(cases, d)
+	}
+	return cases, rows.Err()
+}
@@ -0,0 +1,271 @@
+package repositories
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"go-keycloak-jwt/db"
+	"go-keycloak-jwt/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const scoreInquiryColumns = `id, user_id, user_name, team, subject_iin, score_card, provider, attributes, id_query, error_code,
+	error_string, score, one_year_probability_of_default, risk_grade, score_by_ml, one_year_probability_of_default_by_ml,
+	risk_grade_by_ml, latency_ms, cached_from_id, consent_id, decision, decision_rule, decision_rules_version, inquired_at,
+	created_at`
+
+// CreateScoreInquiry сохраняет запрос скоринга вместе с причинами.
+// q может быть транзакцией — тогда запись и причины фиксируются атомарно.
+func CreateScoreInquiry(ctx context.Context, q db.Querier, inquiry *models.ScoreInquiry) error {
+	attributes := inquiry.Attributes
+	if len(attributes) == 0 {
+		attributes = []byte("{}")
+	}
+
+	err := q.QueryRow(ctx, `
+		INSERT INTO score_inquiries (user_id, user_name, team, subject_iin, score_card, provider, attributes, id_query,
+			error_code, error_string, score, one_year_probability_of_default, risk_grade, score_by_ml,
+			one_year_probability_of_default_by_ml, risk_grade_by_ml, latency_ms, cached_from_id, consent_id, decision,
+			decision_rule, decision_rules_version, inquired_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)
+		RETURNING id, created_at`,
+		inquiry.UserId, inquiry.UserName, inquiry.Team, inquiry.SubjectIin, inquiry.ScoreCard, inquiry.Provider, attributes, inquiry.IdQuery, inquiry.ErrorCode,
+		inquiry.ErrorString, inquiry.Score, inquiry.OneYearProbabilityOfDefault, inquiry.RiskGrade, inquiry.ScoreByML,
+		inquiry.OneYearProbabilityOfDefaultByML, inquiry.RiskGradeByML, inquiry.LatencyMs, inquiry.CachedFromId,
+		inquiry.ConsentId, inquiry.Decision, inquiry.DecisionRule, inquiry.DecisionRulesVersion, inquiry.InquiredAt,
+	).Scan(&inquiry.Id, &inquiry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert score inquiry: %v", err)
+	}
+
+	for i, cause := range inquiry.Causes {
+		_, err := q.Exec(ctx,
+			"INSERT INTO score_inquiry_causes (inquiry_id, position, name, cause_text) VALUES ($1, $2, $3, $4)",
+			inquiry.Id, i, cause.Name, cause.CauseText)
+		if err != nil {
+			return fmt.Errorf("failed to insert score inquiry cause: %v", err)
+		}
+	}
+	return nil
+}
+
+func GetScoreInquiryById(ctx context.Context, id int64) (models.ScoreInquiry, error) {
+	row := db.DB.QueryRow(ctx, "SELECT "+scoreInquiryColumns+" FROM score_inquiries WHERE id=$1", id)
+	inquiry, err := scanScoreInquiry(row)
+	if err != nil {
+		return models.ScoreInquiry{}, err
+	}
+
+	causes, err := getScoreInquiryCauses(ctx, inquiry.Id)
+	if err != nil {
+		return models.ScoreInquiry{}, err
+	}
+	inquiry.Causes = causes
+	return inquiry, nil
+}
+
+// Поля, по которым разрешена сортировка истории, и их тип для сравнения курсора
+var scoreInquirySortColumns = map[string]string{
+	"created_at": "timestamptz",
+	"latency_ms": "bigint",
+}
+
+// FindScoreInquiries возвращает страницу записей, подходящих под фильтр.
+// Пагинация курсорная: курсор хранит значение поля сортировки и id последней записи.
+// Причины в выборку не входят — для них есть GetScoreInquiryById.
+func FindScoreInquiries(ctx context.Context, filter models.ScoreInquiryFilter) (models.ScoreInquiryPage, error) {
+	sort := filter.Sort
+	if sort == "" {
+		sort = "created_at"
+	}
+	sortType, ok := scoreInquirySortColumns[sort]
+	if !ok {
+		return models.ScoreInquiryPage{}, scoreInquiryFilterError("sort", "unsupported sort field: "+sort)
+	}
+	order := strings.ToLower(filter.Order)
+	if order == "" {
+		order = "desc"
+	}
+	if order != "asc" && order != "desc" {
+		return models.ScoreInquiryPage{}, scoreInquiryFilterError("order", "unsupported sort order: "+filter.Order)
+	}
+	limit := filter.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.SubjectIin != "" {
+		add("subject_iin = $%d", filter.SubjectIin)
+	}
+	if filter.UserId != "" {
+		add("user_id = $%d", filter.UserId)
+	}
+	if filter.Team != "" {
+		add("team = $%d", filter.Team)
+	}
+	if filter.ScoreCard != "" {
+		add("score_card = $%d", filter.ScoreCard)
+	}
+	if filter.RiskGrade != "" {
+		add("(risk_grade = $%[1]d OR risk_grade_by_ml = $%[1]d)", filter.RiskGrade)
+	}
+	if filter.ErrorCode != "" {
+		add("error_code = $%d", filter.ErrorCode)
+	}
+	if filter.From != nil {
+		add("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("created_at < $%d", *filter.To)
+	}
+	if filter.Cursor != "" {
+		value, id, err := decodeScoreInquiryCursor(filter.Cursor)
+		if err != nil {
+			return models.ScoreInquiryPage{}, scoreInquiryFilterError("cursor", err.Error())
+		}
+		// Курсор от другой сортировки иначе дошёл бы до базы и упал на приведении типа
+		if !validScoreInquiryCursorValue(sort, value) {
+			return models.ScoreInquiryPage{}, scoreInquiryFilterError("cursor", "invalid cursor")
+		}
+		comparison := "<"
+		if order == "asc" {
+			comparison = ">"
+		}
+		args = append(args, value, id)
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s ($%d::text::%s, $%d)",
+			sort, comparison, len(args)-1, sortType, len(args)))
+	}
+
+	query := "SELECT " + scoreInquiryColumns + " FROM score_inquiries"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	// Берём на одну запись больше, чтобы понять, есть ли следующая страница
+	args = append(args, limit+1)
+	query += fmt.Sprintf(" ORDER BY %[1]s %[2]s, id %[2]s LIMIT $%[3]d", sort, order, len(args))
+
+	rows, err := db.DB.Query(ctx, query, args...)
+	if err != nil {
+		return models.ScoreInquiryPage{}, err
+	}
+	defer rows.Close()
+
+	page := models.ScoreInquiryPage{Data: []models.ScoreInquiry{}}
+	for rows.Next() {
+		inquiry, err := scanScoreInquiry(rows)
+		if err != nil {
+			return models.ScoreInquiryPage{}, err
+		}
+		page.Data = append(page.Data, inquiry)
+	}
+	if err := rows.Err(); err != nil {
+		return models.ScoreInquiryPage{}, err
+	}
+
+	if len(page.Data) > limit {
+		page.Data = page.Data[:limit]
+		last := page.Data[limit-1]
+		value := last.CreatedAt.Format(time.RFC3339Nano)
+		if sort == "latency_ms" {
+			value = strconv.FormatInt(last.LatencyMs, 10)
+		}
+		page.NextCursor = encodeScoreInquiryCursor(value, last.Id)
+	}
+	return page, nil
+}
+
+// Ошибки параметров фильтра отличаются от ошибок базы: это ошибка клиента (400)
+func scoreInquiryFilterError(field, message string) error {
+	return &models.ValidationError{Fields: []models.FieldError{{Field: field, Code: "INVALID_PARAMETER", Message: message}}}
+}
+
+func validScoreInquiryCursorValue(sort, value string) bool {
+	if sort == "latency_ms" {
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	}
+	_, err := time.Parse(time.RFC3339Nano, value)
+	return err == nil
+}
+
+func encodeScoreInquiryCursor(value string, id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(value + "|" + strconv.FormatInt(id, 10)))
+}
+
+func decodeScoreInquiryCursor(cursor string) (string, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid cursor")
+	}
+	sep := strings.LastIndex(string(raw), "|")
+	if sep < 0 {
+		return "", 0, fmt.Errorf("invalid cursor")
+	}
+	id, err := strconv.ParseInt(string(raw[sep+1:]), 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid cursor")
+	}
+	return string(raw[:sep]), id, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanScoreInquiry(row rowScanner) (models.ScoreInquiry, error) {
+	var inquiry models.ScoreInquiry
+	err := row.Scan(&inquiry.Id, &inquiry.UserId, &inquiry.UserName, &inquiry.Team, &inquiry.SubjectIin, &inquiry.ScoreCard, &inquiry.Provider, &inquiry.Attributes,
+		&inquiry.IdQuery, &inquiry.ErrorCode, &inquiry.ErrorString, &inquiry.Score,
+		&inquiry.OneYearProbabilityOfDefault, &inquiry.RiskGrade, &inquiry.ScoreByML,
+		&inquiry.OneYearProbabilityOfDefaultByML, &inquiry.RiskGradeByML, &inquiry.LatencyMs, &inquiry.CachedFromId,
+		&inquiry.ConsentId, &inquiry.Decision, &inquiry.DecisionRule, &inquiry.DecisionRulesVersion, &inquiry.InquiredAt, &inquiry.CreatedAt)
+	return inquiry, err
+}
+
+func getScoreInquiryCauses(ctx context.Context, inquiryId int64) ([]models.Causes, error) {
+	rows, err := db.DB.Query(ctx,
+		"SELECT name, cause_text FROM score_inquiry_causes WHERE inquiry_id=$1 ORDER BY position", inquiryId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var causes []models.Causes
+	for rows.Next() {
+		var cause models.Causes
+		if err := rows.Scan(&cause.Name, &cause.CauseText); err != nil {
+			return nil, err
+		}
+		causes = append(causes, cause)
+	}
+	return causes, rows.Err()
+}
+
+// FindLatestSuccessfulScoreInquiryId — последний успешный запрос по субъекту и карте до beforeId;
+// found = false, если такого нет
+func FindLatestSuccessfulScoreInquiryId(ctx context.Context, subjectIin string, scoreCard string, beforeId int64) (int64, bool, error) {
+	var id int64
+	err := db.DB.QueryRow(ctx, `
+		SELECT id FROM score_inquiries
+		WHERE subject_iin = $1 AND score_card = $2 AND error_code = '0' AND id < $3
+		ORDER BY id DESC LIMIT 1`, subjectIin, scoreCard, beforeId,
+	).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return id, true, nil
+}
@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"go-keycloak-jwt/db"
+	"go-keycloak-jwt/models"
+	"strings"
+)
+
+func CreateScoreShadowResult(ctx context.Context, result *models.ScoreShadowResult) error {
+	return db.DB.QueryRow(ctx, `
+		INSERT INTO score_shadow_results (inquiry_id, provider, score_card, id_query, error_code, error_string, score,
+			one_year_probability_of_default, risk_grade, score_by_ml, one_year_probability_of_default_by_ml, risk_grade_by_ml,
+			latency_ms)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, created_at`,
+		result.InquiryId, result.Provider, result.ScoreCard, result.IdQuery, result.ErrorCode, result.ErrorString,
+		result.Score, result.OneYearProbabilityOfDefault, result.RiskGrade, result.ScoreByML,
+		result.OneYearProbabilityOfDefaultByML, result.RiskGradeByML, result.LatencyMs,
+	).Scan(&result.Id, &result.CreatedAt)
+}
+
+// numericText переводит балл, сохранённый текстом как его вернул провайдер (возможна десятичная запятая),
+// в число; нечисловые значения дают NULL
+func numericText(column string) string {
+	return fmt.Sprintf(`CASE WHEN replace(%[1]s, ',', '.') ~ '^-?[0-9]+(\.[0-9]+)?$' THEN replace(%[1]s, ',', '.')::float8 END`, column)
+}
+
+// pdText переводит PD, сохранённую текстом, в долю единицы по тем же правилам, что pd.Parse: верхняя граница
+// диапазона бюро "2% - 3%", процент или доля; прочие значения и выход за 0..1 дают NULL
+func pdText(column string) string {
+	value := fmt.Sprintf(`replace(trim(%s), ',', '.')`, column)
+	return fmt.Sprintf(`(SELECT pd FROM (SELECT CASE
+		WHEN %[1]s ~ '^([0-9]+(\.[0-9]+)?\s*%%?\s*-\s*)?[0-9]+(\.[0-9]+)?\s*%%$'
+			THEN substring(%[1]s FROM '([0-9]+(?:\.[0-9]+)?)\s*%%$')::float8 / 100
+		WHEN %[1]s ~ '^[0-9]+(\.[0-9]+)?$' THEN %[1]s::float8
+	END AS pd) parsed WHERE pd <= 1)`, value)
+}
+
+// gradeText — класс риска для сравнения; пустой класс даёт NULL, и пара не считается сравнимой
+func gradeText(column string) string {
+	return fmt.Sprintf(`NULLIF(upper(trim(%s)), '')`, column)
+}
+
+// CompareScoreShadowResults сводит теневые результаты с основными по паре провайдеров и карте
+func CompareScoreShadowResults(ctx context.Context, filter models.ScoreShadowFilter) ([]models.ScoreShadowComparison, error) {
+	conditions := []string{"TRUE"}
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Provider != "" {
+		add("i.provider = $%d", filter.Provider)
+	}
+	if filter.ShadowProvider != "" {
+		add("s.provider = $%d", filter.ShadowProvider)
+	}
+	if filter.ScoreCard != "" {
+		add("i.score_card = $%d", filter.ScoreCard)
+	}
+	if filter.From != nil {
+		add("s.created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("s.created_at < $%d", *filter.To)
+	}
+
+	query := `
+		WITH pairs AS (
+			SELECT i.provider, s.provider AS shadow_provider, i.score_card,
+				i.error_code = '0' AND s.error_code = '0' AS compared,
+				s.error_code <> '0' AS shadow_error,
+				` + gradeText("i.risk_grade") + ` = ` + gradeText("s.risk_grade") + ` AS grade_match,
+				` + gradeText("i.risk_grade_by_ml") + ` = ` + gradeText("s.risk_grade_by_ml") + ` AS grade_by_ml_match,
+				` + numericText("s.score") + ` - ` + numericText("i.score") + ` AS delta,
+				` + pdText("s.one_year_probability_of_default_by_ml") + ` - ` + pdText("i.one_year_probability_of_default_by_ml") + ` AS pd_by_ml_delta,
+				i.latency_ms AS primary_latency, s.latency_ms AS shadow_latency
+			FROM score_shadow_results s JOIN score_inquiries i ON i.id = s.inquiry_id
+			WHERE ` + strings.Join(conditions, " AND ") + `
+		)
+		SELECT provider, shadow_provider, score_card, count(*),
+			count(*) FILTER (WHERE shadow_error),
+			count(*) FILTER (WHERE compared),
+			count(grade_match) FILTER (WHERE compared),
+			count(*) FILTER (WHERE compared AND grade_match),
+			count(grade_by_ml_match) FILTER (WHERE compared),
+			count(*) FILTER (WHERE compared AND grade_by_ml_match),
+			avg(delta) FILTER (WHERE compared),
+			avg(abs(delta)) FILTER (WHERE compared),
+			min(delta) FILTER (WHERE compared),
+			max(delta) FILTER (WHERE compared),
+			avg(pd_by_ml_delta) FILTER (WHERE compared),
+			avg(abs(pd_by_ml_delta)) FILTER (WHERE compared),
+			avg(primary_latency)::float8, avg(shadow_latency)::float8
+		FROM pairs
+		GROUP BY provider, shadow_provider, score_card
+		ORDER BY provider, shadow_provider, score_card`
+
+	rows, err := db.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comparisons := []models.ScoreShadowComparison{}
+	for rows.Next() {
+		var c models.ScoreShadowComparison
+		if err := rows.Scan(&c.Provider, &c.ShadowProvider, &c.ScoreCard, &c.Total, &c.ShadowErrors, &c.Compared,
+			&c.RiskGradeCompared, &c.RiskGradeMatches, &c.RiskGradeByMLCompared, &c.RiskGradeByMLMatches, &c.ScoreDeltaMean, &c.ScoreDeltaMeanAbs, &c.ScoreDeltaMin, &c.ScoreDeltaMax,
+			&c.PdByMLDeltaMean, &c.PdByMLDeltaMeanAbs,
+			&c.PrimaryLatencyMeanMs, &c.ShadowLatencyMeanMs); err != nil {
+			return nil, err
+		}
+		comparisons = append(comparisons, c)
+	}
+	return comparisons, rows.Err()
+}
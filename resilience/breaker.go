@@ -0,0 +1,139 @@
+// Package resilience содержит примитивы для устойчивых вызовов внешних
+// сервисов: автоматический выключатель (circuit breaker) и повторы с джиттером.
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type State string
+
+const (
+	Closed   State = "closed"
+	Open     State = "open"
+	HalfOpen State = "half-open"
+)
+
+// OpenError возвращается, пока выключатель разомкнут
+type OpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("circuit breaker is open, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// Состояние выключателя для health-проверок
+type Snapshot struct {
+	State               State      `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	RetryAfterSeconds   int        `json:"retry_after_seconds,omitempty"`
+}
+
+// CircuitBreaker размыкается после threshold неудач подряд и не пропускает
+// вызовы в течение cooldown. Затем пропускает один пробный вызов: успех
+// замыкает цепь, неудача снова размыкает её.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{threshold: threshold, cooldown: cooldown, state: Closed}
+}
+
+// Do выполняет fn, если выключатель её пропускает. isFailure решает,
+// считается ли ошибка сбоем сервиса (бизнес-ошибки цепь не размыкают).
+// Отмена вызывающим (context.Canceled) ничего не говорит о сервисе и не считается ни сбоем, ни успехом.
+func (b *CircuitBreaker) Do(fn func() error, isFailure func(error) bool) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+	err := fn()
+	switch {
+	case errors.Is(err, context.Canceled):
+		b.release()
+	case err != nil && isFailure(err):
+		b.failure()
+	default:
+		b.success()
+	}
+	return err
+}
+
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Open:
+		remaining := b.cooldown - time.Since(b.openedAt)
+		if remaining > 0 {
+			return &OpenError{RetryAfter: remaining}
+		}
+		b.state = HalfOpen
+		b.probing = true
+		return nil
+	case HalfOpen:
+		// Пока идёт пробный вызов, остальные получают отказ
+		if b.probing {
+			return &OpenError{RetryAfter: time.Second}
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+func (b *CircuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = Closed
+	b.failures = 0
+	b.probing = false
+}
+
+// release завершает вызов без результата: счётчик сбоев не меняется, следующий вызов снова может стать пробным
+func (b *CircuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *CircuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if b.state == HalfOpen || b.failures >= b.threshold {
+		b.state = Open
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *CircuitBreaker) Snapshot() Snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	snapshot := Snapshot{State: b.state, ConsecutiveFailures: b.failures}
+	if b.state != Closed {
+		openedAt := b.openedAt
+		snapshot.OpenedAt = &openedAt
+	}
+	if b.state == Open {
+		if remaining := b.cooldown - time.Since(b.openedAt); remaining > 0 {
+			snapshot.RetryAfterSeconds = int(remaining.Round(time.Second).Seconds())
+		}
+	}
+	return snapshot
+}
@@ -0,0 +1,50 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+var errUnavailable = errors.New("unavailable")
+
+func isUnavailable(err error) bool {
+	return errors.Is(err, errUnavailable)
+}
+
+func TestCircuitBreakerIgnoresCallerCancellation(t *testing.T) {
+	breaker := NewCircuitBreaker(2, time.Minute)
+	fail := func() error { return errUnavailable }
+	canceled := func() error { return fmt.Errorf("request aborted: %w", context.Canceled) }
+
+	_ = breaker.Do(fail, isUnavailable)
+	_ = breaker.Do(canceled, isUnavailable)
+	if got := breaker.Snapshot().ConsecutiveFailures; got != 1 {
+		t.Fatalf("failures after cancellation = %d, want 1", got)
+	}
+
+	_ = breaker.Do(fail, isUnavailable)
+	if state := breaker.Snapshot().State; state != Open {
+		t.Fatalf("state = %s, want %s", state, Open)
+	}
+}
+
+func TestCircuitBreakerCanceledProbeKeepsHalfOpen(t *testing.T) {
+	breaker := NewCircuitBreaker(1, time.Millisecond)
+	_ = breaker.Do(func() error { return errUnavailable }, isUnavailable)
+	time.Sleep(2 * time.Millisecond)
+
+	_ = breaker.Do(func() error { return context.Canceled }, isUnavailable)
+	if state := breaker.Snapshot().State; state != HalfOpen {
+		t.Fatalf("state after canceled probe = %s, want %s", state, HalfOpen)
+	}
+	// Отменённая проба не занимает место пробного вызова
+	if err := breaker.Do(func() error { return nil }, isUnavailable); err != nil {
+		t.Fatalf("next probe error = %v", err)
+	}
+	if state := breaker.Snapshot().State; state != Closed {
+		t.Fatalf("state = %s, want %s", state, Closed)
+	}
+}
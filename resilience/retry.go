@@ -0,0 +1,33 @@
+package resilience
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Retry вызывает fn до attempts раз, пока retryable считает ошибку временной.
+// Пауза между попытками растёт экспоненциально от base и выбирается случайно
+// в пределах [0, base*2^n) (full jitter), чтобы клиенты не били в сервис разом.
+func Retry(ctx context.Context, attempts int, base time.Duration, fn func() error, retryable func(error) bool) error {
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if err = fn(); err == nil || !retryable(err) {
+			return err
+		}
+		if attempt == attempts-1 {
+			break
+		}
+
+		var delay time.Duration
+		if base > 0 {
+			delay = time.Duration(rand.Int63n(int64(base << attempt)))
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+	return err
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"go-keycloak-jwt/helpers"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/repositories"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrBureauArchiveNotFound = errors.New("no archived bureau response for this inquiry")
+	ErrBureauArchiveDisabled = errors.New("bureau response archive is not configured")
+)
+
+// Мастер-ключи архива по идентификаторам; новые записи шифруются текущим,
+// старые ключи остаются в конфигурации, чтобы читать записи до ротации.
+type archiveKeyring struct {
+	currentId string
+	keys      map[string][]byte
+}
+
+var bureauArchiveKeys *archiveKeyring
+
+// InitBureauArchive читает мастер-ключи архива ответов бюро:
+// BUREAU_ARCHIVE_KEYS — список "id:base64" через запятую (каждый ключ 32 байта),
+// BUREAU_ARCHIVE_KEY_ID — ключ для новых записей (по умолчанию первый в списке).
+// Без ключей архив выключен.
+func InitBureauArchive() {
+	value := os.Getenv("BUREAU_ARCHIVE_KEYS")
+	if value == "" {
+		log.Print("BUREAU_ARCHIVE_KEYS is not set, bureau responses are not archived")
+		bureauArchiveKeys = nil
+		return
+	}
+
+	keyring := &archiveKeyring{keys: map[string][]byte{}}
+	for _, item := range strings.Split(value, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok || id == "" {
+			log.Fatalf("invalid BUREAU_ARCHIVE_KEYS entry %q, expected id:base64", item)
+		}
+		key, err := helpers.DecodeAESKey(encoded)
+		if err != nil {
+			log.Fatalf("invalid BUREAU_ARCHIVE_KEYS key %s: %v", id, err)
+		}
+		keyring.keys[id] = key
+		if keyring.currentId == "" {
+			keyring.currentId = id
+		}
+	}
+	if id := os.Getenv("BUREAU_ARCHIVE_KEY_ID"); id != "" {
+		if _, ok := keyring.keys[id]; !ok {
+			log.Fatalf("BUREAU_ARCHIVE_KEY_ID %s is not in BUREAU_ARCHIVE_KEYS", id)
+		}
+		keyring.currentId = id
+	}
+	bureauArchiveKeys = keyring
+}
+
+// Сырой ответ бюро на последний запрос в рамках контекста
+type bureauExchange struct {
+	mu         sync.Mutex
+	operation  string
+	statusCode int
+	body       []byte
+}
+
+type bureauExchangeKey struct{}
+
+// withBureauExchange добавляет в контекст место для сырого ответа бюро; его заполняет SOAP-клиент
+func withBureauExchange(ctx context.Context) (context.Context, *bureauExchange) {
+	exchange := &bureauExchange{}
+	return context.WithValue(ctx, bureauExchangeKey{}, exchange), exchange
+}
+
+// captureBureauExchange запоминает ответ, если вызывающий его ждёт; при повторах остаётся последний
+func captureBureauExchange(ctx context.Context, operation string, statusCode int, body []byte) {
+	exchange, ok := ctx.Value(bureauExchangeKey{}).(*bureauExchange)
+	if !ok {
+		return
+	}
+	exchange.mu.Lock()
+	defer exchange.mu.Unlock()
+	exchange.operation = operation
+	exchange.statusCode = statusCode
+	exchange.body = append([]byte(nil), body...)
+}
+
+// sealBureauExchange шифрует ответ для архива: ключ данных свой у каждой записи
+// и хранится обёрнутым текущим мастер-ключом. Возвращает nil, если архив выключен или ответа не было.
+func sealBureauExchange(exchange *bureauExchange, inquiryId int64) (*models.BureauArchiveRecord, error) {
+	if bureauArchiveKeys == nil || exchange == nil {
+		return nil, nil
+	}
+	exchange.mu.Lock()
+	defer exchange.mu.Unlock()
+	if exchange.operation == "" {
+		return nil, nil
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate archive data key: %v", err)
+	}
+	ciphertext, err := helpers.EncryptAESGCM(dataKey, exchange.body, archiveAdditionalData(inquiryId))
+	if err != nil {
+		return nil, err
+	}
+	keyId := bureauArchiveKeys.currentId
+	wrappedKey, err := helpers.EncryptAESGCM(bureauArchiveKeys.keys[keyId], dataKey, []byte(keyId))
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(exchange.body)
+	return &models.BureauArchiveRecord{
+		InquiryId:  inquiryId,
+		Operation:  exchange.operation,
+		StatusCode: exchange.statusCode,
+		KeyId:      keyId,
+		WrappedKey: wrappedKey,
+		Ciphertext: ciphertext,
+		Sha256:     hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+// Шифртекст привязан к запросу: перенос записи на другой inquiry_id не расшифруется
+func archiveAdditionalData(inquiryId int64) []byte {
+	return []byte(fmt.Sprintf("score_inquiry:%d", inquiryId))
+}
+
+// GetArchivedBureauResponse расшифровывает ответ бюро по запросу скоринга. Для запроса из кэша
+// берётся ответ исходного запроса (cached_from_id): решение принималось по нему.
+// Каждое обращение пишется в журнал с исходом; без записи в журнал ответ не выдаётся.
+func GetArchivedBureauResponse(ctx context.Context, principal models.Principal, inquiryId int64, reason string) (models.BureauArchiveResponse, error) {
+	if bureauArchiveKeys == nil {
+		return models.BureauArchiveResponse{}, ErrBureauArchiveDisabled
+	}
+
+	inquiry, err := repositories.GetScoreInquiryById(ctx, inquiryId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.BureauArchiveResponse{}, ErrBureauArchiveNotFound
+	}
+	if err != nil {
+		return models.BureauArchiveResponse{}, err
+	}
+	sourceId := inquiryId
+	if inquiry.CachedFromId != nil {
+		sourceId = *inquiry.CachedFromId
+	}
+
+	record, err := repositories.GetBureauArchiveRecordByInquiryId(ctx, sourceId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.BureauArchiveResponse{}, ErrBureauArchiveNotFound
+	}
+	if err != nil {
+		return models.BureauArchiveResponse{}, err
+	}
+
+	body, decryptErr := decryptBureauArchiveRecord(record)
+	access := models.BureauArchiveAccess{
+		ArchiveId: record.Id,
+		InquiryId: inquiryId,
+		UserId:    principal.UserId,
+		UserName:  principal.UserName,
+		Reason:    reason,
+		Outcome:   models.BureauArchiveRead,
+	}
+	if decryptErr != nil {
+		access.Outcome = models.BureauArchiveFailed
+		access.Error = decryptErr.Error()
+	}
+	if err := repositories.CreateBureauArchiveAccess(ctx, &access); err != nil {
+		return models.BureauArchiveResponse{}, fmt.Errorf("failed to log archive access: %v", err)
+	}
+	if decryptErr != nil {
+		return models.BureauArchiveResponse{}, decryptErr
+	}
+
+	return models.BureauArchiveResponse{
+		InquiryId:       inquiryId,
+		ServedFromCache: inquiry.CachedFromId != nil,
+		SourceInquiryId: record.InquiryId,
+		Operation:       record.Operation,
+		StatusCode:      record.StatusCode,
+		KeyId:           record.KeyId,
+		Sha256:          record.Sha256,
+		ResponseXml:     string(body),
+		CreatedAt:       record.CreatedAt,
+	}, nil
+}
+
+func decryptBureauArchiveRecord(record models.BureauArchiveRecord) ([]byte, error) {
+	masterKey, ok := bureauArchiveKeys.keys[record.KeyId]
+	if !ok {
+		return nil, fmt.Errorf("archive master key %s is not configured", record.KeyId)
+	}
+	dataKey, err := helpers.DecryptAESGCM(masterKey, record.WrappedKey, []byte(record.KeyId))
+	if err != nil {
+		return nil, err
+	}
+	return helpers.DecryptAESGCM(dataKey, record.Ciphertext, archiveAdditionalData(record.InquiryId))
+}
+
+func GetBureauArchiveAccessLog(ctx context.Context, inquiryId int64, limit int) ([]models.BureauArchiveAccess, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return repositories.FindBureauArchiveAccess(ctx, inquiryId, limit)
+}
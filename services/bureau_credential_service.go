@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-keycloak-jwt/helpers"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/repositories"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNoBureauCredential       = errors.New("no credit bureau credentials configured for this user")
+	ErrBureauCredentialNotFound = errors.New("bureau credential not found")
+	ErrCredentialStoreReadOnly  = errors.New("bureau credentials are loaded from a file and cannot be changed via API")
+	ErrCredentialStoreDisabled  = errors.New("bureau credentials store is not configured")
+)
+
+// CredentialStore сопоставляет пользователя Keycloak с учётными данными бюро.
+// Приоритет: запись пользователя, затем его групп (в порядке из токена), затем запись по умолчанию.
+type CredentialStore interface {
+	Lookup(ctx context.Context, principal models.Principal) (models.BureauCredential, error)
+	List(ctx context.Context) ([]models.BureauCredential, error)
+	Save(ctx context.Context, credential models.BureauCredential) (models.BureauCredential, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+var bureauCredentials CredentialStore
+
+// InitBureauCredentials настраивает хранилище учётных данных бюро:
+// BUREAU_CREDENTIALS=postgres (по умолчанию) — таблица bureau_credentials, пароли
+// шифруются ключом BUREAU_CREDENTIALS_KEY (base64, 32 байта);
+// BUREAU_CREDENTIALS=file — JSON-файл BUREAU_CREDENTIALS_FILE для локальной работы,
+// перечитывается при изменении.
+func InitBureauCredentials() {
+	switch os.Getenv("BUREAU_CREDENTIALS") {
+	case "", "postgres":
+		// Статическому клиенту учётные данные не нужны, поэтому без бюро ключ необязателен
+		if os.Getenv("BUREAU_CREDENTIALS_KEY") == "" && os.Getenv("BUREAU_URL") == "" {
+			log.Print("BUREAU_CREDENTIALS_KEY is not set, bureau credentials store is disabled")
+			bureauCredentials = disabledCredentialStore{}
+			return
+		}
+		key, err := helpers.DecodeAESKey(os.Getenv("BUREAU_CREDENTIALS_KEY"))
+		if err != nil {
+			log.Fatalf("invalid BUREAU_CREDENTIALS_KEY: %v", err)
+		}
+		bureauCredentials = &postgresCredentialStore{key: key}
+	case "file":
+		path := os.Getenv("BUREAU_CREDENTIALS_FILE")
+		if path == "" {
+			log.Fatal("BUREAU_CREDENTIALS_FILE is required when BUREAU_CREDENTIALS=file")
+		}
+		bureauCredentials = &fileCredentialStore{path: path}
+	default:
+		log.Fatalf("unknown BUREAU_CREDENTIALS: %s", os.Getenv("BUREAU_CREDENTIALS"))
+	}
+}
+
+func ListBureauCredentials(ctx context.Context) ([]models.BureauCredential, error) {
+	return bureauCredentials.List(ctx)
+}
+
+// SaveBureauCredential создаёт или ротирует учётные данные; вступают в силу со следующего запроса
+func SaveBureauCredential(ctx context.Context, principal models.Principal, request models.BureauCredentialRequest) (models.BureauCredential, error) {
+	credential := models.BureauCredential{
+		SubjectType: request.SubjectType,
+		Subject:     request.Subject,
+		UserName:    request.UserName,
+		Password:    request.Password,
+		Culture:     request.Culture,
+		Version:     request.Version,
+		UpdatedBy:   principal.UserName,
+	}
+	if credential.SubjectType == models.CredentialSubjectDefault {
+		credential.Subject = ""
+	} else if credential.Subject == "" {
+		return models.BureauCredential{}, fmt.Errorf("subject is required for %s credentials", credential.SubjectType)
+	}
+	return bureauCredentials.Save(ctx, withCredentialDefaults(credential))
+}
+
+// withCredentialDefaults подставляет Culture и Version, если они не заданы, — для любого хранилища
+func withCredentialDefaults(credential models.BureauCredential) models.BureauCredential {
+	if credential.Culture == "" {
+		credential.Culture = "ru-RU"
+	}
+	if credential.Version == "" {
+		credential.Version = "1"
+	}
+	return credential
+}
+
+func DeleteBureauCredential(ctx context.Context, id int64) error {
+	return bureauCredentials.Delete(ctx, id)
+}
+
+// pickBureauCredential выбирает запись с наивысшим приоритетом для пользователя
+func pickBureauCredential(principal models.Principal, candidates []models.BureauCredential) (models.BureauCredential, error) {
+	for _, c := range candidates {
+		if c.SubjectType == models.CredentialSubjectUser && c.Subject == principal.UserId {
+			return c, nil
+		}
+	}
+	for _, group := range principal.Groups {
+		for _, c := range candidates {
+			if c.SubjectType == models.CredentialSubjectGroup && c.Subject == group {
+				return c, nil
+			}
+		}
+	}
+	for _, c := range candidates {
+		if c.SubjectType == models.CredentialSubjectDefault {
+			return c, nil
+		}
+	}
+	return models.BureauCredential{}, ErrNoBureauCredential
+}
+
+type postgresCredentialStore struct {
+	key []byte
+}
+
+// Шифртекст привязан к субъекту, чтобы пароль нельзя было переставить в чужую запись
+func credentialAAD(c models.BureauCredential) []byte {
+	return []byte(c.SubjectType + ":" + c.Subject)
+}
+
+func (p *postgresCredentialStore) Lookup(ctx context.Context, principal models.Principal) (models.BureauCredential, error) {
+	rows, err := repositories.FindBureauCredentials(ctx, principal.UserId, principal.Groups)
+	if err != nil {
+		return models.BureauCredential{}, err
+	}
+	var candidates []models.BureauCredential
+	for _, row := range rows {
+		candidates = append(candidates, row.BureauCredential)
+	}
+
+	credential, err := pickBureauCredential(principal, candidates)
+	if err != nil {
+		return models.BureauCredential{}, err
+	}
+	for _, row := range rows {
+		if row.Id == credential.Id {
+			password, err := helpers.DecryptAESGCM(p.key, row.PasswordEnc, credentialAAD(credential))
+			if err != nil {
+				return models.BureauCredential{}, fmt.Errorf("failed to decrypt bureau credential %d: %v", row.Id, err)
+			}
+			credential.Password = string(password)
+		}
+	}
+	return credential, nil
+}
+
+func (p *postgresCredentialStore) List(ctx context.Context) ([]models.BureauCredential, error) {
+	rows, err := repositories.ListBureauCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	credentials := []models.BureauCredential{}
+	for _, row := range rows {
+		credentials = append(credentials, row.BureauCredential)
+	}
+	return credentials, nil
+}
+
+func (p *postgresCredentialStore) Save(ctx context.Context, credential models.BureauCredential) (models.BureauCredential, error) {
+	passwordEnc, err := helpers.EncryptAESGCM(p.key, []byte(credential.Password), credentialAAD(credential))
+	if err != nil {
+		return models.BureauCredential{}, err
+	}
+	row := repositories.EncryptedBureauCredential{BureauCredential: credential, PasswordEnc: passwordEnc}
+	if err := repositories.UpsertBureauCredential(ctx, &row); err != nil {
+		return models.BureauCredential{}, err
+	}
+	return row.BureauCredential, nil
+}
+
+func (p *postgresCredentialStore) Delete(ctx context.Context, id int64) error {
+	found, err := repositories.DeleteBureauCredential(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrBureauCredentialNotFound
+	}
+	return nil
+}
+
+// fileCredentialStore читает JSON-массив объектов в формате BureauCredentialRequest.
+// Файл перечитывается, когда меняется время его модификации.
+type fileCredentialStore struct {
+	path string
+
+	mu          sync.Mutex
+	modTime     time.Time
+	credentials []models.BureauCredential
+}
+
+func (f *fileCredentialStore) load() ([]models.BureauCredential, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bureau credentials file: %v", err)
+	}
+	if f.credentials != nil && info.ModTime().Equal(f.modTime) {
+		return f.credentials, nil
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bureau credentials file: %v", err)
+	}
+	var requests []models.BureauCredentialRequest
+	if err := json.Unmarshal(data, &requests); err != nil {
+		return nil, fmt.Errorf("failed to parse bureau credentials file: %v", err)
+	}
+
+	credentials := make([]models.BureauCredential, 0, len(requests))
+	for i, r := range requests {
+		credentials = append(credentials, withCredentialDefaults(models.BureauCredential{
+			Id:          int64(i + 1),
+			SubjectType: r.SubjectType,
+			Subject:     r.Subject,
+			UserName:    r.UserName,
+			Password:    r.Password,
+			Culture:     r.Culture,
+			Version:     r.Version,
+			UpdatedAt:   info.ModTime(),
+		}))
+	}
+	f.credentials = credentials
+	f.modTime = info.ModTime()
+	return credentials, nil
+}
+
+func (f *fileCredentialStore) Lookup(_ context.Context, principal models.Principal) (models.BureauCredential, error) {
+	credentials, err := f.load()
+	if err != nil {
+		return models.BureauCredential{}, err
+	}
+	return pickBureauCredential(principal, credentials)
+}
+
+func (f *fileCredentialStore) List(_ context.Context) ([]models.BureauCredential, error) {
+	return f.load()
+}
+
+func (f *fileCredentialStore) Save(context.Context, models.BureauCredential) (models.BureauCredential, error) {
+	return models.BureauCredential{}, ErrCredentialStoreReadOnly
+}
+
+func (f *fileCredentialStore) Delete(context.Context, int64) error {
+	return ErrCredentialStoreReadOnly
+}
+
+type disabledCredentialStore struct{}
+
+func (disabledCredentialStore) Lookup(context.Context, models.Principal) (models.BureauCredential, error) {
+	return models.BureauCredential{}, ErrNoBureauCredential
+}
+
+func (disabledCredentialStore) List(context.Context) ([]models.BureauCredential, error) {
+	return []models.BureauCredential{}, nil
+}
+
+func (disabledCredentialStore) Save(context.Context, models.BureauCredential) (models.BureauCredential, error) {
+	return models.BureauCredential{}, ErrCredentialStoreDisabled
+}
+
+func (disabledCredentialStore) Delete(context.Context, int64) error {
+	return ErrCredentialStoreDisabled
+}
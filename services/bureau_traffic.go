@@ -0,0 +1,275 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/pii"
+	"go-keycloak-jwt/repositories"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Заголовки с учётными данными: в записи их значения заменяются
+var redactedHeaders = []string{"Password", "SecurityToken", "Authorization"}
+
+// Те же поля внутри XML, если бюро когда-нибудь начнёт принимать их в теле
+var redactedElements = regexp.MustCompile(`(?s)<((?:\w+:)?(?:Password|SecurityToken))>.*?</(?:\w+:)?(?:Password|SecurityToken)>`)
+
+// TrafficStore хранит записанный обмен с бюро
+type TrafficStore interface {
+	Save(ctx context.Context, record models.BureauTrafficRecord) error
+	Find(ctx context.Context, matchKey string) (models.BureauTrafficRecord, bool, error)
+}
+
+// newTrafficTransport оборачивает транспорт SOAP-клиента в запись или воспроизведение
+// по переменным окружения:
+// BUREAU_TRAFFIC_MODE — record (запросы идут в бюро и сохраняются) или replay
+// (в бюро ничего не уходит, ответы берутся из записей); пусто — выключено.
+// BUREAU_TRAFFIC_STORE — dir (каталог BUREAU_TRAFFIC_DIR с JSON-файлами) или postgres.
+func newTrafficTransport(next http.RoundTripper) http.RoundTripper {
+	mode := os.Getenv("BUREAU_TRAFFIC_MODE")
+	if mode == "" {
+		return next
+	}
+
+	var store TrafficStore
+	switch os.Getenv("BUREAU_TRAFFIC_STORE") {
+	case "", "dir":
+		dir := os.Getenv("BUREAU_TRAFFIC_DIR")
+		if dir == "" {
+			dir = "testdata/bureau-traffic"
+		}
+		store = dirTrafficStore{dir: dir}
+	case "postgres":
+		store = postgresTrafficStore{}
+	default:
+		log.Fatalf("unknown BUREAU_TRAFFIC_STORE: %s", os.Getenv("BUREAU_TRAFFIC_STORE"))
+	}
+
+	switch mode {
+	case "record":
+		log.Print("bureau traffic recording is enabled")
+		return &recordingTransport{next: next, store: store}
+	case "replay":
+		log.Print("bureau traffic replay is enabled, the bureau will not be called")
+		return &replayTransport{store: store}
+	}
+	log.Fatalf("unknown BUREAU_TRAFFIC_MODE: %s", mode)
+	return nil
+}
+
+type recordingTransport struct {
+	next  http.RoundTripper
+	store TrafficStore
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := readAndRestore(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := readAndRestore(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	operation, attributes := describeSoapRequest(requestBody)
+	record := models.BureauTrafficRecord{
+		Operation:   operation,
+		MatchKey:    trafficMatchKey(operation, attributes),
+		Attributes:  attributes,
+		RequestXml:  redactXml(requestBody),
+		ResponseXml: redactXml(responseBody),
+		StatusCode:  resp.StatusCode,
+		Headers:     redactHeaders(req.Header),
+		RecordedAt:  time.Now(),
+	}
+	// ИИН в записях маскируются: ключ сопоставления уже посчитан по исходным значениям.
+	// Запись не должна ломать основной запрос, поэтому ошибки только логируем
+	if err := t.store.Save(req.Context(), pii.Mask(record)); err != nil {
+		log.Printf("failed to record bureau traffic: %v", err)
+	}
+	return resp, nil
+}
+
+type replayTransport struct {
+	store TrafficStore
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := readAndRestore(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	operation, attributes := describeSoapRequest(requestBody)
+
+	record, found, err := t.store.Find(req.Context(), trafficMatchKey(operation, attributes))
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("no recorded bureau response for %s %v", operation, attributes)
+	}
+
+	return &http.Response{
+		StatusCode:    record.StatusCode,
+		Status:        fmt.Sprintf("%d %s", record.StatusCode, http.StatusText(record.StatusCode)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"text/xml; charset=utf-8"}},
+		Body:          io.NopCloser(strings.NewReader(record.ResponseXml)),
+		ContentLength: int64(len(record.ResponseXml)),
+		Request:       req,
+	}, nil
+}
+
+// describeSoapRequest определяет операцию и атрибуты запроса для сопоставления при воспроизведении
+func describeSoapRequest(body []byte) (string, map[string]string) {
+	var envelope struct {
+		Body struct {
+			GetScoreCards *struct{} `xml:"GetScoreCards"`
+			Score         *struct {
+				ScoreCard  string `xml:"ScoreCard"`
+				Attributes []struct {
+					Name   string `xml:"name"`
+					Value  string `xml:"value"`
+					Values struct {
+						Id    string `xml:"id"`
+						Value string `xml:"value"`
+					} `xml:"values"`
+				} `xml:"attributes"`
+			} `xml:"Score"`
+		} `xml:"Body"`
+	}
+	if err := xml.Unmarshal(body, &envelope); err != nil {
+		return "Unknown", nil
+	}
+
+	switch {
+	case envelope.Body.GetScoreCards != nil:
+		return "GetScoreCards", map[string]string{}
+	case envelope.Body.Score != nil:
+		attributes := map[string]string{"ScoreCard": strings.TrimSpace(envelope.Body.Score.ScoreCard)}
+		for _, a := range envelope.Body.Score.Attributes {
+			name := strings.ToUpper(strings.TrimSpace(a.Name))
+			attributes[name] = removeSpaces(a.Value)
+			if a.Values.Id != "" || a.Values.Value != "" {
+				attributes[name+".values"] = removeSpaces(a.Values.Id) + "=" + removeSpaces(a.Values.Value)
+			}
+		}
+		return "Score", attributes
+	}
+	return "Unknown", nil
+}
+
+func trafficMatchKey(operation string, attributes map[string]string) string {
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := []string{operation}
+	for _, name := range names {
+		parts = append(parts, name+"="+attributes[name])
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+func redactHeaders(header http.Header) map[string]string {
+	headers := map[string]string{}
+	for name := range header {
+		headers[name] = header.Get(name)
+	}
+	for _, name := range redactedHeaders {
+		if _, ok := headers[http.CanonicalHeaderKey(name)]; ok {
+			headers[http.CanonicalHeaderKey(name)] = "[REDACTED]"
+		}
+	}
+	return headers
+}
+
+func redactXml(body []byte) string {
+	return redactedElements.ReplaceAllString(string(body), "<$1>[REDACTED]</$1>")
+}
+
+// readAndRestore читает тело целиком и подменяет его копией, чтобы его можно было прочитать ещё раз
+func readAndRestore(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	_ = (*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// dirTrafficStore хранит по файлу на ключ сопоставления: <операция>-<ключ>.json.
+// Такие файлы удобно класть рядом с регрессионными тестами.
+type dirTrafficStore struct {
+	dir string
+}
+
+func (d dirTrafficStore) path(operation string, matchKey string) string {
+	return filepath.Join(d.dir, operation+"-"+matchKey[:16]+".json")
+}
+
+func (d dirTrafficStore) Save(_ context.Context, record models.BureauTrafficRecord) error {
+	if err := os.MkdirAll(d.dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(d.path(record.Operation, record.MatchKey), data, 0o600)
+}
+
+func (d dirTrafficStore) Find(_ context.Context, matchKey string) (models.BureauTrafficRecord, bool, error) {
+	matches, err := filepath.Glob(filepath.Join(d.dir, "*-"+matchKey[:16]+".json"))
+	if err != nil || len(matches) == 0 {
+		return models.BureauTrafficRecord{}, false, err
+	}
+	data, err := os.ReadFile(matches[0])
+	if err != nil {
+		return models.BureauTrafficRecord{}, false, err
+	}
+	var record models.BureauTrafficRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return models.BureauTrafficRecord{}, false, fmt.Errorf("invalid traffic record %s: %v", matches[0], err)
+	}
+	return record, record.MatchKey == matchKey, nil
+}
+
+type postgresTrafficStore struct{}
+
+func (postgresTrafficStore) Save(ctx context.Context, record models.BureauTrafficRecord) error {
+	return repositories.CreateBureauTrafficRecord(ctx, &record)
+}
+
+func (postgresTrafficStore) Find(ctx context.Context, matchKey string) (models.BureauTrafficRecord, bool, error) {
+	return repositories.GetLatestBureauTrafficRecord(ctx, matchKey)
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"go-keycloak-jwt/iin"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/repositories"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrConsentRequired — у субъекта нет действующего согласия на запрашиваемую карту; в бюро не обращаемся
+	ErrConsentRequired = errors.New("no valid consent of the subject covers this score card")
+	ErrConsentNotFound = errors.New("consent not found")
+)
+
+// Код ошибки в ответе POST /score при отсутствии согласия
+const ConsentRequiredCode = "CONSENT_REQUIRED"
+
+// consentCheckEnabled — проверка согласий включена всегда, кроме CONSENT_CHECK=off (только для разработки)
+func consentCheckEnabled() bool {
+	return os.Getenv("CONSENT_CHECK") != "off"
+}
+
+func CreateConsent(ctx context.Context, principal models.Principal, request models.ConsentRequest) (models.Consent, error) {
+	subject := strings.TrimSpace(request.SubjectIin)
+	if err := validateConsentSubject(subject); err != nil {
+		return models.Consent{}, err
+	}
+
+	consent := models.Consent{
+		SubjectIin:  subject,
+		Channel:     strings.TrimSpace(request.Channel),
+		GrantedAt:   time.Now(),
+		ExpiresAt:   request.ExpiresAt,
+		DocumentRef: strings.TrimSpace(request.DocumentRef),
+		CreatedBy:   principal.UserName,
+	}
+	if request.GrantedAt != nil {
+		consent.GrantedAt = *request.GrantedAt
+	}
+	for _, scope := range request.Scope {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			consent.Scope = append(consent.Scope, scope)
+		}
+	}
+
+	var fields []models.FieldError
+	if len(consent.Scope) == 0 {
+		fields = append(fields, models.FieldError{Field: "scope", Code: "SCOPE_REQUIRED", Message: "at least one score card or * is required"})
+	}
+	if !consent.ExpiresAt.After(consent.GrantedAt) {
+		fields = append(fields, models.FieldError{Field: "expires_at", Code: "INVALID_EXPIRY", Message: "expires_at must be after granted_at"})
+	}
+	if len(fields) > 0 {
+		return models.Consent{}, &models.ValidationError{Fields: fields}
+	}
+
+	if err := repositories.CreateConsent(ctx, &consent); err != nil {
+		return models.Consent{}, fmt.Errorf("failed to create consent: %v", err)
+	}
+	return consent, nil
+}
+
+func GetSubjectConsents(ctx context.Context, subjectIin string) ([]models.Consent, error) {
+	return repositories.FindConsentsBySubject(ctx, strings.TrimSpace(subjectIin))
+}
+
+func RevokeConsent(ctx context.Context, principal models.Principal, id int64, reason string) (models.Consent, error) {
+	consent, err := repositories.RevokeConsent(ctx, id, principal.UserName, reason)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Consent{}, ErrConsentNotFound
+	}
+	return consent, err
+}
+
+// requireConsent ищет действующее согласие субъекта на карту и возвращает его id.
+// Субъект определяется по атрибуту IIN или BIN; для других атрибутов субъект не известен и проверка не выполняется.
+func requireConsent(ctx context.Context, score models.ScoreRequest) (*int64, error) {
+	if !consentCheckEnabled() {
+		return nil, nil
+	}
+	subject := subjectIdentifier(score)
+	if subject == "" {
+		return nil, nil
+	}
+
+	consents, err := repositories.FindConsentsBySubject(ctx, subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check consent: %v", err)
+	}
+	now := time.Now()
+	for _, consent := range consents {
+		if consent.Valid(now) && consent.Covers(score.Score.ScoreCard) {
+			return &consent.Id, nil
+		}
+	}
+	return nil, ErrConsentRequired
+}
+
+// ИИН или БИН субъекта из атрибутов запроса
+func subjectIdentifier(score models.ScoreRequest) string {
+	switch strings.ToUpper(strings.TrimSpace(score.Score.Attributes.Name)) {
+	case "IIN", "BIN":
+		return strings.TrimSpace(score.Score.Attributes.Value)
+	}
+	return ""
+}
+
+func validateConsentSubject(subject string) error {
+	var err error
+	if iin.IsBIN(subject) {
+		_, err = iin.ParseBIN(subject)
+	} else {
+		_, err = iin.ParseIIN(subject)
+	}
+	if err != nil {
+		return &models.ValidationError{Fields: []models.FieldError{{
+			Field:   "subject_iin",
+			Code:    "INVALID_SUBJECT",
+			Message: err.Error(),
+		}}}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"go-keycloak-jwt/models"
 	"go-keycloak-jwt/repositories"
 )
@@ -9,6 +10,6 @@ func GetCountries() (models.Countries, error) {
 	return repositories.GetAllCountries()
 }
 
-func GetCountryById(reqId string) (models.Country, error) {
-	return repositories.GetCountryById(reqId)
+func GetCountryById(ctx context.Context, reqId string) (models.Country, error) {
+	return repositories.GetCountryById(ctx, reqId)
 }
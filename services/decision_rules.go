@@ -0,0 +1,388 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/repositories"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrDecisionRuleSetNotFound = errors.New("decision rule set not found")
+	ErrNoActiveDecisionRules   = errors.New("no active decision rule set")
+)
+
+// Поля результата скоринга, доступные в условиях правил
+var decisionFields = map[string]func(models.ScoreInquiry) string{
+	"ScoreCard":                       func(i models.ScoreInquiry) string { return i.ScoreCard },
+	"Provider":                        func(i models.ScoreInquiry) string { return i.Provider },
+	"ErrorCode":                       func(i models.ScoreInquiry) string { return i.ErrorCode },
+	"Score":                           func(i models.ScoreInquiry) string { return i.Score },
+	"OneYearProbabilityOfDefault":     func(i models.ScoreInquiry) string { return i.OneYearProbabilityOfDefault },
+	"RiskGrade":                       func(i models.ScoreInquiry) string { return i.RiskGrade },
+	"ScoreByML":                       func(i models.ScoreInquiry) string { return i.ScoreByML },
+	"OneYearProbabilityOfDefaultByML": func(i models.ScoreInquiry) string { return i.OneYearProbabilityOfDefaultByML },
+	"RiskGradeByML":                   func(i models.ScoreInquiry) string { return i.RiskGradeByML },
+}
+
+var decisionValues = map[string]bool{
+	models.DecisionApprove: true,
+	models.DecisionRefer:   true,
+	models.DecisionDecline: true,
+}
+
+// Активный набор правил кэшируется, чтобы не читать его из базы на каждый скоринг;
+// после изменений через API кэш сбрасывается сразу, на других экземплярах — через DECISION_RULES_REFRESH.
+var activeDecisionRules struct {
+	mu       sync.Mutex
+	ruleSet  *models.DecisionRuleSet
+	loadedAt time.Time
+}
+
+func loadActiveDecisionRules(ctx context.Context) (*models.DecisionRuleSet, error) {
+	activeDecisionRules.mu.Lock()
+	defer activeDecisionRules.mu.Unlock()
+
+	if !activeDecisionRules.loadedAt.IsZero() &&
+		time.Since(activeDecisionRules.loadedAt) < envDuration("DECISION_RULES_REFRESH", 30*time.Second) {
+		return activeDecisionRules.ruleSet, nil
+	}
+	ruleSet, found, err := repositories.GetActiveDecisionRuleSet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	activeDecisionRules.ruleSet = nil
+	if found {
+		activeDecisionRules.ruleSet = &ruleSet
+	}
+	activeDecisionRules.loadedAt = time.Now()
+	return activeDecisionRules.ruleSet, nil
+}
+
+func resetActiveDecisionRules() {
+	activeDecisionRules.mu.Lock()
+	activeDecisionRules.loadedAt = time.Time{}
+	activeDecisionRules.mu.Unlock()
+}
+
+// applyDecisionRules принимает решение по успешному результату скоринга активными правилами.
+// Без активных правил и при ошибке бюро решение не выносится.
+func applyDecisionRules(ctx context.Context, inquiry *models.ScoreInquiry) {
+	if inquiry.ErrorCode != "0" {
+		return
+	}
+	ruleSet, err := loadActiveDecisionRules(ctx)
+	if err != nil {
+		log.Printf("failed to load decision rules: %v", err)
+		return
+	}
+	if ruleSet == nil {
+		return
+	}
+
+	decision, _ := evaluateDecisionRules(*ruleSet, decisionFacts(*inquiry))
+	inquiry.Decision = decision.Decision
+	inquiry.DecisionRule = decision.Rule
+	inquiry.DecisionRulesVersion = &decision.RulesVersion
+}
+
+// ScoreDecisionOf возвращает решение, сохранённое в запросе скоринга (nil, если его нет)
+func ScoreDecisionOf(inquiry models.ScoreInquiry) *models.ScoreDecision {
+	if inquiry.Decision == "" || inquiry.DecisionRulesVersion == nil {
+		return nil
+	}
+	return &models.ScoreDecision{
+		Decision:     inquiry.Decision,
+		Rule:         inquiry.DecisionRule,
+		RulesVersion: *inquiry.DecisionRulesVersion,
+	}
+}
+
+func decisionFacts(inquiry models.ScoreInquiry) map[string]string {
+	facts := make(map[string]string, len(decisionFields))
+	for name, get := range decisionFields {
+		facts[name] = get(inquiry)
+	}
+	return facts
+}
+
+// evaluateDecisionRules проверяет правила по порядку; первое правило со всеми выполненными условиями
+// определяет решение. Трассировка нужна для пробного прогона.
+func evaluateDecisionRules(ruleSet models.DecisionRuleSet, facts map[string]string) (models.ScoreDecision, []models.DecisionRuleTrace) {
+	trace := make([]models.DecisionRuleTrace, 0, len(ruleSet.Rules))
+	for _, rule := range ruleSet.Rules {
+		step := models.DecisionRuleTrace{Rule: rule.Name, Matched: true}
+		for _, condition := range rule.When {
+			if !matchDecisionCondition(condition, facts[condition.Field]) {
+				step.Matched = false
+				step.FailedCondition = describeDecisionCondition(condition, facts[condition.Field])
+				break
+			}
+		}
+		trace = append(trace, step)
+		if step.Matched {
+			return models.ScoreDecision{Decision: rule.Decision, Rule: rule.Name, RulesVersion: ruleSet.Version}, trace
+		}
+	}
+	return models.ScoreDecision{Decision: ruleSet.Default, RulesVersion: ruleSet.Version}, trace
+}
+
+func matchDecisionCondition(condition models.DecisionCondition, actual string) bool {
+	actual = strings.TrimSpace(actual)
+	// Пустое поле (например, бюро не вернуло ML-оценку) не удовлетворяет ни одному условию, кроме ne/not_in
+	if actual == "" {
+		return condition.Op == "ne" || condition.Op == "not_in"
+	}
+
+	switch condition.Op {
+	case "eq":
+		return compareDecisionValues(actual, scalarString(condition.Value)) == 0
+	case "ne":
+		return compareDecisionValues(actual, scalarString(condition.Value)) != 0
+	case "gt":
+		return compareDecisionValues(actual, scalarString(condition.Value)) > 0
+	case "gte":
+		return compareDecisionValues(actual, scalarString(condition.Value)) >= 0
+	case "lt":
+		return compareDecisionValues(actual, scalarString(condition.Value)) < 0
+	case "lte":
+		return compareDecisionValues(actual, scalarString(condition.Value)) <= 0
+	case "in", "not_in":
+		found := false
+		for _, value := range listStrings(condition.Value) {
+			if compareDecisionValues(actual, value) == 0 {
+				found = true
+				break
+			}
+		}
+		return found == (condition.Op == "in")
+	case "between":
+		bounds := listStrings(condition.Value)
+		if len(bounds) != 2 {
+			return false
+		}
+		return compareDecisionValues(actual, bounds[0]) >= 0 && compareDecisionValues(actual, bounds[1]) <= 0
+	}
+	return false
+}
+
+// Числа сравниваются численно (бюро может прислать дробную часть через запятую), остальное — как строки
+func compareDecisionValues(a, b string) int {
+	x, errA := parseDecisionNumber(a)
+	y, errB := parseDecisionNumber(b)
+	if errA == nil && errB == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(strings.ToUpper(strings.TrimSpace(a)), strings.ToUpper(strings.TrimSpace(b)))
+}
+
+func parseDecisionNumber(value string) (float64, error) {
+	return strconv.ParseFloat(strings.Replace(strings.TrimSpace(value), ",", ".", 1), 64)
+}
+
+func scalarString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+func listStrings(value interface{}) []string {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		values = append(values, scalarString(item))
+	}
+	return values
+}
+
+func describeDecisionCondition(condition models.DecisionCondition, actual string) string {
+	return fmt.Sprintf("%s %s %v (actual %q)", condition.Field, condition.Op, condition.Value, actual)
+}
+
+// validateDecisionRuleSet проверяет набор до сохранения, чтобы ошибка в правилах не всплыла во время скоринга
+func validateDecisionRuleSet(ruleSet models.DecisionRuleSet) error {
+	if strings.TrimSpace(ruleSet.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if !decisionValues[ruleSet.Default] {
+		return fmt.Errorf("default must be one of approve, refer, decline")
+	}
+	for i, rule := range ruleSet.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("rules[%d]: name is required", i)
+		}
+		if !decisionValues[rule.Decision] {
+			return fmt.Errorf("rule %s: decision must be one of approve, refer, decline", rule.Name)
+		}
+		if len(rule.When) == 0 {
+			return fmt.Errorf("rule %s: at least one condition is required", rule.Name)
+		}
+		for _, condition := range rule.When {
+			if _, ok := decisionFields[condition.Field]; !ok {
+				return fmt.Errorf("rule %s: unknown field %s", rule.Name, condition.Field)
+			}
+			if err := validateDecisionOperand(condition); err != nil {
+				return fmt.Errorf("rule %s: %s %s: %v", rule.Name, condition.Field, condition.Op, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateDecisionOperand(condition models.DecisionCondition) error {
+	switch condition.Op {
+	case "eq", "ne", "gt", "gte", "lt", "lte":
+		switch condition.Value.(type) {
+		case string, float64, int, bool:
+			return nil
+		}
+		return fmt.Errorf("a single value is required")
+	case "in", "not_in":
+		if len(listStrings(condition.Value)) == 0 {
+			return fmt.Errorf("a non-empty list is required")
+		}
+		return nil
+	case "between":
+		if len(listStrings(condition.Value)) != 2 {
+			return fmt.Errorf("exactly two bounds are required")
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown operation")
+}
+
+func invalidDecisionRules(field string, err error) error {
+	return &models.ValidationError{Fields: []models.FieldError{{Field: field, Code: "INVALID_RULES", Message: err.Error()}}}
+}
+
+// normalizeDecisionRuleSet приводит значения из YAML к тому же виду, что даёт JSON
+func normalizeDecisionRuleSet(ruleSet *models.DecisionRuleSet) {
+	for i := range ruleSet.Rules {
+		for j := range ruleSet.Rules[i].When {
+			condition := &ruleSet.Rules[i].When[j]
+			condition.Value = normalizeDecisionValue(condition.Value)
+		}
+	}
+}
+
+func normalizeDecisionValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = normalizeDecisionValue(v[i])
+		}
+		return v
+	}
+	return value
+}
+
+func ListDecisionRuleSets(ctx context.Context) ([]models.DecisionRuleSet, error) {
+	return repositories.ListDecisionRuleSets(ctx)
+}
+
+func GetDecisionRuleSet(ctx context.Context, version int) (models.DecisionRuleSet, error) {
+	ruleSet, err := repositories.GetDecisionRuleSet(ctx, version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.DecisionRuleSet{}, ErrDecisionRuleSetNotFound
+	}
+	return ruleSet, err
+}
+
+// CreateDecisionRuleSet сохраняет правила новой версией; activate — сразу сделать её активной
+func CreateDecisionRuleSet(ctx context.Context, principal models.Principal, ruleSet models.DecisionRuleSet, activate bool) (models.DecisionRuleSet, error) {
+	normalizeDecisionRuleSet(&ruleSet)
+	if err := validateDecisionRuleSet(ruleSet); err != nil {
+		return models.DecisionRuleSet{}, invalidDecisionRules("rules", err)
+	}
+	ruleSet.CreatedBy = principal.UserName
+	ruleSet.Active = false
+	if err := repositories.CreateDecisionRuleSet(ctx, &ruleSet); err != nil {
+		return models.DecisionRuleSet{}, err
+	}
+	if activate {
+		if err := ActivateDecisionRuleSet(ctx, ruleSet.Version); err != nil {
+			return models.DecisionRuleSet{}, err
+		}
+		ruleSet.Active = true
+	}
+	return ruleSet, nil
+}
+
+func ActivateDecisionRuleSet(ctx context.Context, version int) error {
+	err := repositories.ActivateDecisionRuleSet(ctx, version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrDecisionRuleSetNotFound
+	}
+	if err != nil {
+		return err
+	}
+	resetActiveDecisionRules()
+	return nil
+}
+
+// DryRunDecisionRules прогоняет правила без сохранения результата: переданный набор,
+// указанную версию или активную — по фактам из запроса или из сохранённого запроса скоринга
+func DryRunDecisionRules(ctx context.Context, principal models.Principal, request models.DecisionDryRunRequest) (models.DecisionDryRunResponse, error) {
+	var ruleSet models.DecisionRuleSet
+	switch {
+	case request.RuleSet != nil:
+		ruleSet = *request.RuleSet
+		normalizeDecisionRuleSet(&ruleSet)
+		if err := validateDecisionRuleSet(ruleSet); err != nil {
+			return models.DecisionDryRunResponse{}, invalidDecisionRules("rule_set", err)
+		}
+	case request.Version != 0:
+		var err error
+		if ruleSet, err = GetDecisionRuleSet(ctx, request.Version); err != nil {
+			return models.DecisionDryRunResponse{}, err
+		}
+	default:
+		active, found, err := repositories.GetActiveDecisionRuleSet(ctx)
+		if err != nil {
+			return models.DecisionDryRunResponse{}, err
+		}
+		if !found {
+			return models.DecisionDryRunResponse{}, ErrNoActiveDecisionRules
+		}
+		ruleSet = active
+	}
+
+	facts := request.Facts
+	if request.InquiryId != 0 {
+		inquiry, err := GetScoreInquiryById(ctx, principal, request.InquiryId)
+		if err != nil {
+			return models.DecisionDryRunResponse{}, err
+		}
+		facts = decisionFacts(inquiry)
+	}
+	if facts == nil {
+		facts = map[string]string{}
+	}
+
+	decision, trace := evaluateDecisionRules(ruleSet, facts)
+	return models.DecisionDryRunResponse{Decision: decision, Facts: facts, Trace: trace}, nil
+}
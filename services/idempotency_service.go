@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/repositories"
+	"log"
+	"time"
+)
+
+var (
+	ErrIdempotencyKeyReused     = errors.New("Idempotency-Key was already used with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this Idempotency-Key is still in progress")
+)
+
+var idempotency struct {
+	ttl time.Duration
+	// Через сколько незавершённый запрос считается зависшим (например, экземпляр перезапустился)
+	staleAfter time.Duration
+}
+
+// StartIdempotency читает IDEMPOTENCY_TTL (сколько хранится ответ, по умолчанию 24h)
+// и запускает очистку истёкших ключей
+func StartIdempotency() {
+	idempotency.ttl = envDuration("IDEMPOTENCY_TTL", 24*time.Hour)
+	idempotency.staleAfter = envDuration("IDEMPOTENCY_LOCK_TIMEOUT", 5*time.Minute)
+	go func() {
+		for range time.Tick(10 * time.Minute) {
+			if err := repositories.DeleteExpiredIdempotencyKeys(context.Background()); err != nil {
+				log.Printf("failed to purge idempotency keys: %v", err)
+			}
+		}
+	}()
+}
+
+// BeginIdempotentRequest занимает ключ пользователя под запрос. Возвращает сохранённый ответ, если такой же
+// запрос уже выполнен, или nil, если запрос нужно выполнить (и затем вызвать Complete или Release).
+func BeginIdempotentRequest(ctx context.Context, principal models.Principal, key string, method string, path string, requestHash string) (*models.IdempotencyRecord, error) {
+	record := models.IdempotencyRecord{
+		UserId:      principal.UserId,
+		Key:         key,
+		Method:      method,
+		Path:        path,
+		RequestHash: requestHash,
+		ExpiresAt:   time.Now().Add(idempotency.ttl),
+	}
+	reserved, existing, err := repositories.ReserveIdempotencyKey(ctx, &record, idempotency.staleAfter)
+	if err != nil {
+		return nil, err
+	}
+	if reserved {
+		return nil, nil
+	}
+	if existing.Method != method || existing.Path != path || existing.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if existing.StatusCode == 0 {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	return &existing, nil
+}
+
+func CompleteIdempotentRequest(ctx context.Context, principal models.Principal, key string, statusCode int, contentType string, body []byte) {
+	if err := repositories.CompleteIdempotencyKey(ctx, principal.UserId, key, statusCode, contentType, body); err != nil {
+		log.Printf("failed to store idempotent response: %v", err)
+	}
+}
+
+func ReleaseIdempotentRequest(ctx context.Context, principal models.Principal, key string) {
+	if err := repositories.ReleaseIdempotencyKey(ctx, principal.UserId, key); err != nil {
+		log.Printf("failed to release idempotency key: %v", err)
+	}
+}
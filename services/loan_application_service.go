@@ -0,0 +1,302 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"go-keycloak-jwt/db"
+	"go-keycloak-jwt/iin"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/repositories"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrLoanApplicationNotFound = errors.New("loan application not found")
+	ErrLoanApplicationStatus   = errors.New("loan application status does not allow this action")
+)
+
+// Из каких статусов разрешён переход
+var loanStatusTransitions = map[string][]string{
+	models.LoanStatusScored:  {models.LoanStatusDraft, models.LoanStatusScored},
+	models.LoanStatusDecided: {models.LoanStatusScored},
+	models.LoanStatusClosed:  {models.LoanStatusDraft, models.LoanStatusScored, models.LoanStatusDecided},
+}
+
+// validateLoanApplication нормализует запрос и собирает ошибки по полям
+func validateLoanApplication(ctx context.Context, request *models.LoanApplicationRequest) error {
+	var fields []models.FieldError
+	request.ApplicantIin = strings.TrimSpace(request.ApplicantIin)
+	request.ApplicantName = strings.Join(strings.Fields(request.ApplicantName), " ")
+	request.Currency = strings.ToUpper(strings.TrimSpace(request.Currency))
+	if request.Currency == "" {
+		request.Currency = "KZT"
+	}
+
+	if _, err := iin.ParseIIN(request.ApplicantIin); err != nil {
+		fields = append(fields, models.FieldError{Field: "applicant_iin", Code: "INVALID_IIN", Message: err.Error()})
+	}
+	if request.ApplicantName == "" {
+		fields = append(fields, models.FieldError{Field: "applicant_name", Code: "REQUIRED", Message: "applicant_name is required"})
+	}
+	if len(request.Currency) != 3 {
+		fields = append(fields, models.FieldError{Field: "currency", Code: "INVALID_CURRENCY", Message: "currency must be an ISO 4217 code"})
+	}
+	_, err := repositories.GetCountryById(ctx, strconv.Itoa(request.CountryId))
+	if errors.Is(err, pgx.ErrNoRows) {
+		fields = append(fields, models.FieldError{Field: "country_id", Code: "UNKNOWN_COUNTRY", Message: "unknown country"})
+	} else if err != nil {
+		return fmt.Errorf("failed to check country: %v", err)
+	}
+
+	if len(fields) > 0 {
+		return &models.ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+func CreateLoanApplication(ctx context.Context, principal models.Principal, request models.LoanApplicationRequest) (models.LoanApplication, error) {
+	if err := validateLoanApplication(ctx, &request); err != nil {
+		return models.LoanApplication{}, err
+	}
+
+	application := models.LoanApplication{
+		ApplicantIin:  request.ApplicantIin,
+		ApplicantName: request.ApplicantName,
+		CountryId:     request.CountryId,
+		Amount:        request.Amount,
+		Currency:      request.Currency,
+		TermMonths:    request.TermMonths,
+		Status:        models.LoanStatusDraft,
+		UserId:        principal.UserId,
+		UserName:      principal.UserName,
+		Team:          principal.Team,
+	}
+	if err := repositories.CreateLoanApplication(ctx, &application); err != nil {
+		return models.LoanApplication{}, err
+	}
+	return getLoanApplication(ctx, principal, application.Id)
+}
+
+// ListLoanApplications — заявки с учётом прав: супервизор видит заявки своей команды, остальные — только свои
+func ListLoanApplications(ctx context.Context, principal models.Principal, filter models.LoanApplicationFilter) ([]models.LoanApplication, error) {
+	if principal.HasRole(models.RoleSupervisor) && principal.Team != "" {
+		filter.Team = principal.Team
+	} else {
+		filter.UserId = principal.UserId
+	}
+	return repositories.FindLoanApplications(ctx, filter)
+}
+
+func getLoanApplication(ctx context.Context, principal models.Principal, id int64) (models.LoanApplication, error) {
+	application, err := repositories.GetLoanApplicationById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.LoanApplication{}, ErrLoanApplicationNotFound
+	}
+	if err != nil {
+		return models.LoanApplication{}, err
+	}
+	// Права те же, что на историю скоринга: чужие заявки не раскрываем даже фактом существования
+	if application.UserId != principal.UserId &&
+		!(principal.HasRole(models.RoleSupervisor) && principal.Team != "" && application.Team == principal.Team) {
+		return models.LoanApplication{}, ErrLoanApplicationNotFound
+	}
+	return application, nil
+}
+
+// GetLoanApplication — заявка с привязанными запросами скоринга и историей статусов
+func GetLoanApplication(ctx context.Context, principal models.Principal, id int64) (models.LoanApplicationDetails, error) {
+	application, err := getLoanApplication(ctx, principal, id)
+	if err != nil {
+		return models.LoanApplicationDetails{}, err
+	}
+	scores, err := repositories.GetLoanApplicationScores(ctx, id)
+	if err != nil {
+		return models.LoanApplicationDetails{}, err
+	}
+	history, err := repositories.GetLoanApplicationHistory(ctx, id)
+	if err != nil {
+		return models.LoanApplicationDetails{}, err
+	}
+	return models.LoanApplicationDetails{LoanApplication: application, Scores: scores, History: history}, nil
+}
+
+// UpdateLoanApplication меняет данные заявки, пока она черновик
+func UpdateLoanApplication(ctx context.Context, principal models.Principal, id int64, request models.LoanApplicationRequest) (models.LoanApplication, error) {
+	application, err := getLoanApplication(ctx, principal, id)
+	if err != nil {
+		return models.LoanApplication{}, err
+	}
+	if err := validateLoanApplication(ctx, &request); err != nil {
+		return models.LoanApplication{}, err
+	}
+
+	application.ApplicantIin = request.ApplicantIin
+	application.ApplicantName = request.ApplicantName
+	application.CountryId = request.CountryId
+	application.Amount = request.Amount
+	application.Currency = request.Currency
+	application.TermMonths = request.TermMonths
+	updated, err := repositories.UpdateLoanApplication(ctx, application)
+	if err != nil {
+		return models.LoanApplication{}, err
+	}
+	if !updated {
+		return models.LoanApplication{}, ErrLoanApplicationStatus
+	}
+	return getLoanApplication(ctx, principal, id)
+}
+
+// DeleteLoanApplication удаляет черновик; поданную заявку можно только закрыть
+func DeleteLoanApplication(ctx context.Context, principal models.Principal, id int64) error {
+	if _, err := getLoanApplication(ctx, principal, id); err != nil {
+		return err
+	}
+	deleted, err := repositories.DeleteLoanApplication(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrLoanApplicationStatus
+	}
+	return nil
+}
+
+// ScoreLoanApplication выполняет скоринг заявителя по карте и привязывает запрос к заявке.
+// Успешный ответ бюро переводит заявку в scored; ошибки скоринга (согласие, квота, бюро) возвращаются как есть.
+// Запрос скоринга возвращается и при ошибке, если он был выполнен.
+func ScoreLoanApplication(ctx context.Context, tokenString string, principal models.Principal, id int64, request models.LoanApplicationScoreRequest) (models.LoanApplicationDetails, models.ScoreInquiry, error) {
+	application, err := getLoanApplication(ctx, principal, id)
+	if err != nil {
+		return models.LoanApplicationDetails{}, models.ScoreInquiry{}, err
+	}
+	// Статус проверяется до платного запроса; окончательно — при переходе в транзакции
+	if !containsString(loanStatusTransitions[models.LoanStatusScored], application.Status) {
+		return models.LoanApplicationDetails{}, models.ScoreInquiry{}, ErrLoanApplicationStatus
+	}
+
+	var score models.ScoreRequest
+	score.Score.ScoreCard = strings.TrimSpace(request.ScoreCard)
+	score.Score.Attributes.Name = "IIN"
+	score.Score.Attributes.Value = application.ApplicantIin
+	_, inquiry, err := Score(ctx, tokenString, principal, score, ScoreOptions{})
+	if err != nil {
+		return models.LoanApplicationDetails{}, inquiry, err
+	}
+
+	if err := linkLoanApplicationScore(ctx, principal, id, inquiry); err != nil {
+		return models.LoanApplicationDetails{}, inquiry, err
+	}
+	details, err := GetLoanApplication(ctx, principal, id)
+	return details, inquiry, err
+}
+
+// LinkLoanApplicationScore привязывает уже выполненный запрос скоринга того же заявителя
+func LinkLoanApplicationScore(ctx context.Context, principal models.Principal, id int64, inquiryId int64) (models.LoanApplicationDetails, error) {
+	application, err := getLoanApplication(ctx, principal, id)
+	if err != nil {
+		return models.LoanApplicationDetails{}, err
+	}
+	if !containsString(loanStatusTransitions[models.LoanStatusScored], application.Status) {
+		return models.LoanApplicationDetails{}, ErrLoanApplicationStatus
+	}
+	inquiry, err := GetScoreInquiryById(ctx, principal, inquiryId)
+	if err != nil {
+		return models.LoanApplicationDetails{}, err
+	}
+	if inquiry.SubjectIin != application.ApplicantIin {
+		return models.LoanApplicationDetails{}, &models.ValidationError{Fields: []models.FieldError{{
+			Field: "inquiry_id", Code: "SUBJECT_MISMATCH", Message: "score inquiry is for a different subject",
+		}}}
+	}
+
+	if err := linkLoanApplicationScore(ctx, principal, id, inquiry); err != nil {
+		return models.LoanApplicationDetails{}, err
+	}
+	return GetLoanApplication(ctx, principal, id)
+}
+
+func linkLoanApplicationScore(ctx context.Context, principal models.Principal, id int64, inquiry models.ScoreInquiry) error {
+	return withLoanApplicationTx(ctx, func(tx pgx.Tx) error {
+		if inquiry.ErrorCode == "0" {
+			changed, err := repositories.TransitionLoanApplication(ctx, tx, id, loanStatusTransitions[models.LoanStatusScored],
+				models.LoanApplicationStatusChange{
+					ToStatus:  models.LoanStatusScored,
+					Comment:   fmt.Sprintf("score inquiry %d", inquiry.Id),
+					ChangedBy: principal.UserName,
+				}, "")
+			if err != nil {
+				return err
+			}
+			if !changed {
+				return ErrLoanApplicationStatus
+			}
+		}
+		return repositories.LinkLoanApplicationScore(ctx, tx, id, inquiry.Id, principal.UserName)
+	})
+}
+
+// DecideLoanApplication фиксирует решение по оценённой заявке
+func DecideLoanApplication(ctx context.Context, principal models.Principal, id int64, request models.LoanApplicationDecisionRequest) (models.LoanApplicationDetails, error) {
+	change := models.LoanApplicationStatusChange{
+		ToStatus:  models.LoanStatusDecided,
+		Comment:   strings.TrimSpace(request.Comment),
+		ChangedBy: principal.UserName,
+	}
+	if err := transitionLoanApplication(ctx, principal, id, change, request.Decision); err != nil {
+		return models.LoanApplicationDetails{}, err
+	}
+	return GetLoanApplication(ctx, principal, id)
+}
+
+// CloseLoanApplication закрывает заявку на любом этапе; закрытая заявка не меняется
+func CloseLoanApplication(ctx context.Context, principal models.Principal, id int64, comment string) (models.LoanApplicationDetails, error) {
+	change := models.LoanApplicationStatusChange{
+		ToStatus:  models.LoanStatusClosed,
+		Comment:   strings.TrimSpace(comment),
+		ChangedBy: principal.UserName,
+	}
+	if err := transitionLoanApplication(ctx, principal, id, change, ""); err != nil {
+		return models.LoanApplicationDetails{}, err
+	}
+	return GetLoanApplication(ctx, principal, id)
+}
+
+func transitionLoanApplication(ctx context.Context, principal models.Principal, id int64, change models.LoanApplicationStatusChange, decision string) error {
+	if _, err := getLoanApplication(ctx, principal, id); err != nil {
+		return err
+	}
+	return withLoanApplicationTx(ctx, func(tx pgx.Tx) error {
+		changed, err := repositories.TransitionLoanApplication(ctx, tx, id, loanStatusTransitions[change.ToStatus], change, decision)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			return ErrLoanApplicationStatus
+		}
+		return nil
+	})
+}
+
+func withLoanApplicationTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err := fn(tx); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrLoanApplicationNotFound
+		}
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit loan application: %v", err)
+	}
+	return nil
+}
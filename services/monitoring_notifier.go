@@ -0,0 +1,147 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/pii"
+	"go-keycloak-jwt/repositories"
+	"io"
+	"log"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// MonitoringNotifier доставляет оповещения мониторинга портфеля.
+// ИИН в оповещении уже замаскирован: полные данные доступны по inquiry_id в сервисе.
+type MonitoringNotifier interface {
+	Notify(ctx context.Context, alert models.MonitoringAlert) error
+}
+
+var monitoringNotifier MonitoringNotifier = logMonitoringNotifier{}
+
+// InitMonitoringNotifier выбирает способ доставки по MONITORING_NOTIFIER: log (по умолчанию), webhook или smtp.
+// Для локальной работы webhook можно направить на любой HTTP-приёмник, а smtp — на тестовый почтовый сервер.
+func InitMonitoringNotifier() {
+	switch os.Getenv("MONITORING_NOTIFIER") {
+	case "", "log":
+		monitoringNotifier = logMonitoringNotifier{}
+	case "webhook":
+		url := os.Getenv("MONITORING_WEBHOOK_URL")
+		if url == "" {
+			log.Fatalf("MONITORING_WEBHOOK_URL is required for MONITORING_NOTIFIER=webhook")
+		}
+		monitoringNotifier = &webhookMonitoringNotifier{
+			url:        url,
+			secret:     []byte(os.Getenv("MONITORING_WEBHOOK_SECRET")),
+			httpClient: &http.Client{Timeout: envDuration("MONITORING_WEBHOOK_TIMEOUT", 10*time.Second)},
+		}
+	case "smtp":
+		notifier := &smtpMonitoringNotifier{
+			addr: os.Getenv("MONITORING_SMTP_ADDR"),
+			from: os.Getenv("MONITORING_SMTP_FROM"),
+		}
+		for _, to := range strings.Split(os.Getenv("MONITORING_SMTP_TO"), ",") {
+			if to = strings.TrimSpace(to); to != "" {
+				notifier.to = append(notifier.to, to)
+			}
+		}
+		if notifier.addr == "" || notifier.from == "" || len(notifier.to) == 0 {
+			log.Fatalf("MONITORING_SMTP_ADDR, MONITORING_SMTP_FROM and MONITORING_SMTP_TO are required for MONITORING_NOTIFIER=smtp")
+		}
+		if user := os.Getenv("MONITORING_SMTP_USER"); user != "" {
+			host, _, _ := strings.Cut(notifier.addr, ":")
+			notifier.auth = smtp.PlainAuth("", user, os.Getenv("MONITORING_SMTP_PASSWORD"), host)
+		}
+		monitoringNotifier = notifier
+	default:
+		log.Fatalf("unknown MONITORING_NOTIFIER: %s", os.Getenv("MONITORING_NOTIFIER"))
+	}
+}
+
+// logMonitoringNotifier только пишет оповещение в журнал
+type logMonitoringNotifier struct{}
+
+func (logMonitoringNotifier) Notify(_ context.Context, alert models.MonitoringAlert) error {
+	log.Printf("monitoring alert %d [%s] %s", alert.Id, alert.WatchlistName, alert.Message)
+	return nil
+}
+
+// webhookMonitoringNotifier отправляет оповещение POST-запросом в JSON.
+// С MONITORING_WEBHOOK_SECRET тело подписывается HMAC-SHA256 в заголовке X-Signature (hex).
+type webhookMonitoringNotifier struct {
+	url        string
+	secret     []byte
+	httpClient *http.Client
+}
+
+func (w *webhookMonitoringNotifier) Notify(ctx context.Context, alert models.MonitoringAlert) error {
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(w.secret) > 0 {
+		mac := hmac.New(sha256.New, w.secret)
+		mac.Write(payload)
+		req.Header.Set("X-Signature", hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// smtpMonitoringNotifier отправляет оповещение письмом
+type smtpMonitoringNotifier struct {
+	addr string
+	from string
+	to   []string
+	auth smtp.Auth
+}
+
+func (s *smtpMonitoringNotifier) Notify(_ context.Context, alert models.MonitoringAlert) error {
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", s.from)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(s.to, ", "))
+	fmt.Fprintf(&message, "Subject: [%s] %s\r\n", alert.WatchlistName, alert.Rule)
+	message.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&message, "%s\r\n\r\n", alert.Message)
+	fmt.Fprintf(&message, "Список: %s\r\nКарта: %s\r\nСубъект: %s\r\n", alert.WatchlistName, alert.ScoreCard, alert.SubjectIin)
+	fmt.Fprintf(&message, "%s: %s -> %s\r\n", alert.Field, alert.PreviousValue, alert.CurrentValue)
+	fmt.Fprintf(&message, "Запросы: %d -> %d\r\n", alert.PreviousInquiryId, alert.InquiryId)
+	return smtp.SendMail(s.addr, s.auth, s.from, s.to, message.Bytes())
+}
+
+// deliverMonitoringAlert отправляет оповещение с замаскированным ИИН и сохраняет результат попытки
+func deliverMonitoringAlert(ctx context.Context, alert models.MonitoringAlert) {
+	deliveryError := ""
+	if err := monitoringNotifier.Notify(ctx, pii.Mask(alert)); err != nil {
+		deliveryError = pii.RedactText(err.Error())
+		log.Printf("failed to deliver monitoring alert %d: %s", alert.Id, deliveryError)
+	}
+	if err := repositories.SetMonitoringAlertDelivery(ctx, alert.Id, deliveryError); err != nil {
+		log.Printf("failed to save monitoring alert %d delivery: %v", alert.Id, err)
+	}
+}
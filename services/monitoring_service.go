@@ -0,0 +1,572 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"go-keycloak-jwt/iin"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/pd"
+	"go-keycloak-jwt/pii"
+	"go-keycloak-jwt/repositories"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrWatchlistNotFound  = errors.New("watchlist not found")
+	ErrWatchlistNameTaken = errors.New("watchlist with this name already exists")
+)
+
+// Сколько раз пытаться доставить оповещение, прежде чем оставить его недоставленным
+const monitoringAlertMaxAttempts = 5
+
+const minWatchlistInterval = time.Hour
+
+// Шкала классов риска от лучшего к худшему и пороги PD-диапазонов; задаются MONITORING_RISK_GRADES
+// и MONITORING_PD_BANDS
+var monitoringScale struct {
+	grades  map[string]int
+	pdBands []float64
+}
+
+// Списки, перескоринг которых уже идёт в этом экземпляре
+var runningWatchlists sync.Map
+
+// StartMonitoring запускает планировщик перескоринга списков наблюдения и повторную доставку оповещений.
+// Вызывается после StartScoreBatchWorkers: запросы в бюро делят с пакетами общий лимит BUREAU_RATE_LIMIT.
+// MONITORING_SCHEDULER=off отключает планировщик на экземпляре (API списков остаётся).
+func StartMonitoring() {
+	InitMonitoringNotifier()
+
+	monitoringScale.grades = map[string]int{}
+	for i, grade := range riskGradeScale() {
+		monitoringScale.grades[grade] = i
+	}
+
+	bands := os.Getenv("MONITORING_PD_BANDS")
+	if bands == "" {
+		bands = defaultPdBands
+	}
+	pdBands, err := parsePdBands(bands)
+	if err != nil {
+		log.Fatalf("invalid MONITORING_PD_BANDS: %v", err)
+	}
+	monitoringScale.pdBands = pdBands
+
+	if os.Getenv("MONITORING_SCHEDULER") == "off" {
+		return
+	}
+	poll := envDuration("MONITORING_POLL", time.Minute)
+	go func() {
+		for {
+			runDueWatchlists()
+			retryMonitoringAlerts()
+			time.Sleep(poll)
+		}
+	}()
+}
+
+func runDueWatchlists() {
+	ctx := context.Background()
+	watchlists, err := repositories.FindDueWatchlists(ctx, time.Now())
+	if err != nil {
+		log.Printf("failed to load due watchlists: %v", err)
+		return
+	}
+	for _, watchlist := range watchlists {
+		interval, err := parseWatchlistInterval(watchlist.Interval)
+		if err != nil {
+			log.Printf("watchlist %d: %v", watchlist.Id, err)
+			continue
+		}
+		if _, running := runningWatchlists.LoadOrStore(watchlist.Id, true); running {
+			continue
+		}
+		// Следующий запуск переносим до перескоринга, чтобы другой экземпляр не взял тот же список
+		claimed, err := repositories.ClaimWatchlistRun(ctx, watchlist.Id, watchlist.NextRunAt, time.Now().Add(interval))
+		if err != nil || !claimed {
+			if err != nil {
+				log.Printf("failed to claim watchlist %d: %v", watchlist.Id, err)
+			}
+			runningWatchlists.Delete(watchlist.Id)
+			continue
+		}
+		go func(watchlist models.Watchlist) {
+			defer runningWatchlists.Delete(watchlist.Id)
+			rescoreWatchlist(watchlist)
+		}(watchlist)
+	}
+}
+
+// riskGradeScale — классы риска от лучшего к худшему (MONITORING_RISK_GRADES);
+// общая шкала для оповещений мониторинга и аналитики расхождений
+func riskGradeScale() []string {
+	grades := os.Getenv("MONITORING_RISK_GRADES")
+	if grades == "" {
+		grades = "A1,A2,A3,B1,B2,B3,C1,C2,C3,D1,D2,D3,E1,E2,E3"
+	}
+	var scale []string
+	for _, grade := range strings.Split(grades, ",") {
+		scale = append(scale, strings.ToUpper(strings.TrimSpace(grade)))
+	}
+	return scale
+}
+
+func retryMonitoringAlerts() {
+	ctx := context.Background()
+	alerts, err := repositories.FindUndeliveredMonitoringAlerts(ctx, monitoringAlertMaxAttempts, 100)
+	if err != nil {
+		log.Printf("failed to load undelivered monitoring alerts: %v", err)
+		return
+	}
+	for _, alert := range alerts {
+		deliverMonitoringAlert(ctx, alert)
+	}
+}
+
+// rescoreWatchlist перескоривает всех субъектов списка от имени его автора: с его учётными данными бюро
+// и в счёт его квоты. Списки, созданные до сохранения автора, перескориваются от имени пользователя monitoring
+func rescoreWatchlist(watchlist models.Watchlist) {
+	ctx := context.Background()
+	members, err := repositories.GetWatchlistMembers(ctx, watchlist.Id)
+	if err != nil {
+		log.Printf("failed to load watchlist %d members: %v", watchlist.Id, err)
+		return
+	}
+
+	userId := watchlist.UserId
+	if userId == "" {
+		userId = "monitoring"
+	}
+	principal := models.Principal{
+		UserId:   userId,
+		UserName: "monitoring:" + watchlist.Name,
+		Team:     watchlist.Team,
+		Groups:   watchlist.Groups,
+		Roles:    watchlist.Roles,
+	}
+	for _, member := range members {
+		rescoreWatchlistMember(ctx, principal, watchlist, member)
+	}
+}
+
+func rescoreWatchlistMember(ctx context.Context, principal models.Principal, watchlist models.Watchlist, member models.WatchlistMember) {
+	if err := scoreBatchLimiter.Wait(ctx); err != nil {
+		log.Printf("watchlist %d member %d: rate limiter: %v", watchlist.Id, member.Id, err)
+		return
+	}
+
+	var request models.ScoreRequest
+	request.Score.ScoreCard = watchlist.ScoreCard
+	request.Score.Attributes.Name = "IIN"
+	if iin.IsBIN(member.SubjectIin) {
+		request.Score.Attributes.Name = "BIN"
+	}
+	request.Score.Attributes.Value = member.SubjectIin
+
+	// Перескоринг должен видеть свежие данные бюро, а не кэш
+	_, inquiry, err := Score(ctx, "", principal, request, ScoreOptions{ForceRefresh: true})
+	if err == nil && inquiry.ErrorCode != "0" {
+		err = fmt.Errorf("bureau error %s: %s", inquiry.ErrorCode, inquiry.ErrorString)
+	}
+	if err != nil {
+		if saveErr := repositories.UpdateWatchlistMemberResult(ctx, member.Id, nil, pii.RedactText(err.Error())); saveErr != nil {
+			log.Printf("watchlist %d member %d: failed to save result: %v", watchlist.Id, member.Id, saveErr)
+		}
+		return
+	}
+
+	previousId, found := int64(0), false
+	if member.LastInquiryId != nil {
+		previousId, found = *member.LastInquiryId, true
+	} else if previousId, found, err = repositories.FindLatestSuccessfulScoreInquiryId(ctx, inquiry.SubjectIin, inquiry.ScoreCard, inquiry.Id); err != nil {
+		log.Printf("watchlist %d member %d: failed to find previous result: %v", watchlist.Id, member.Id, err)
+	}
+	if found {
+		previous, err := repositories.GetScoreInquiryById(ctx, previousId)
+		if err != nil {
+			log.Printf("watchlist %d member %d: failed to load previous result: %v", watchlist.Id, member.Id, err)
+		} else {
+			raiseMonitoringAlerts(ctx, watchlist, member, previous, inquiry)
+		}
+	}
+
+	if err := repositories.UpdateWatchlistMemberResult(ctx, member.Id, &inquiry.Id, ""); err != nil {
+		log.Printf("watchlist %d member %d: failed to save result: %v", watchlist.Id, member.Id, err)
+	}
+}
+
+func raiseMonitoringAlerts(ctx context.Context, watchlist models.Watchlist, member models.WatchlistMember, previous models.ScoreInquiry, current models.ScoreInquiry) {
+	for _, rule := range watchlist.AlertRules {
+		alert, triggered := evaluateMonitoringAlertRule(rule, previous, current)
+		if !triggered {
+			continue
+		}
+		alert.WatchlistId = watchlist.Id
+		alert.WatchlistName = watchlist.Name
+		alert.MemberId = member.Id
+		alert.SubjectIin = member.SubjectIin
+		alert.ScoreCard = watchlist.ScoreCard
+		alert.PreviousInquiryId = previous.Id
+		alert.InquiryId = current.Id
+		if err := repositories.CreateMonitoringAlert(ctx, &alert); err != nil {
+			log.Printf("watchlist %d member %d: failed to save alert: %v", watchlist.Id, member.Id, err)
+			continue
+		}
+		deliverMonitoringAlert(ctx, alert)
+	}
+}
+
+// evaluateMonitoringAlertRule сравнивает новый результат с предыдущим; значения, которые нельзя
+// сравнить (неизвестный класс, не число), правило не срабатывает
+func evaluateMonitoringAlertRule(rule models.MonitoringAlertRule, previous models.ScoreInquiry, current models.ScoreInquiry) (models.MonitoringAlert, bool) {
+	rule = withMonitoringRuleDefaults(rule)
+	before := decisionFields[rule.Field](previous)
+	after := decisionFields[rule.Field](current)
+	alert := models.MonitoringAlert{Rule: rule.Type, Field: rule.Field, PreviousValue: before, CurrentValue: after}
+
+	switch rule.Type {
+	case models.AlertRuleRiskGradeWorsened:
+		from, okFrom := monitoringScale.grades[strings.ToUpper(strings.TrimSpace(before))]
+		to, okTo := monitoringScale.grades[strings.ToUpper(strings.TrimSpace(after))]
+		if !okFrom || !okTo || to-from < rule.Notches {
+			return alert, false
+		}
+		alert.Message = fmt.Sprintf("%s worsened from %s to %s (%d notches)", rule.Field, before, after, to-from)
+	case models.AlertRulePdBandIncreased:
+		from, errFrom := pdBand(before)
+		to, errTo := pdBand(after)
+		if errFrom != nil || errTo != nil || to-from < rule.Notches {
+			return alert, false
+		}
+		alert.Message = fmt.Sprintf("%s moved up from band %d to band %d (%s -> %s)", rule.Field, from, to, before, after)
+	case models.AlertRuleScoreDropped:
+		from, errFrom := parseDecisionNumber(before)
+		to, errTo := parseDecisionNumber(after)
+		if errFrom != nil || errTo != nil || from-to < rule.Points {
+			return alert, false
+		}
+		alert.Message = fmt.Sprintf("%s dropped by %s (%s -> %s)", rule.Field,
+			strconv.FormatFloat(from-to, 'f', -1, 64), before, after)
+	default:
+		return alert, false
+	}
+	return alert, true
+}
+
+// Пороги PD-диапазонов по умолчанию в долях единицы
+const defaultPdBands = "0.01,0.03,0.05,0.1,0.2,0.5"
+
+// parsePdBands разбирает пороги MONITORING_PD_BANDS: доли ("0.03") или проценты ("3%")
+func parsePdBands(value string) ([]float64, error) {
+	var bands []float64
+	for _, band := range strings.Split(value, ",") {
+		threshold, err := pd.Parse(band)
+		if err != nil {
+			return nil, fmt.Errorf("%q %v", strings.TrimSpace(band), err)
+		}
+		bands = append(bands, threshold)
+	}
+	return bands, nil
+}
+
+// pdBand — номер PD-диапазона: число порогов MONITORING_PD_BANDS, которые PD превышает.
+// PD бюро — диапазон, берётся его верхняя граница (см. pd.Parse): "2% - 3%" при порогах 1% и 3% — диапазон 1
+func pdBand(value string) (int, error) {
+	probability, err := pd.Parse(value)
+	if err != nil {
+		return 0, err
+	}
+	band := 0
+	for _, threshold := range monitoringScale.pdBands {
+		if probability > threshold {
+			band++
+		}
+	}
+	return band, nil
+}
+
+// Поле по умолчанию и допустимые поля для каждого типа правила
+var monitoringRuleFields = map[string][]string{
+	models.AlertRuleRiskGradeWorsened: {"RiskGrade", "RiskGradeByML"},
+	models.AlertRulePdBandIncreased:   {"OneYearProbabilityOfDefault", "OneYearProbabilityOfDefaultByML"},
+	models.AlertRuleScoreDropped:      {"Score", "ScoreByML"},
+}
+
+func withMonitoringRuleDefaults(rule models.MonitoringAlertRule) models.MonitoringAlertRule {
+	if fields, ok := monitoringRuleFields[rule.Type]; ok && rule.Field == "" {
+		rule.Field = fields[0]
+	}
+	if rule.Notches <= 0 {
+		rule.Notches = 1
+	}
+	return rule
+}
+
+// parseWatchlistInterval принимает длительность Go ("720h") или число дней ("30d")
+func parseWatchlistInterval(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	var interval time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid interval %q", value)
+		}
+		interval = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if interval, err = time.ParseDuration(value); err != nil {
+			return 0, fmt.Errorf("invalid interval %q", value)
+		}
+	}
+	if interval < minWatchlistInterval {
+		return 0, fmt.Errorf("interval must be at least %s", minWatchlistInterval)
+	}
+	return interval, nil
+}
+
+// validateWatchlist нормализует запрос и собирает ошибки по полям
+func validateWatchlist(ctx context.Context, principal models.Principal, request *models.WatchlistRequest) error {
+	var fields []models.FieldError
+	request.Name = strings.TrimSpace(request.Name)
+	request.ScoreCard = strings.TrimSpace(request.ScoreCard)
+
+	if request.Name == "" {
+		fields = append(fields, models.FieldError{Field: "name", Code: "REQUIRED", Message: "name is required"})
+	}
+	if _, found, ok := findScoreCard(ctx, "", principal, request.ScoreCard); ok && !found {
+		fields = append(fields, models.FieldError{Field: "score_card", Code: "UNKNOWN_SCORE_CARD", Message: "unknown score card"})
+	}
+	if _, err := parseWatchlistInterval(request.Interval); err != nil {
+		fields = append(fields, models.FieldError{Field: "interval", Code: "INVALID_INTERVAL", Message: err.Error()})
+	}
+
+	for i, rule := range request.AlertRules {
+		field := fmt.Sprintf("alert_rules[%d]", i)
+		allowed, ok := monitoringRuleFields[rule.Type]
+		if !ok {
+			fields = append(fields, models.FieldError{Field: field + ".type", Code: "UNKNOWN_RULE",
+				Message: "type must be risk_grade_worsened, pd_band_increased or score_dropped"})
+			continue
+		}
+		request.AlertRules[i] = withMonitoringRuleDefaults(rule)
+		if !containsString(allowed, request.AlertRules[i].Field) {
+			fields = append(fields, models.FieldError{Field: field + ".field", Code: "INVALID_FIELD",
+				Message: "field must be one of " + strings.Join(allowed, ", ")})
+		}
+		if rule.Type == models.AlertRuleScoreDropped && rule.Points <= 0 {
+			fields = append(fields, models.FieldError{Field: field + ".points", Code: "INVALID_POINTS",
+				Message: "points must be positive"})
+		}
+	}
+
+	subjects, subjectFields := normalizeWatchlistSubjects("subject_iins", request.SubjectIins)
+	request.SubjectIins = subjects
+	fields = append(fields, subjectFields...)
+
+	if len(fields) > 0 {
+		return &models.ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// normalizeWatchlistSubjects проверяет ИИН/БИН и убирает повторы
+func normalizeWatchlistSubjects(field string, values []string) ([]string, []models.FieldError) {
+	var fields []models.FieldError
+	seen := map[string]bool{}
+	subjects := []string{}
+	for i, value := range values {
+		subject := strings.TrimSpace(value)
+		var err error
+		if iin.IsBIN(subject) {
+			_, err = iin.ParseBIN(subject)
+		} else {
+			_, err = iin.ParseIIN(subject)
+		}
+		if err != nil {
+			fields = append(fields, models.FieldError{Field: fmt.Sprintf("%s[%d]", field, i), Code: "INVALID_SUBJECT",
+				Message: err.Error()})
+			continue
+		}
+		if !seen[subject] {
+			seen[subject] = true
+			subjects = append(subjects, subject)
+		}
+	}
+	return subjects, fields
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateWatchlist создаёт список; первый перескоринг — на ближайшем проходе планировщика.
+// Первый результат сравнивается с последним успешным запросом субъекта по той же карте, если он есть.
+func CreateWatchlist(ctx context.Context, principal models.Principal, request models.WatchlistRequest) (models.Watchlist, error) {
+	if err := validateWatchlist(ctx, principal, &request); err != nil {
+		return models.Watchlist{}, err
+	}
+
+	watchlist := models.Watchlist{
+		Name:       request.Name,
+		ScoreCard:  request.ScoreCard,
+		Interval:   strings.TrimSpace(request.Interval),
+		AlertRules: request.AlertRules,
+		Active:     request.Active == nil || *request.Active,
+		Team:       principal.Team,
+		CreatedBy:  principal.UserName,
+		UserId:     principal.UserId,
+		Groups:     principal.Groups,
+		Roles:      principal.Roles,
+		NextRunAt:  time.Now(),
+	}
+	if watchlist.AlertRules == nil {
+		watchlist.AlertRules = []models.MonitoringAlertRule{}
+	}
+	if err := repositories.CreateWatchlist(ctx, &watchlist, request.SubjectIins); err != nil {
+		if isUniqueViolation(err) {
+			return models.Watchlist{}, ErrWatchlistNameTaken
+		}
+		return models.Watchlist{}, err
+	}
+	return watchlist, nil
+}
+
+// UpdateWatchlist меняет настройки списка; при смене периодичности следующий запуск пересчитывается
+// от последнего. Состав меняется через AddWatchlistMembers и RemoveWatchlistMember.
+func UpdateWatchlist(ctx context.Context, principal models.Principal, id int64, request models.WatchlistRequest) (models.Watchlist, error) {
+	watchlist, err := GetWatchlist(ctx, id)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+	request.SubjectIins = nil
+	if err := validateWatchlist(ctx, principal, &request); err != nil {
+		return models.Watchlist{}, err
+	}
+
+	current := watchlist.Watchlist
+	if interval := strings.TrimSpace(request.Interval); interval != current.Interval {
+		parsed, _ := parseWatchlistInterval(interval)
+		from := current.CreatedAt
+		if current.LastRunAt != nil {
+			from = *current.LastRunAt
+		}
+		current.Interval = interval
+		current.NextRunAt = from.Add(parsed)
+	}
+	current.Name = request.Name
+	current.ScoreCard = request.ScoreCard
+	current.AlertRules = request.AlertRules
+	if current.AlertRules == nil {
+		current.AlertRules = []models.MonitoringAlertRule{}
+	}
+	if request.Active != nil {
+		current.Active = *request.Active
+	}
+
+	updated, err := repositories.UpdateWatchlist(ctx, current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Watchlist{}, ErrWatchlistNotFound
+	}
+	if isUniqueViolation(err) {
+		return models.Watchlist{}, ErrWatchlistNameTaken
+	}
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	// Результат новой карты не сравнивается с результатом старой: иначе ложные оповещения
+	if updated.ScoreCard != watchlist.ScoreCard {
+		if err := repositories.ResetWatchlistMemberResults(ctx, id); err != nil {
+			return models.Watchlist{}, err
+		}
+	}
+	return updated, nil
+}
+
+func ListWatchlists(ctx context.Context) ([]models.Watchlist, error) {
+	return repositories.ListWatchlists(ctx)
+}
+
+func GetWatchlist(ctx context.Context, id int64) (models.WatchlistDetails, error) {
+	watchlist, err := repositories.GetWatchlistById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.WatchlistDetails{}, ErrWatchlistNotFound
+	}
+	if err != nil {
+		return models.WatchlistDetails{}, err
+	}
+	members, err := repositories.GetWatchlistMembers(ctx, id)
+	if err != nil {
+		return models.WatchlistDetails{}, err
+	}
+	return models.WatchlistDetails{Watchlist: watchlist, MemberList: members}, nil
+}
+
+func DeleteWatchlist(ctx context.Context, id int64) error {
+	deleted, err := repositories.DeleteWatchlist(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrWatchlistNotFound
+	}
+	return nil
+}
+
+// AddWatchlistMembers добавляет субъектов в список и возвращает число новых
+func AddWatchlistMembers(ctx context.Context, id int64, subjectIins []string) (int, error) {
+	if _, err := GetWatchlist(ctx, id); err != nil {
+		return 0, err
+	}
+	subjects, fields := normalizeWatchlistSubjects("subject_iins", subjectIins)
+	if len(fields) > 0 {
+		return 0, &models.ValidationError{Fields: fields}
+	}
+	return repositories.AddWatchlistMembers(ctx, id, subjects)
+}
+
+func RemoveWatchlistMember(ctx context.Context, id int64, subjectIin string) error {
+	removed, err := repositories.RemoveWatchlistMember(ctx, id, strings.TrimSpace(subjectIin))
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrWatchlistNotFound
+	}
+	return nil
+}
+
+// RunWatchlistNow ставит перескоринг списка на ближайший проход планировщика
+func RunWatchlistNow(ctx context.Context, id int64) error {
+	err := repositories.ScheduleWatchlistNow(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrWatchlistNotFound
+	}
+	return err
+}
+
+func GetMonitoringAlerts(ctx context.Context, filter models.MonitoringAlertFilter) ([]models.MonitoringAlert, error) {
+	if filter.Limit <= 0 || filter.Limit > 500 {
+		filter.Limit = 100
+	}
+	filter.SubjectIin = strings.TrimSpace(filter.SubjectIin)
+	return repositories.FindMonitoringAlerts(ctx, filter)
+}
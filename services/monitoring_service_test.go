@@ -0,0 +1,56 @@
+package services
+
+import (
+	"go-keycloak-jwt/models"
+	"testing"
+)
+
+func TestPdBand(t *testing.T) {
+	bands, err := parsePdBands(defaultPdBands)
+	if err != nil {
+		t.Fatalf("parsePdBands error = %v", err)
+	}
+	monitoringScale.pdBands = bands
+
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{value: "0% - 1%", want: 0},
+		{value: "1% - 2%", want: 1},
+		{value: "2% - 3%", want: 1},
+		{value: "3% - 5%", want: 2},
+		{value: "10% - 15%", want: 4},
+		{value: "50% - 100%", want: 6},
+		{value: "0.04", want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := pdBand(tt.value)
+			if err != nil {
+				t.Fatalf("pdBand(%q) error = %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("pdBand(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPdBandIncreasedAlert(t *testing.T) {
+	bands, err := parsePdBands("1%,3%,5%")
+	if err != nil {
+		t.Fatalf("parsePdBands error = %v", err)
+	}
+	monitoringScale.pdBands = bands
+
+	rule := models.MonitoringAlertRule{Type: models.AlertRulePdBandIncreased}
+	previous := models.ScoreInquiry{OneYearProbabilityOfDefault: "2% - 3%"}
+	current := models.ScoreInquiry{OneYearProbabilityOfDefault: "3% - 5%"}
+	if _, triggered := evaluateMonitoringAlertRule(rule, previous, current); !triggered {
+		t.Error("pd_band_increased did not fire for 2% - 3% -> 3% - 5%")
+	}
+	if _, triggered := evaluateMonitoringAlertRule(rule, current, previous); triggered {
+		t.Error("pd_band_increased fired for an improving PD")
+	}
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/repositories"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Код ошибки в ответе 429 при исчерпанной квоте
+const QuotaExceededCode = "QUOTA_EXCEEDED"
+
+var (
+	ErrScoreQuotaNotFound  = errors.New("score quota not found")
+	ErrInvalidBillingMonth = errors.New("month must be in YYYY-MM format")
+)
+
+// Границы суток и месяцев для квот и счетов считаются в QUOTA_TIMEZONE (по умолчанию Asia/Almaty)
+var quotaLocation = loadQuotaLocation()
+
+func loadQuotaLocation() *time.Location {
+	name := os.Getenv("QUOTA_TIMEZONE")
+	if name == "" {
+		name = "Asia/Almaty"
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("failed to load QUOTA_TIMEZONE %s, using UTC+5: %v", name, err)
+		return time.FixedZone("UTC+5", 5*60*60)
+	}
+	return location
+}
+
+// Квоты кэшируются так же, как правила решения: изменения через API видны сразу,
+// на других экземплярах — через QUOTA_REFRESH
+var scoreQuotas struct {
+	mu       sync.Mutex
+	quotas   []models.ScoreQuota
+	loadedAt time.Time
+}
+
+func loadScoreQuotas(ctx context.Context) ([]models.ScoreQuota, error) {
+	scoreQuotas.mu.Lock()
+	defer scoreQuotas.mu.Unlock()
+
+	if !scoreQuotas.loadedAt.IsZero() && time.Since(scoreQuotas.loadedAt) < envDuration("QUOTA_REFRESH", 30*time.Second) {
+		return scoreQuotas.quotas, nil
+	}
+	quotas, err := repositories.ListScoreQuotas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	scoreQuotas.quotas = quotas
+	scoreQuotas.loadedAt = time.Now()
+	return quotas, nil
+}
+
+func resetScoreQuotas() {
+	scoreQuotas.mu.Lock()
+	scoreQuotas.loadedAt = time.Time{}
+	scoreQuotas.mu.Unlock()
+}
+
+// quotaPeriod возвращает начало текущего периода квоты и момент её сброса
+func quotaPeriod(period string, now time.Time) (time.Time, time.Time) {
+	local := now.In(quotaLocation)
+	if period == models.QuotaPeriodMonth {
+		start := time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, quotaLocation)
+		return start, start.AddDate(0, 1, 0)
+	}
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, quotaLocation)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// quotaSubject — пользователь или команда, к которым применяется квота; пусто, если квота не про этого пользователя
+func quotaSubject(quota models.ScoreQuota, principal models.Principal) string {
+	switch quota.ScopeType {
+	case models.QuotaScopeUser:
+		if quota.Scope == models.QuotaAny || quota.Scope == principal.UserId || quota.Scope == principal.UserName {
+			return principal.UserId
+		}
+	case models.QuotaScopeTeam:
+		if principal.Team != "" && (quota.Scope == models.QuotaAny || quota.Scope == principal.Team) {
+			return principal.Team
+		}
+	}
+	return ""
+}
+
+func scoreQuotaStatus(ctx context.Context, quota models.ScoreQuota, subject string, now time.Time) (models.ScoreQuotaStatus, error) {
+	start, resetsAt := quotaPeriod(quota.Period, now)
+	used, err := repositories.CountBillableScoreCalls(ctx, quota.ScopeType, subject, quota.ScoreCard, start)
+	if err != nil {
+		return models.ScoreQuotaStatus{}, fmt.Errorf("failed to count score usage: %v", err)
+	}
+	remaining := quota.Limit - used
+	if remaining < 0 {
+		remaining = 0
+	}
+	return models.ScoreQuotaStatus{Quota: quota, Subject: subject, Used: used, Remaining: remaining, ResetsAt: resetsAt}, nil
+}
+
+// checkScoreQuota проверяет все квоты пользователя и его команды на карту перед платным запросом.
+// Одновременные запросы могут превысить квоту на несколько штук: счёт ведётся по сохранённым запросам.
+func checkScoreQuota(ctx context.Context, principal models.Principal, scoreCard string) error {
+	quotas, err := loadScoreQuotas(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load score quotas: %v", err)
+	}
+	now := time.Now()
+	for _, quota := range quotas {
+		if quota.ScoreCard != models.QuotaAny && quota.ScoreCard != strings.TrimSpace(scoreCard) {
+			continue
+		}
+		subject := quotaSubject(quota, principal)
+		if subject == "" {
+			continue
+		}
+		status, err := scoreQuotaStatus(ctx, quota, subject, now)
+		if err != nil {
+			return err
+		}
+		if status.Used >= quota.Limit {
+			return &models.QuotaExceededError{Status: status}
+		}
+	}
+	return nil
+}
+
+func ListScoreQuotas(ctx context.Context) ([]models.ScoreQuota, error) {
+	return repositories.ListScoreQuotas(ctx)
+}
+
+// PutScoreQuota создаёт квоту или меняет лимит существующей
+func PutScoreQuota(ctx context.Context, principal models.Principal, request models.ScoreQuotaRequest) (models.ScoreQuota, error) {
+	quota := models.ScoreQuota{
+		ScopeType: request.ScopeType,
+		Scope:     strings.TrimSpace(request.Scope),
+		ScoreCard: strings.TrimSpace(request.ScoreCard),
+		Period:    request.Period,
+		Limit:     request.Limit,
+		UpdatedBy: principal.UserName,
+	}
+	if quota.ScoreCard == "" {
+		quota.ScoreCard = models.QuotaAny
+	}
+	saved, err := repositories.UpsertScoreQuota(ctx, quota)
+	if err != nil {
+		return models.ScoreQuota{}, err
+	}
+	resetScoreQuotas()
+	return saved, nil
+}
+
+func DeleteScoreQuota(ctx context.Context, id int64) error {
+	deleted, err := repositories.DeleteScoreQuota(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrScoreQuotaNotFound
+	}
+	resetScoreQuotas()
+	return nil
+}
+
+// GetMyScoreQuotas — состояние всех квот, которые относятся к пользователю и его команде
+func GetMyScoreQuotas(ctx context.Context, principal models.Principal) ([]models.ScoreQuotaStatus, error) {
+	quotas, err := loadScoreQuotas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	statuses := []models.ScoreQuotaStatus{}
+	for _, quota := range quotas {
+		subject := quotaSubject(quota, principal)
+		if subject == "" {
+			continue
+		}
+		status, err := scoreQuotaStatus(ctx, quota, subject, now)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// GetMyScoreUsage — использование пользователя за текущий месяц
+func GetMyScoreUsage(ctx context.Context, principal models.Principal) ([]models.ScoreUsageRow, error) {
+	from, _ := quotaPeriod(models.QuotaPeriodMonth, time.Now())
+	return repositories.GetScoreUsage(ctx, models.ScoreUsageFilter{UserId: principal.UserId, From: &from})
+}
+
+func GetScoreUsage(ctx context.Context, filter models.ScoreUsageFilter) ([]models.ScoreUsageRow, error) {
+	return repositories.GetScoreUsage(ctx, filter)
+}
+
+// GetScoreBilling — платные запросы за месяц (YYYY-MM, по умолчанию текущий) по департаментам.
+// Если для карты задана цена в SCORE_CARD_PRICES ("BehaviorScoring=150"), считается сумма.
+func GetScoreBilling(ctx context.Context, month string) ([]models.ScoreBillingRow, error) {
+	var from time.Time
+	if month == "" {
+		from, _ = quotaPeriod(models.QuotaPeriodMonth, time.Now())
+	} else {
+		parsed, err := time.ParseInLocation("2006-01", month, quotaLocation)
+		if err != nil {
+			return nil, ErrInvalidBillingMonth
+		}
+		from = parsed
+	}
+
+	rows, err := repositories.GetScoreBilling(ctx, from, from.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, err
+	}
+	prices := parseEnvPairs("SCORE_CARD_PRICES")
+	for i := range rows {
+		rows[i].Month = from.Format("2006-01")
+		if value, ok := prices[rows[i].ScoreCard]; ok {
+			if price, err := parseDecisionNumber(value); err == nil {
+				amount := price * float64(rows[i].Calls)
+				rows[i].UnitPrice = &price
+				rows[i].Amount = &amount
+			}
+		}
+	}
+	return rows, nil
+}
+
+var scoreBillingHeader = []string{"month", "team", "score_card", "provider", "calls", "unit_price", "amount"}
+
+func WriteScoreBillingCSV(w io.Writer, rows []models.ScoreBillingRow) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(scoreBillingHeader); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		price, amount := "", ""
+		if r.UnitPrice != nil {
+			price = strconv.FormatFloat(*r.UnitPrice, 'f', -1, 64)
+			amount = strconv.FormatFloat(*r.Amount, 'f', 2, 64)
+		}
+		record := []string{r.Month, r.Team, r.ScoreCard, r.Provider, strconv.FormatInt(r.Calls, 10), price, amount}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
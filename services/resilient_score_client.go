@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/resilience"
+	"time"
+)
+
+// resilientScoreClient ограничивает время операций, повторяет идемпотентный
+// GetScoreCards и не пускает запросы к бюро, пока выключатель разомкнут.
+// Score не повторяется: каждый вызов оплачивается.
+type resilientScoreClient struct {
+	next              ScoreClient
+	breaker           *resilience.CircuitBreaker
+	scoreCardsTimeout time.Duration
+	scoreTimeout      time.Duration
+	retries           int
+	retryBase         time.Duration
+}
+
+func (r *resilientScoreClient) GetScoreCards(ctx context.Context, call ScoreCardsCall) ([]models.ScoreCardsReturnDataXml, error) {
+	ctx, err := r.withCredential(ctx, call.Principal)
+	if err != nil {
+		return nil, err
+	}
+	var cards []models.ScoreCardsReturnDataXml
+	err = resilience.Retry(ctx, r.retries+1, r.retryBase, func() error {
+		return r.breaker.Do(func() error {
+			opCtx, cancel := context.WithTimeout(ctx, r.scoreCardsTimeout)
+			defer cancel()
+
+			var err error
+			cards, err = r.next.GetScoreCards(opCtx, call)
+			return err
+		}, isBureauFailure)
+	}, isBureauFailure)
+	return cards, err
+}
+
+func (r *resilientScoreClient) Score(ctx context.Context, call ScoreCall) (models.ScoreResponseXml, error) {
+	ctx, err := r.withCredential(ctx, call.Principal)
+	if err != nil {
+		return models.ScoreResponseXml{}, err
+	}
+	var response models.ScoreResponseXml
+	err = r.breaker.Do(func() error {
+		opCtx, cancel := context.WithTimeout(ctx, r.scoreTimeout)
+		defer cancel()
+
+		var err error
+		response, err = r.next.Score(opCtx, call)
+		return err
+	}, isBureauFailure)
+	return response, err
+}
+
+// withCredential выбирает учётные данные бюро до выключателя: ошибка хранилища иначе
+// засчитывалась бы выключателю как успешный ответ бюро и сбрасывала счётчик сбоев
+func (r *resilientScoreClient) withCredential(ctx context.Context, principal models.Principal) (context.Context, error) {
+	resolver, ok := r.next.(credentialResolver)
+	if !ok {
+		return ctx, nil
+	}
+	credential, err := resolver.resolveCredential(ctx, principal)
+	if err != nil {
+		return ctx, err
+	}
+	return withBureauCredential(ctx, credential), nil
+}
+
+func isBureauFailure(err error) bool {
+	var transportErr *BureauTransportError
+	return errors.As(err, &transportErr) || errors.Is(err, context.DeadlineExceeded)
+}
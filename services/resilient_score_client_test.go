@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/resilience"
+	"go-keycloak-jwt/simulator"
+	"testing"
+	"time"
+)
+
+// staticCredentialStore отдаёт одни и те же учётные данные бюро
+type staticCredentialStore struct {
+	credential models.BureauCredential
+}
+
+func (s staticCredentialStore) Lookup(context.Context, models.Principal) (models.BureauCredential, error) {
+	return s.credential, nil
+}
+
+func (s staticCredentialStore) List(context.Context) ([]models.BureauCredential, error) {
+	return []models.BureauCredential{s.credential}, nil
+}
+
+func (s staticCredentialStore) Save(_ context.Context, credential models.BureauCredential) (models.BureauCredential, error) {
+	return credential, nil
+}
+
+func (s staticCredentialStore) Delete(context.Context, int64) error {
+	return nil
+}
+
+func newSimulatedScoreClient(t *testing.T, breakerFailures int) (*resilientScoreClient, *simulator.Simulator) {
+	t.Helper()
+	server, sim := simulator.NewTestServer(simulator.Config{})
+	t.Cleanup(server.Close)
+
+	store := staticCredentialStore{credential: withCredentialDefaults(models.BureauCredential{UserName: "test", Password: "test"})}
+	client := &resilientScoreClient{
+		next:              newSoapScoreClient(server.URL, store),
+		breaker:           resilience.NewCircuitBreaker(breakerFailures, time.Minute),
+		scoreCardsTimeout: 5 * time.Second,
+		scoreTimeout:      5 * time.Second,
+		retries:           0,
+		retryBase:         time.Millisecond,
+	}
+	return client, sim
+}
+
+func simulatedScoreCall() ScoreCall {
+	var request models.ScoreRequest
+	request.Score.ScoreCard = "BehaviorScoring"
+	request.Score.Attributes.Name = "IIN"
+	request.Score.Attributes.Value = "900115300003"
+	return ScoreCall{Principal: models.Principal{UserId: "user-1"}, Request: request}
+}
+
+func TestResilientScoreClientSimulator(t *testing.T) {
+	client, _ := newSimulatedScoreClient(t, 2)
+	ctx := context.Background()
+
+	cards, err := client.GetScoreCards(ctx, ScoreCardsCall{Principal: models.Principal{UserId: "user-1"}})
+	if err != nil {
+		t.Fatalf("GetScoreCards error = %v", err)
+	}
+	if len(cards) != 1 || cards[0].Name != "BehaviorScoring" {
+		t.Fatalf("GetScoreCards = %+v, want BehaviorScoring", cards)
+	}
+
+	response, err := client.Score(ctx, simulatedScoreCall())
+	if err != nil {
+		t.Fatalf("Score error = %v", err)
+	}
+	result := response.Envelope.Body.ScoreResponse.Return
+	if result.IdQuery == "" || result.Score == "" {
+		t.Fatalf("Score returned an empty result: %+v", result)
+	}
+	if state := client.breaker.Snapshot().State; state != resilience.Closed {
+		t.Fatalf("breaker state = %s, want %s", state, resilience.Closed)
+	}
+}
+
+// SOAP Fault класса Server с HTTP 500 — сбой бюро: выключатель должен его считать и размыкаться
+func TestResilientScoreClientOpensBreakerOnServerFault(t *testing.T) {
+	client, sim := newSimulatedScoreClient(t, 2)
+	sim.SetFaults(simulator.Faults{FaultRate: 1})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Score(ctx, simulatedScoreCall())
+		var transportErr *BureauTransportError
+		if !errors.As(err, &transportErr) {
+			t.Fatalf("Score #%d error = %v, want BureauTransportError", i+1, err)
+		}
+	}
+	if state := client.breaker.Snapshot().State; state != resilience.Open {
+		t.Fatalf("breaker state = %s, want %s", state, resilience.Open)
+	}
+
+	// Пока выключатель разомкнут, ни Score, ни GetScoreCards к бюро не обращаются
+	sim.SetFaults(simulator.Faults{})
+	var openErr *resilience.OpenError
+	if _, err := client.Score(ctx, simulatedScoreCall()); !errors.As(err, &openErr) {
+		t.Fatalf("Score error = %v, want OpenError", err)
+	}
+	if _, err := client.GetScoreCards(ctx, ScoreCardsCall{}); !errors.As(err, &openErr) {
+		t.Fatalf("GetScoreCards error = %v, want OpenError", err)
+	}
+}
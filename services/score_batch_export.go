@@ -0,0 +1,77 @@
+package services
+
+import (
+	"encoding/csv"
+	"github.com/xuri/excelize/v2"
+	"go-keycloak-jwt/models"
+	"io"
+	"strconv"
+)
+
+var scoreBatchResultHeader = []string{
+	"row", "subject", "status", "inquiry_id", "IdQuery", "Score", "OneYearProbabilityOfDefault", "RiskGrade",
+	"ScoreByML", "OneYearProbabilityOfDefaultByML", "RiskGradeByML", "error",
+}
+
+func scoreBatchResultRecord(r models.ScoreBatchResultRow) []string {
+	inquiryId := ""
+	if r.InquiryId != nil {
+		inquiryId = strconv.FormatInt(*r.InquiryId, 10)
+	}
+	return []string{
+		strconv.Itoa(r.RowNumber), r.Subject, r.Status, inquiryId, r.IdQuery, r.Score,
+		r.OneYearProbabilityOfDefault, r.RiskGrade, r.ScoreByML, r.OneYearProbabilityOfDefaultByML,
+		r.RiskGradeByML, r.Error,
+	}
+}
+
+func WriteScoreBatchResultsCSV(w io.Writer, results []models.ScoreBatchResultRow) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(scoreBatchResultHeader); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if err := writer.Write(scoreBatchResultRecord(r)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func WriteScoreBatchResultsXLSX(w io.Writer, results []models.ScoreBatchResultRow) error {
+	f := excelize.NewFile()
+	defer func() {
+		_ = f.Close()
+	}()
+
+	sheet := f.GetSheetName(0)
+	stream, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+	if err := stream.SetRow("A1", toCells(scoreBatchResultHeader)); err != nil {
+		return err
+	}
+	for i, r := range results {
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err := stream.SetRow(cell, toCells(scoreBatchResultRecord(r))); err != nil {
+			return err
+		}
+	}
+	if err := stream.Flush(); err != nil {
+		return err
+	}
+	return f.Write(w)
+}
+
+func toCells(values []string) []interface{} {
+	cells := make([]interface{}, len(values))
+	for i, v := range values {
+		cells[i] = v
+	}
+	return cells
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/xuri/excelize/v2"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/pii"
+	"go-keycloak-jwt/repositories"
+	"go-keycloak-jwt/resilience"
+	"golang.org/x/time/rate"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrScoreBatchNotFound = errors.New("score batch not found")
+
+const defaultScoreBatchMaxRows = 10000
+
+type scoreBatchTask struct {
+	batch models.ScoreBatch
+	item  models.ScoreBatchItem
+	done  *sync.WaitGroup
+}
+
+var (
+	scoreBatchTasks   chan scoreBatchTask
+	scoreBatchLimiter *rate.Limiter
+)
+
+// StartScoreBatchWorkers запускает пул воркеров пакетного скоринга.
+// Число воркеров задаёт SCORE_BATCH_WORKERS, лимит запросов в бюро в секунду — BUREAU_RATE_LIMIT.
+// Незавершённые задания (например, прерванные перезапуском) ставятся в очередь заново.
+func StartScoreBatchWorkers() {
+	workers := envInt("SCORE_BATCH_WORKERS", 4)
+	rps := envFloat("BUREAU_RATE_LIMIT", 5)
+
+	scoreBatchTasks = make(chan scoreBatchTask, workers)
+	scoreBatchLimiter = rate.NewLimiter(rate.Limit(rps), 1)
+	for i := 0; i < workers; i++ {
+		go scoreBatchWorker()
+	}
+
+	ids, err := repositories.GetUnfinishedScoreBatchIds(context.Background())
+	if err != nil {
+		log.Printf("failed to load unfinished score batches: %v", err)
+		return
+	}
+	for _, id := range ids {
+		go runScoreBatch(id)
+	}
+}
+
+// ParseScoreBatchFile читает список субъектов из CSV или XLSX.
+// Если в первой строке есть заголовок с именем атрибута (например, IIN), берётся эта колонка,
+// иначе — первая колонка всех строк.
+func ParseScoreBatchFile(fileName string, r io.Reader, attributeName string) ([]string, error) {
+	var records [][]string
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %v", err)
+		}
+		records = rows
+	case ".xlsx":
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read XLSX: %v", err)
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		rows, err := f.GetRows(f.GetSheetName(0))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read XLSX: %v", err)
+		}
+		records = rows
+	default:
+		return nil, fmt.Errorf("unsupported file type, expected .csv or .xlsx")
+	}
+
+	column := 0
+	if len(records) > 0 {
+		for i, cell := range records[0] {
+			if strings.EqualFold(strings.TrimSpace(cell), attributeName) {
+				column = i
+				records = records[1:]
+				break
+			}
+		}
+	}
+
+	var subjects []string
+	for _, record := range records {
+		if column >= len(record) {
+			continue
+		}
+		if subject := strings.TrimSpace(record[column]); subject != "" {
+			subjects = append(subjects, subject)
+		}
+	}
+	if len(subjects) == 0 {
+		return nil, fmt.Errorf("file contains no subjects")
+	}
+	if max := envInt("SCORE_BATCH_MAX_ROWS", defaultScoreBatchMaxRows); len(subjects) > max {
+		return nil, fmt.Errorf("file contains %d subjects, the limit is %d", len(subjects), max)
+	}
+	return subjects, nil
+}
+
+// CreateScoreBatch сохраняет задание и ставит его в очередь на обработку
+func CreateScoreBatch(ctx context.Context, principal models.Principal, scoreCard string, attributeName string, fileName string, subjects []string) (models.ScoreBatch, error) {
+	batch := models.ScoreBatch{
+		UserId:        principal.UserId,
+		UserName:      principal.UserName,
+		Team:          principal.Team,
+		Groups:        principal.Groups,
+		Roles:         principal.Roles,
+		ScoreCard:     scoreCard,
+		AttributeName: attributeName,
+		FileName:      fileName,
+		Status:        models.ScoreBatchPending,
+	}
+	if err := repositories.CreateScoreBatch(ctx, &batch, subjects); err != nil {
+		return models.ScoreBatch{}, err
+	}
+
+	go runScoreBatch(batch.Id)
+	return batch, nil
+}
+
+func GetScoreBatchById(ctx context.Context, principal models.Principal, id int64) (models.ScoreBatch, error) {
+	batch, err := repositories.GetScoreBatchById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ScoreBatch{}, ErrScoreBatchNotFound
+	}
+	if err != nil {
+		return models.ScoreBatch{}, err
+	}
+
+	owner := models.ScoreInquiry{UserId: batch.UserId, Team: batch.Team}
+	if !canViewScoreInquiry(principal, owner) {
+		return models.ScoreBatch{}, ErrScoreBatchNotFound
+	}
+	return batch, nil
+}
+
+func GetScoreBatchResults(ctx context.Context, principal models.Principal, id int64) ([]models.ScoreBatchResultRow, error) {
+	if _, err := GetScoreBatchById(ctx, principal, id); err != nil {
+		return nil, err
+	}
+	return repositories.GetScoreBatchResults(ctx, id)
+}
+
+// runScoreBatch раздаёт необработанные строки задания воркерам и закрывает задание,
+// когда все они обработаны
+func runScoreBatch(id int64) {
+	ctx := context.Background()
+	batch, err := repositories.GetScoreBatchById(ctx, id)
+	if err != nil {
+		log.Printf("failed to load score batch %d: %v", id, err)
+		return
+	}
+	items, err := repositories.GetPendingScoreBatchItems(ctx, id)
+	if err != nil {
+		log.Printf("failed to load score batch %d items: %v", id, err)
+		return
+	}
+	if err := repositories.SetScoreBatchStatus(ctx, id, models.ScoreBatchRunning); err != nil {
+		log.Printf("failed to start score batch %d: %v", id, err)
+		return
+	}
+
+	var done sync.WaitGroup
+	for _, item := range items {
+		done.Add(1)
+		scoreBatchTasks <- scoreBatchTask{batch: batch, item: item, done: &done}
+	}
+	done.Wait()
+
+	if err := repositories.FinishScoreBatch(ctx, id); err != nil {
+		log.Printf("failed to finish score batch %d: %v", id, err)
+	}
+}
+
+func scoreBatchWorker() {
+	for task := range scoreBatchTasks {
+		processScoreBatchItem(task.batch, task.item)
+		task.done.Done()
+	}
+}
+
+func processScoreBatchItem(batch models.ScoreBatch, item models.ScoreBatchItem) {
+	ctx := context.Background()
+
+	var inquiry models.ScoreInquiry
+	var err error
+	if item.InquiryId != nil {
+		// Строка уже скорилась до перезапуска — берём сохранённый результат, а не платим повторно
+		inquiry, err = repositories.GetScoreInquiryById(ctx, *item.InquiryId)
+	} else {
+		inquiry, err = scoreBatchItem(ctx, batch, item)
+	}
+
+	item.Status = models.ScoreBatchItemDone
+	if inquiry.Id != 0 {
+		item.InquiryId = &inquiry.Id
+	}
+	if err != nil {
+		item.Status = models.ScoreBatchItemFailed
+		item.Error = pii.RedactText(err.Error())
+	} else if inquiry.ErrorCode != "0" {
+		item.Status = models.ScoreBatchItemFailed
+		item.Error = fmt.Sprintf("bureau error %s: %s", inquiry.ErrorCode, inquiry.ErrorString)
+	}
+
+	if err := repositories.CompleteScoreBatchItem(ctx, item); err != nil {
+		log.Printf("score batch %d row %d: failed to save result: %v", batch.Id, item.RowNumber, err)
+	}
+}
+
+// scoreBatchItem скорит строку от имени автора задания (с его группами — для учётных данных бюро и учёта)
+// и сразу привязывает запрос к строке
+func scoreBatchItem(ctx context.Context, batch models.ScoreBatch, item models.ScoreBatchItem) (models.ScoreInquiry, error) {
+	principal := models.Principal{
+		UserId:   batch.UserId,
+		UserName: batch.UserName,
+		Team:     batch.Team,
+		Groups:   batch.Groups,
+		Roles:    batch.Roles,
+	}
+	var request models.ScoreRequest
+	request.Score.ScoreCard = batch.ScoreCard
+	request.Score.Attributes.Name = batch.AttributeName
+	request.Score.Attributes.Value = item.Subject
+	// Лимит BUREAU_RATE_LIMIT расходуют только запросы к провайдеру, не ответы из кэша
+	options := ScoreOptions{RateLimiter: scoreBatchLimiter}
+
+	_, inquiry, err := Score(ctx, "", principal, request, options)
+	// Пока бюро отключено выключателем, строку не бракуем, а ждём и повторяем
+	var openErr *resilience.OpenError
+	for errors.As(err, &openErr) {
+		time.Sleep(openErr.RetryAfter)
+		_, inquiry, err = Score(ctx, "", principal, request, options)
+	}
+	if inquiry.Id != 0 {
+		if linkErr := repositories.SetScoreBatchItemInquiry(ctx, item.Id, inquiry.Id); linkErr != nil {
+			log.Printf("score batch %d row %d: failed to link inquiry: %v", batch.Id, item.RowNumber, linkErr)
+		}
+	}
+	return inquiry, err
+}
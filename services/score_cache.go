@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/repositories"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScoreCache хранит ответы бюро, чтобы не оплачивать повторные запросы
+// по одному и тому же субъекту
+type ScoreCache interface {
+	Get(ctx context.Context, key string) (models.ScoreCacheEntry, bool, error)
+	Set(ctx context.Context, key string, scoreCard string, entry models.ScoreCacheEntry) error
+}
+
+var scoreCache ScoreCache = noScoreCache{}
+
+const defaultScoreCacheTTL = 24 * time.Hour
+
+// InitScoreCache настраивает кэш по переменным окружения:
+// SCORE_CACHE — memory, postgres или пусто (кэш выключен);
+// SCORE_CACHE_TTL — время жизни по умолчанию (например, 12h);
+// SCORE_CACHE_TTLS — время жизни по картам: "BehaviorScoring=6h,Other=0" (0 — не кэшировать карту).
+func InitScoreCache() {
+	ttls := scoreCacheTTLs{defaultTTL: defaultScoreCacheTTL, byCard: map[string]time.Duration{}}
+	if value := os.Getenv("SCORE_CACHE_TTL"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("invalid SCORE_CACHE_TTL: %v", err)
+		}
+		ttls.defaultTTL = ttl
+	}
+	for _, pair := range strings.Split(os.Getenv("SCORE_CACHE_TTLS"), ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		card, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			log.Fatalf("invalid SCORE_CACHE_TTLS entry: %s", pair)
+		}
+		ttl, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			log.Fatalf("invalid SCORE_CACHE_TTLS entry %s: %v", pair, err)
+		}
+		ttls.byCard[strings.TrimSpace(card)] = ttl
+	}
+
+	switch os.Getenv("SCORE_CACHE") {
+	case "memory":
+		cache := &memoryScoreCache{ttls: ttls, entries: map[string]memoryScoreCacheEntry{}}
+		go cache.purgeExpired()
+		scoreCache = cache
+	case "postgres":
+		cache := &postgresScoreCache{ttls: ttls}
+		go cache.purgeExpired()
+		scoreCache = cache
+	case "":
+		scoreCache = noScoreCache{}
+	default:
+		log.Fatalf("unknown SCORE_CACHE: %s", os.Getenv("SCORE_CACHE"))
+	}
+}
+
+// scoreCacheKey строится по провайдеру, языку, карте и нормализованным атрибутам: регистр имени
+// и пробелы в значениях не должны приводить к повторному платному запросу
+func scoreCacheKey(provider string, locale string, score models.ScoreRequest) string {
+	attributes := score.Score.Attributes
+	parts := []string{
+		provider,
+		locale,
+		strings.TrimSpace(score.Score.ScoreCard),
+		strings.ToUpper(strings.TrimSpace(attributes.Name)),
+		removeSpaces(attributes.Value),
+		removeSpaces(attributes.Values.Id),
+		removeSpaces(attributes.Values.Value),
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+func removeSpaces(value string) string {
+	return strings.Join(strings.Fields(value), "")
+}
+
+type scoreCacheTTLs struct {
+	defaultTTL time.Duration
+	byCard     map[string]time.Duration
+}
+
+func (t scoreCacheTTLs) forCard(scoreCard string) time.Duration {
+	if ttl, ok := t.byCard[strings.TrimSpace(scoreCard)]; ok {
+		return ttl
+	}
+	return t.defaultTTL
+}
+
+type noScoreCache struct{}
+
+func (noScoreCache) Get(context.Context, string) (models.ScoreCacheEntry, bool, error) {
+	return models.ScoreCacheEntry{}, false, nil
+}
+
+func (noScoreCache) Set(context.Context, string, string, models.ScoreCacheEntry) error {
+	return nil
+}
+
+type memoryScoreCacheEntry struct {
+	entry     models.ScoreCacheEntry
+	expiresAt time.Time
+}
+
+type memoryScoreCache struct {
+	ttls    scoreCacheTTLs
+	mu      sync.Mutex
+	entries map[string]memoryScoreCacheEntry
+}
+
+func (m *memoryScoreCache) Get(_ context.Context, key string) (models.ScoreCacheEntry, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cached, ok := m.entries[key]
+	if !ok || time.Now().After(cached.expiresAt) {
+		return models.ScoreCacheEntry{}, false, nil
+	}
+	return cached.entry, true, nil
+}
+
+func (m *memoryScoreCache) Set(_ context.Context, key string, scoreCard string, entry models.ScoreCacheEntry) error {
+	ttl := m.ttls.forCard(scoreCard)
+	if ttl <= 0 {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = memoryScoreCacheEntry{entry: entry, expiresAt: entry.InquiredAt.Add(ttl)}
+	return nil
+}
+
+func (m *memoryScoreCache) purgeExpired() {
+	for range time.Tick(time.Minute) {
+		now := time.Now()
+		m.mu.Lock()
+		for key, cached := range m.entries {
+			if now.After(cached.expiresAt) {
+				delete(m.entries, key)
+			}
+		}
+		m.mu.Unlock()
+	}
+}
+
+// postgresScoreCache разделяет кэш между несколькими экземплярами сервиса
+type postgresScoreCache struct {
+	ttls scoreCacheTTLs
+}
+
+func (p *postgresScoreCache) Get(ctx context.Context, key string) (models.ScoreCacheEntry, bool, error) {
+	return repositories.GetScoreCacheEntry(ctx, key)
+}
+
+func (p *postgresScoreCache) Set(ctx context.Context, key string, scoreCard string, entry models.ScoreCacheEntry) error {
+	ttl := p.ttls.forCard(scoreCard)
+	if ttl <= 0 {
+		return nil
+	}
+	return repositories.UpsertScoreCacheEntry(ctx, key, scoreCard, entry, entry.InquiredAt.Add(ttl))
+}
+
+func (p *postgresScoreCache) purgeExpired() {
+	for range time.Tick(10 * time.Minute) {
+		if err := repositories.DeleteExpiredScoreCacheEntries(context.Background()); err != nil {
+			log.Printf("failed to purge score cache: %v", err)
+		}
+	}
+}
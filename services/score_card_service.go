@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"go-keycloak-jwt/models"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const scoreCardsTTL = time.Hour
+
+// Описания карт меняются редко, поэтому держим их в памяти,
+// чтобы проверка запроса не требовала лишнего обращения к бюро
+var scoreCardsCache struct {
+	mu       sync.Mutex
+	cards    []models.ScoreCard
+	loadedAt time.Time
+}
+
+// GetScoreCards собирает карты всех провайдеров. Ошибка провайдера по умолчанию
+// возвращается как есть; недоступность остальных только логируется, чтобы не терять основной список.
+func GetScoreCards(ctx context.Context, tokenString string, principal models.Principal, request models.ScoreCardsRequest) ([]models.ScoreCard, error) {
+	call := ScoreCardsCall{
+		SubjectToken: tokenString,
+		Principal:    principal,
+		Request:      request,
+	}
+
+	var cards []models.ScoreCard
+	for _, name := range scoreProviderOrder {
+		provided, err := scoreProviders[name].ListScoreCards(ctx, call)
+		if err != nil {
+			if name == defaultScoreProvider {
+				return nil, err
+			}
+			log.Printf("failed to load score cards of provider %s: %v", name, err)
+			continue
+		}
+		cards = append(cards, provided...)
+	}
+
+	scoreCardsCache.mu.Lock()
+	scoreCardsCache.cards = cards
+	scoreCardsCache.loadedAt = time.Now()
+	scoreCardsCache.mu.Unlock()
+	return cards, nil
+}
+
+// findScoreCard возвращает описание карты по имени. found=false, если карты нет;
+// ok=false, если описания карт недоступны и проверить карту нельзя
+func findScoreCard(ctx context.Context, tokenString string, principal models.Principal, name string) (card models.ScoreCard, found bool, ok bool) {
+	scoreCardsCache.mu.Lock()
+	cards := scoreCardsCache.cards
+	fresh := time.Since(scoreCardsCache.loadedAt) < scoreCardsTTL
+	scoreCardsCache.mu.Unlock()
+
+	if !fresh {
+		loaded, err := GetScoreCards(ctx, tokenString, principal, models.ScoreCardsRequest{})
+		if err != nil {
+			log.Printf("failed to load score cards for validation: %v", err)
+			return models.ScoreCard{}, false, false
+		}
+		cards = loaded
+	}
+
+	for _, c := range cards {
+		if strings.TrimSpace(c.Name) == strings.TrimSpace(name) {
+			return c, true, true
+		}
+	}
+	return models.ScoreCard{}, false, true
+}
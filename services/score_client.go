@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/resilience"
+	"log"
+	"os"
+	"time"
+)
+
+// Параметры одного обращения к бюро.
+// SubjectToken — токен пользователя; наружу он не передаётся, для внешней системы выпускается свой (см. mintDownstreamToken).
+type ScoreCall struct {
+	SubjectToken string
+	Principal    models.Principal
+	Request      models.ScoreRequest
+}
+
+// Параметры запроса списка скоринговых карт
+type ScoreCardsCall struct {
+	SubjectToken string
+	Principal    models.Principal
+	Request      models.ScoreCardsRequest
+}
+
+// ScoreClient — клиент скорингового сервиса бюро
+type ScoreClient interface {
+	GetScoreCards(ctx context.Context, call ScoreCardsCall) ([]models.ScoreCardsReturnDataXml, error)
+	Score(ctx context.Context, call ScoreCall) (models.ScoreResponseXml, error)
+}
+
+var scoreClient ScoreClient = staticScoreClient{}
+
+var bureauBreaker *resilience.CircuitBreaker
+
+// InitScoreClient настраивает клиент бюро; вызывается после InitBureauCredentials. Если BUREAU_URL не задан,
+// используется статический ответ (для разработки).
+//
+// BUREAU_SCORE_CARDS_TIMEOUT и BUREAU_SCORE_TIMEOUT — сроки операций (по умолчанию 5s и 15s),
+// BUREAU_RETRIES — число повторов GetScoreCards (по умолчанию 2),
+// BUREAU_BREAKER_FAILURES и BUREAU_BREAKER_COOLDOWN — сколько сбоев подряд размыкают
+// выключатель и на сколько (по умолчанию 5 и 30s).
+func InitScoreClient() {
+	var base ScoreClient = staticScoreClient{}
+	if url := os.Getenv("BUREAU_URL"); url != "" {
+		base = newSoapScoreClient(url, bureauCredentials)
+	} else if os.Getenv("BUREAU_TRAFFIC_MODE") == "replay" {
+		// При воспроизведении адрес не используется: ответы берутся из записей
+		base = newSoapScoreClient("http://bureau.replay.invalid", nil)
+	}
+
+	bureauBreaker = resilience.NewCircuitBreaker(
+		envInt("BUREAU_BREAKER_FAILURES", 5),
+		envDuration("BUREAU_BREAKER_COOLDOWN", 30*time.Second),
+	)
+	scoreClient = &resilientScoreClient{
+		next:              base,
+		breaker:           bureauBreaker,
+		scoreCardsTimeout: envDuration("BUREAU_SCORE_CARDS_TIMEOUT", 5*time.Second),
+		scoreTimeout:      envDuration("BUREAU_SCORE_TIMEOUT", 15*time.Second),
+		retries:           envInt("BUREAU_RETRIES", 2),
+		retryBase:         200 * time.Millisecond,
+	}
+}
+
+// BureauHealth — состояние выключателя запросов к бюро
+func BureauHealth() resilience.Snapshot {
+	if bureauBreaker == nil {
+		return resilience.Snapshot{State: resilience.Closed}
+	}
+	return bureauBreaker.Snapshot()
+}
+
+// healthReporter — провайдер со своим выключателем
+type healthReporter interface {
+	Health() resilience.Snapshot
+}
+
+// ScoreProvidersHealth — состояние выключателя каждого провайдера скоринга по имени
+func ScoreProvidersHealth() map[string]resilience.Snapshot {
+	health := make(map[string]resilience.Snapshot, len(scoreProviders))
+	for name, provider := range scoreProviders {
+		if reporter, ok := provider.(healthReporter); ok {
+			health[name] = reporter.Health()
+		}
+	}
+	return health
+}
+
+const GetScoreCardsXml = `
+<S:Envelope
+	xmlns:S="http://schemas.xmlsoap.org/soap/envelope/">
+	<S:Body>
+		<GetScoreCardsResponse
+			xmlns="http://score.ws.creditinfo.com/">
+			<return>
+				<attributes>
+					<name>IIN</name>
+				</attributes>
+				<name> BehaviorScoring</name>
+			</return>
+		</GetScoreCardsResponse>
+	</S:Body>
+</S:Envelope>
+`
+
+const ScoreXml = `
+<S:Envelope
+	xmlns:S="http://schemas.xmlsoap.org/soap/envelope/">
+	<S:Body>
+		<ScoreResponse
+			xmlns="http://score.ws.creditinfo.com/">
+			<return>
+				<IdQuery>3619</IdQuery>
+				<ErrorCode>0</ErrorCode>
+				<ErrorString>Выполнено успешно</ErrorString>
+				<Score>73.0</Score>
+				<OneYearProbabilityOfDefault>2% - 3%</OneYearProbabilityOfDefault>
+				<RiskGrade/>
+				<ScoreByML>444.0</ScoreByML>
+				<OneYearProbabilityOfDefaultByML>10% - 15%</OneYearProbabilityOfDefaultByML>
+				<RiskGradeByML>B1</RiskGradeByML>
+				<Causes>
+					<name>Test</name>
+					<causeText>Пример сообщения о низком балле</causeText>
+				</Causes>
+				<Causes>
+					<name>History</name>
+					<causeText>Короткая кредитная история</causeText>
+				</Causes>
+			</return>
+		</ScoreResponse>
+	</S:Body>
+</S:Envelope>
+
+`
+
+// staticScoreClient отвечает фиксированным XML, пока нет доступа к бюро.
+// В бюро он ничего не отправляет, поэтому учётные данные ему не нужны.
+type staticScoreClient struct{}
+
+func (staticScoreClient) GetScoreCards(context.Context, ScoreCardsCall) ([]models.ScoreCardsReturnDataXml, error) {
+	// Парсим XML-ответ
+	var envelope models.ScoreCardsEnvelopeXml
+	err := xml.Unmarshal([]byte(GetScoreCardsXml), &envelope)
+	if err != nil {
+		log.Printf("Ошибка парсинга XML: %v", err)
+		return nil, fmt.Errorf("failed to parse XML")
+	}
+	return envelope.Body.GetScoreCardsResponse.ReturnData, nil
+}
+
+func (staticScoreClient) Score(context.Context, ScoreCall) (models.ScoreResponseXml, error) {
+	// Парсим XML-ответ
+	var response models.ScoreResponseXml
+	err := xml.Unmarshal([]byte(ScoreXml), &response.Envelope)
+	if err != nil {
+		log.Printf("Ошибка парсинга XML: %v", err)
+		return models.ScoreResponseXml{}, fmt.Errorf("failed to parse XML")
+	}
+	return response, nil
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/repositories"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Сколько запросов выгружается в CSV за раз; для больших периодов выгрузку стоит разбить по датам
+const scoreDivergenceExportLimit = 100000
+
+var scoreDivergenceIntervals = map[string]bool{"day": true, "week": true, "month": true}
+
+// GetScoreDivergenceReport — как часто классический и ML-классы риска расходятся:
+// в целом, по картам, по периодам, распределение пар классов и случаи с наибольшим расхождением
+func GetScoreDivergenceReport(ctx context.Context, filter models.ScoreDivergenceFilter) (models.ScoreDivergenceReport, error) {
+	filter.RiskGrades = riskGradeScale()
+	if filter.Interval == "" {
+		filter.Interval = "day"
+	}
+	var fields []models.FieldError
+	if !scoreDivergenceIntervals[filter.Interval] {
+		fields = append(fields, models.FieldError{Field: "interval", Code: "INVALID_PARAMETER", Message: "interval must be day, week or month"})
+	}
+	if filter.Top < 0 || filter.Top > 100 {
+		fields = append(fields, models.FieldError{Field: "top", Code: "INVALID_PARAMETER", Message: "top must be from 1 to 100"})
+	}
+	if len(fields) > 0 {
+		return models.ScoreDivergenceReport{}, &models.ValidationError{Fields: fields}
+	}
+	if filter.Top == 0 {
+		filter.Top = 20
+	}
+
+	var report models.ScoreDivergenceReport
+	var err error
+	if report.ByScoreCard, report.Summary, err = repositories.GetScoreDivergenceByScoreCard(ctx, filter); err != nil {
+		return models.ScoreDivergenceReport{}, err
+	}
+	if report.ByPeriod, err = repositories.GetScoreDivergenceByPeriod(ctx, filter); err != nil {
+		return models.ScoreDivergenceReport{}, err
+	}
+	if report.GradePairs, err = repositories.GetScoreGradePairs(ctx, filter); err != nil {
+		return models.ScoreDivergenceReport{}, err
+	}
+	if report.TopCases, err = repositories.FindScoreDivergenceCases(ctx, filter, filter.Top); err != nil {
+		return models.ScoreDivergenceReport{}, err
+	}
+
+	setMismatchRate(&report.Summary)
+	for i := range report.ByScoreCard {
+		setMismatchRate(&report.ByScoreCard[i])
+	}
+	for i := range report.ByPeriod {
+		setMismatchRate(&report.ByPeriod[i])
+	}
+	return report, nil
+}
+
+// setMismatchRate — доля расхождений среди сравнимых запросов
+func setMismatchRate(stats *models.ScoreDivergenceStats) {
+	stats.NotComparable = stats.Total - stats.Comparable
+	if stats.Comparable > 0 {
+		stats.MismatchRate = float64(stats.GradeMismatches) / float64(stats.Comparable)
+	}
+}
+
+// GetScoreDivergenceCases — запросы для выгрузки команде валидации моделей
+func GetScoreDivergenceCases(ctx context.Context, filter models.ScoreDivergenceFilter) ([]models.ScoreDivergenceCase, error) {
+	filter.RiskGrades = riskGradeScale()
+	return repositories.FindScoreDivergenceCases(ctx, filter, scoreDivergenceExportLimit)
+}
+
+var scoreDivergenceHeader = []string{
+	"inquiry_id", "inquired_at", "score_card", "provider", "subject_iin", "Score", "RiskGrade", "ScoreByML",
+	"RiskGradeByML", "comparable", "grade_mismatch", "grade_notch_delta",
+}
+
+func WriteScoreDivergenceCSV(w io.Writer, cases []models.ScoreDivergenceCase) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(scoreDivergenceHeader); err != nil {
+		return err
+	}
+	for _, d := range cases {
+		delta := ""
+		if d.GradeNotchDelta != nil {
+			delta = strconv.Itoa(*d.GradeNotchDelta)
+		}
+		record := []string{
+			strconv.FormatInt(d.InquiryId, 10), d.InquiredAt.UTC().Format(time.RFC3339), d.ScoreCard,
+			d.Provider, d.SubjectIin, d.Score, d.RiskGrade, d.ScoreByML, d.RiskGradeByML,
+			strconv.FormatBool(d.Comparable), strconv.FormatBool(d.GradeMismatch), delta,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-keycloak-jwt/i18n"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/repositories"
+	"sort"
+	"strings"
+)
+
+var ErrReasonCodeNotFound = errors.New("reason code not found")
+
+func ListReasonCodes(ctx context.Context) ([]models.ReasonCode, error) {
+	return repositories.ListReasonCodes(ctx)
+}
+
+// PutReasonCode добавляет код в каталог или заменяет его описание; языки текста — ru, kk, en
+func PutReasonCode(ctx context.Context, principal models.Principal, request models.ReasonCodeRequest) (models.ReasonCode, error) {
+	code := models.ReasonCode{
+		Code:                strings.TrimSpace(request.Code),
+		Severity:            request.Severity,
+		CustomerText:        map[string]string{},
+		InternalExplanation: strings.TrimSpace(request.InternalExplanation),
+		UpdatedBy:           principal.UserName,
+	}
+
+	var fields []models.FieldError
+	for tag, text := range request.CustomerText {
+		locale := i18n.Normalize(tag)
+		if locale == "" {
+			fields = append(fields, models.FieldError{Field: "customer_text." + tag, Code: "UNSUPPORTED_LOCALE", Message: "supported locales are ru, kk and en"})
+			continue
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			code.CustomerText[locale] = text
+		}
+	}
+	if len(code.CustomerText) == 0 {
+		fields = append(fields, models.FieldError{Field: "customer_text", Code: "CUSTOMER_TEXT_REQUIRED", Message: "customer text in at least one locale is required"})
+	}
+	if code.Code == "" {
+		fields = append(fields, models.FieldError{Field: "code", Code: "CODE_REQUIRED", Message: "code is required"})
+	}
+	if len(fields) > 0 {
+		sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+		return models.ReasonCode{}, &models.ValidationError{Fields: fields}
+	}
+	return repositories.UpsertReasonCode(ctx, code)
+}
+
+func DeleteReasonCode(ctx context.Context, code string) error {
+	deleted, err := repositories.DeleteReasonCode(ctx, code)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrReasonCodeNotFound
+	}
+	return nil
+}
+
+// GetScoreExplanation — причины результата скоринга по убыванию значимости с текстами для письма заявителю
+// на языке locale. top > 0 оставляет только первые причины.
+func GetScoreExplanation(ctx context.Context, principal models.Principal, id int64, locale string, top int) (models.ScoreExplanation, error) {
+	inquiry, err := GetScoreInquiryById(ctx, principal, id)
+	if err != nil {
+		return models.ScoreExplanation{}, err
+	}
+
+	var codes []string
+	for _, cause := range inquiry.Causes {
+		codes = append(codes, strings.TrimSpace(cause.Name))
+	}
+	catalog, err := repositories.FindReasonCodes(ctx, codes)
+	if err != nil {
+		return models.ScoreExplanation{}, err
+	}
+
+	reasons := rankScoreReasons(inquiry.Causes, catalog, locale)
+	if top > 0 && len(reasons) > top {
+		reasons = reasons[:top]
+	}
+	return models.ScoreExplanation{
+		InquiryId:     inquiry.Id,
+		SubjectIin:    inquiry.SubjectIin,
+		ScoreCard:     inquiry.ScoreCard,
+		Provider:      inquiry.Provider,
+		Score:         inquiry.Score,
+		RiskGrade:     inquiry.RiskGrade,
+		ScoreByML:     inquiry.ScoreByML,
+		RiskGradeByML: inquiry.RiskGradeByML,
+		Decision:      inquiry.Decision,
+		Locale:        locale,
+		Reasons:       reasons,
+		InquiredAt:    inquiry.InquiredAt,
+	}, nil
+}
+
+// rankScoreReasons упорядочивает причины по серьёзности из каталога; при равной серьёзности сохраняется
+// порядок бюро. Коды вне каталога идут последними с текстом бюро, повторы кода убираются.
+func rankScoreReasons(causes []models.Causes, catalog map[string]models.ReasonCode, locale string) []models.ScoreReason {
+	reasons := []models.ScoreReason{}
+	seen := map[string]bool{}
+	for _, cause := range causes {
+		code := strings.TrimSpace(cause.Name)
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+
+		reason := models.ScoreReason{Code: code, Text: cause.CauseText, BureauText: cause.CauseText}
+		if entry, ok := catalog[code]; ok {
+			reason.Cataloged = true
+			reason.Severity = entry.Severity
+			reason.InternalExplanation = entry.InternalExplanation
+			if text := customerText(entry, locale); text != "" {
+				reason.Text = text
+			}
+		}
+		reasons = append(reasons, reason)
+	}
+
+	sort.SliceStable(reasons, func(i, j int) bool {
+		return reasons[i].Severity > reasons[j].Severity
+	})
+	for i := range reasons {
+		reasons[i].Rank = i + 1
+	}
+	return reasons
+}
+
+// customerText — текст для клиента на нужном языке, иначе на языке по умолчанию или английском
+func customerText(code models.ReasonCode, locale string) string {
+	for _, l := range []string{locale, i18n.DefaultLocale(), i18n.English} {
+		if text := code.CustomerText[l]; text != "" {
+			return text
+		}
+	}
+	return ""
+}
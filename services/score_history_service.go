@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v4"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/repositories"
+)
+
+var ErrScoreInquiryNotFound = errors.New("score inquiry not found")
+
+// GetScoreInquiries ищет в истории запросов с учётом прав пользователя:
+// супервизор видит запросы своей команды, остальные — только свои.
+func GetScoreInquiries(ctx context.Context, principal models.Principal, filter models.ScoreInquiryFilter) (models.ScoreInquiryPage, error) {
+	if principal.HasRole(models.RoleSupervisor) && principal.Team != "" {
+		filter.Team = principal.Team
+	} else {
+		filter.UserId = principal.UserId
+	}
+	return repositories.FindScoreInquiries(ctx, filter)
+}
+
+func GetScoreInquiryById(ctx context.Context, principal models.Principal, id int64) (models.ScoreInquiry, error) {
+	inquiry, err := repositories.GetScoreInquiryById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ScoreInquiry{}, ErrScoreInquiryNotFound
+	}
+	if err != nil {
+		return models.ScoreInquiry{}, err
+	}
+
+	// Чужие записи не раскрываем даже фактом существования
+	if !canViewScoreInquiry(principal, inquiry) {
+		return models.ScoreInquiry{}, ErrScoreInquiryNotFound
+	}
+	return inquiry, nil
+}
+
+func canViewScoreInquiry(principal models.Principal, inquiry models.ScoreInquiry) bool {
+	if inquiry.UserId == principal.UserId {
+		return true
+	}
+	return principal.HasRole(models.RoleSupervisor) && principal.Team != "" && inquiry.Team == principal.Team
+}
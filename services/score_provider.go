@@ -0,0 +1,245 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-keycloak-jwt/i18n"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/resilience"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ScoreProvider — источник скоринга: кредитное бюро или внутренняя модель.
+// Любой провайдер возвращает нормализованные карты и результат.
+type ScoreProvider interface {
+	Name() string
+	ListScoreCards(ctx context.Context, call ScoreCardsCall) ([]models.ScoreCard, error)
+	Score(ctx context.Context, call ScoreCall) (models.ScoreResult, error)
+}
+
+var (
+	scoreProviders       = map[string]ScoreProvider{}
+	scoreProviderOrder   []string
+	defaultScoreProvider = models.ProviderCreditinfo
+	scoreProviderRoutes  = map[string]string{}
+)
+
+// InitScoreProviders регистрирует провайдеров скоринга; вызывается после InitScoreClient.
+// Creditinfo (SOAP) доступен всегда. Дополнительные провайдеры с HTTP/JSON-интерфейсом
+// задаются в SCORE_PROVIDERS_HTTP ("internal-ml=http://ml:8080,bureau2=http://adapter:8080").
+// Провайдер для карты: явный маршрут из SCORE_PROVIDER_ROUTES ("InternalPD=internal-ml"),
+// затем провайдер, который объявил карту в своём списке, затем SCORE_PROVIDER (по умолчанию creditinfo).
+func InitScoreProviders() {
+	scoreProviders = map[string]ScoreProvider{}
+	scoreProviderOrder = nil
+	registerScoreProvider(&creditinfoProvider{client: scoreClient})
+
+	for name, url := range parseEnvPairs("SCORE_PROVIDERS_HTTP") {
+		registerScoreProvider(newHttpScoreProvider(name, url))
+	}
+
+	defaultScoreProvider = models.ProviderCreditinfo
+	if name := os.Getenv("SCORE_PROVIDER"); name != "" {
+		if _, ok := scoreProviders[name]; !ok {
+			log.Fatalf("unknown SCORE_PROVIDER: %s", name)
+		}
+		defaultScoreProvider = name
+	}
+
+	scoreProviderRoutes = parseEnvPairs("SCORE_PROVIDER_ROUTES")
+	for card, name := range scoreProviderRoutes {
+		if _, ok := scoreProviders[name]; !ok {
+			log.Fatalf("SCORE_PROVIDER_ROUTES: unknown provider %s for score card %s", name, card)
+		}
+	}
+}
+
+func registerScoreProvider(provider ScoreProvider) {
+	if _, exists := scoreProviders[provider.Name()]; !exists {
+		scoreProviderOrder = append(scoreProviderOrder, provider.Name())
+	}
+	scoreProviders[provider.Name()] = provider
+}
+
+// scoreProviderFor выбирает провайдера для скоринговой карты
+func scoreProviderFor(ctx context.Context, tokenString string, principal models.Principal, scoreCard string) ScoreProvider {
+	if name, ok := scoreProviderRoutes[strings.TrimSpace(scoreCard)]; ok {
+		return scoreProviders[name]
+	}
+	if card, found, _ := findScoreCard(ctx, tokenString, principal, scoreCard); found {
+		if provider, ok := scoreProviders[card.Provider]; ok {
+			return provider
+		}
+	}
+	return scoreProviders[defaultScoreProvider]
+}
+
+// "a=b,c=d" из переменной окружения
+func parseEnvPairs(name string) map[string]string {
+	pairs := map[string]string{}
+	for _, item := range strings.Split(os.Getenv(name), ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			continue
+		}
+		if key, value = strings.TrimSpace(key), strings.TrimSpace(value); key != "" && value != "" {
+			pairs[key] = value
+		}
+	}
+	return pairs
+}
+
+// creditinfoProvider — SOAP-сервис Creditinfo через ScoreClient (таймауты, повторы, выключатель)
+type creditinfoProvider struct {
+	client ScoreClient
+}
+
+func (p *creditinfoProvider) Name() string {
+	return models.ProviderCreditinfo
+}
+
+func (p *creditinfoProvider) ListScoreCards(ctx context.Context, call ScoreCardsCall) ([]models.ScoreCard, error) {
+	cards, err := p.client.GetScoreCards(ctx, call)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]models.ScoreCard, 0, len(cards))
+	for _, card := range cards {
+		result = append(result, models.ScoreCardFromXml(p.Name(), card))
+	}
+	return result, nil
+}
+
+func (p *creditinfoProvider) Health() resilience.Snapshot {
+	return BureauHealth()
+}
+
+func (p *creditinfoProvider) Score(ctx context.Context, call ScoreCall) (models.ScoreResult, error) {
+	response, err := p.client.Score(ctx, call)
+	if err != nil {
+		return models.ScoreResult{}, err
+	}
+	return models.ScoreResultFromEnvelope(p.Name(), call.Request.Score.ScoreCard, response), nil
+}
+
+// httpScoreProvider — провайдер с JSON-интерфейсом (адаптер другого бюро или внутренняя ML-модель):
+// POST {url}/score-cards → {"score_cards": [models.ScoreCard]},
+// POST {url}/score с {"score_card", "attributes"} → models.ScoreResult.
+// Токен пользователя наружу не передаётся, только его идентификатор.
+type httpScoreProvider struct {
+	name       string
+	url        string
+	httpClient *http.Client
+	breaker    *resilience.CircuitBreaker
+	timeout    time.Duration
+}
+
+func newHttpScoreProvider(name string, url string) *httpScoreProvider {
+	return &httpScoreProvider{
+		name:       name,
+		url:        strings.TrimRight(url, "/"),
+		httpClient: &http.Client{},
+		breaker: resilience.NewCircuitBreaker(
+			envInt("BUREAU_BREAKER_FAILURES", 5),
+			envDuration("BUREAU_BREAKER_COOLDOWN", 30*time.Second),
+		),
+		timeout: envDuration("SCORE_PROVIDER_TIMEOUT", 15*time.Second),
+	}
+}
+
+func (p *httpScoreProvider) Name() string {
+	return p.name
+}
+
+func (p *httpScoreProvider) Health() resilience.Snapshot {
+	return p.breaker.Snapshot()
+}
+
+func (p *httpScoreProvider) ListScoreCards(ctx context.Context, call ScoreCardsCall) ([]models.ScoreCard, error) {
+	var response struct {
+		ScoreCards []models.ScoreCard `json:"score_cards"`
+	}
+	if err := p.post(ctx, "/score-cards", call.Principal, call.SubjectToken, struct{}{}, &response); err != nil {
+		return nil, err
+	}
+	for i := range response.ScoreCards {
+		response.ScoreCards[i].Provider = p.name
+	}
+	return response.ScoreCards, nil
+}
+
+func (p *httpScoreProvider) Score(ctx context.Context, call ScoreCall) (models.ScoreResult, error) {
+	request := struct {
+		ScoreCard  string      `json:"score_card"`
+		Attributes interface{} `json:"attributes"`
+	}{
+		ScoreCard:  call.Request.Score.ScoreCard,
+		Attributes: call.Request.Score.Attributes,
+	}
+	var result models.ScoreResult
+	if err := p.post(ctx, "/score", call.Principal, call.SubjectToken, request, &result); err != nil {
+		return models.ScoreResult{}, err
+	}
+	result.Provider = p.name
+	if result.ScoreCard == "" {
+		result.ScoreCard = call.Request.Score.ScoreCard
+	}
+	return result, nil
+}
+
+func (p *httpScoreProvider) post(ctx context.Context, path string, principal models.Principal, subjectToken string, body interface{}, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	token, err := mintDownstreamToken(ctx, principal, subjectToken, p.name)
+	if err != nil {
+		return err
+	}
+	return p.breaker.Do(func() error {
+		opCtx, cancel := context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(opCtx, http.MethodPost, p.url+path, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-Id", principal.UserId)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		if locale, ok := i18n.FromContext(ctx); ok {
+			req.Header.Set("Accept-Language", locale)
+		}
+
+		resp, err := p.httpClient.Do(req)
+		if err != nil {
+			return &BureauTransportError{Err: err}
+		}
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return &BureauTransportError{Err: err}
+		}
+		if resp.StatusCode >= 500 {
+			return &BureauTransportError{Err: fmt.Errorf("provider %s returned HTTP %d", p.name, resp.StatusCode)}
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("provider %s returned HTTP %d: %s", p.name, resp.StatusCode, strings.TrimSpace(string(data)))
+		}
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to parse provider %s response: %v", p.name, err)
+		}
+		return nil
+	}, isBureauFailure)
+}
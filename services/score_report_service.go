@@ -0,0 +1,72 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/reports"
+	"go-keycloak-jwt/repositories"
+	"time"
+)
+
+// Шаблон отчёта о скоринге в таблице report_templates
+const scoreReportTemplateName = "score"
+
+// GetScoreReportTemplate возвращает шаблон администратора или шаблон по умолчанию
+func GetScoreReportTemplate(ctx context.Context) (models.ReportTemplate, error) {
+	tpl, found, err := repositories.GetReportTemplate(ctx, scoreReportTemplateName)
+	if err != nil {
+		return models.ReportTemplate{}, err
+	}
+	if !found {
+		return reports.DefaultTemplate(), nil
+	}
+	return tpl, nil
+}
+
+// SaveScoreReportTemplate заменяет шаблон; действует со следующего отчёта
+func SaveScoreReportTemplate(ctx context.Context, principal models.Principal, tpl models.ReportTemplate) (models.ReportTemplate, error) {
+	if err := reports.Validate(tpl); err != nil {
+		return models.ReportTemplate{}, &models.ValidationError{Fields: []models.FieldError{{
+			Field:   "template",
+			Code:    "INVALID_TEMPLATE",
+			Message: err.Error(),
+		}}}
+	}
+	tpl.UpdatedBy = principal.UserName
+	if err := repositories.SaveReportTemplate(ctx, scoreReportTemplateName, &tpl); err != nil {
+		return models.ReportTemplate{}, err
+	}
+	return tpl, nil
+}
+
+// ResetScoreReportTemplate возвращает шаблон по умолчанию
+func ResetScoreReportTemplate(ctx context.Context) error {
+	return repositories.DeleteReportTemplate(ctx, scoreReportTemplateName)
+}
+
+// RenderScoreReport формирует отчёт по запросу скоринга в PDF или XLSX.
+// inquiry передаётся уже с учётом прав пользователя (маскирование персональных данных).
+func RenderScoreReport(ctx context.Context, principal models.Principal, inquiry models.ScoreInquiry, format string) ([]byte, error) {
+	tpl, err := GetScoreReportTemplate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data := reports.Data{Inquiry: inquiry, GeneratedBy: principal.UserName, GeneratedAt: time.Now()}
+
+	// Отчёт собирается в памяти, чтобы при ошибке вернуть JSON, а не оборванный файл
+	var buf bytes.Buffer
+	switch format {
+	case "pdf":
+		err = reports.WritePDF(&buf, tpl, data)
+	case "xlsx":
+		err = reports.WriteXLSX(&buf, tpl, data)
+	default:
+		return nil, fmt.Errorf("unsupported report format: %s", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to render score report: %v", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-keycloak-jwt/db"
+	"go-keycloak-jwt/i18n"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/pii"
+	"go-keycloak-jwt/repositories"
+	"go-keycloak-jwt/resilience"
+	"golang.org/x/time/rate"
+	"log"
+	"strings"
+	"time"
+)
+
+// Дополнительные параметры запроса скоринга
+type ScoreOptions struct {
+	// Игнорировать кэш и обратиться в бюро (только для роли score_cache_bypass)
+	ForceRefresh bool
+	// Допускается теневой скоринг (только для запросов POST /score, не для пакетов)
+	Shadow bool
+	// Ограничение частоты запросов к провайдеру (пакеты); ответы из кэша его не расходуют
+	RateLimiter *rate.Limiter
+}
+
+// Score проверяет запрос и согласие субъекта, выполняет скоринг у провайдера карты
+// и сохраняет результат в score_inquiries.
+// Если для субъекта и карты есть свежий результат в кэше, провайдер не вызывается,
+// а запись ссылается на исходный запрос (cached_from_id).
+// Запись делается даже при ошибке бюро: каждый запрос должен остаться в истории.
+// К успешному результату применяются активные правила кредитного решения.
+func Score(ctx context.Context, tokenString string, principal models.Principal, score models.ScoreRequest, options ScoreOptions) (models.ScoreResult, models.ScoreInquiry, error) {
+	if err := ValidateScoreRequest(ctx, tokenString, principal, score); err != nil {
+		return models.ScoreResult{}, models.ScoreInquiry{}, err
+	}
+
+	consentId, err := requireConsent(ctx, score)
+	if err != nil {
+		return models.ScoreResult{}, models.ScoreInquiry{}, err
+	}
+
+	attributes, err := json.Marshal(score.Score.Attributes)
+	if err != nil {
+		return models.ScoreResult{}, models.ScoreInquiry{}, fmt.Errorf("failed to marshal attributes: %v", err)
+	}
+
+	inquiry := models.ScoreInquiry{
+		UserId:     principal.UserId,
+		UserName:   principal.UserName,
+		Team:       principal.Team,
+		SubjectIin: subjectIin(score),
+		ScoreCard:  score.Score.ScoreCard,
+		Attributes: attributes,
+		ConsentId:  consentId,
+	}
+
+	provider := scoreProviderFor(ctx, tokenString, principal, score.Score.ScoreCard)
+	inquiry.Provider = provider.Name()
+
+	// Причины в ответе на языке запроса, поэтому язык входит в ключ кэша
+	locale, _ := i18n.FromContext(ctx)
+	cacheKey := scoreCacheKey(provider.Name(), locale, score)
+	if !options.ForceRefresh {
+		entry, found, err := scoreCache.Get(ctx, cacheKey)
+		if err != nil {
+			log.Printf("score cache lookup failed: %v", err)
+		}
+		if found {
+			fillScoreInquiry(&inquiry, entry.Result)
+			inquiry.CachedFromId = &entry.InquiryId
+			inquiry.InquiredAt = entry.InquiredAt
+			applyDecisionRules(ctx, &inquiry)
+			if err := saveScoreInquiry(ctx, &inquiry, nil); err != nil {
+				return models.ScoreResult{}, models.ScoreInquiry{}, err
+			}
+			return entry.Result, inquiry, nil
+		}
+	}
+
+	// Ответ из кэша бесплатен, поэтому квоты проверяются только перед запросом к провайдеру
+	if err := checkScoreQuota(ctx, principal, score.Score.ScoreCard); err != nil {
+		return models.ScoreResult{}, models.ScoreInquiry{}, err
+	}
+
+	if options.RateLimiter != nil {
+		if err := options.RateLimiter.Wait(ctx); err != nil {
+			return models.ScoreResult{}, models.ScoreInquiry{}, fmt.Errorf("rate limiter: %v", err)
+		}
+	}
+
+	callCtx, exchange := withBureauExchange(ctx)
+	started := time.Now()
+	result, callErr := provider.Score(callCtx, ScoreCall{
+		SubjectToken: tokenString,
+		Principal:    principal,
+		Request:      score,
+	})
+	inquiry.LatencyMs = time.Since(started).Milliseconds()
+	inquiry.InquiredAt = started
+
+	// Выключатель не пропустил запрос — провайдеру ничего не ушло, записывать нечего
+	var openErr *resilience.OpenError
+	if errors.As(callErr, &openErr) {
+		return models.ScoreResult{}, models.ScoreInquiry{}, callErr
+	}
+
+	if callErr != nil {
+		inquiry.ErrorCode = "-1"
+		inquiry.ErrorString = pii.RedactText(callErr.Error())
+	} else {
+		fillScoreInquiry(&inquiry, result)
+		applyDecisionRules(ctx, &inquiry)
+	}
+
+	if err := saveScoreInquiry(ctx, &inquiry, exchange); err != nil {
+		return models.ScoreResult{}, models.ScoreInquiry{}, err
+	}
+	if options.Shadow {
+		startShadowScore(tokenString, principal, score, inquiry)
+	}
+	if callErr != nil {
+		return models.ScoreResult{}, inquiry, callErr
+	}
+
+	// Кэшируем только успешные ответы
+	if inquiry.ErrorCode == "0" {
+		entry := models.ScoreCacheEntry{Result: result, InquiryId: inquiry.Id, InquiredAt: inquiry.InquiredAt}
+		if err := scoreCache.Set(ctx, cacheKey, score.Score.ScoreCard, entry); err != nil {
+			log.Printf("score cache store failed: %v", err)
+		}
+	}
+	return result, inquiry, nil
+}
+
+func fillScoreInquiry(inquiry *models.ScoreInquiry, result models.ScoreResult) {
+	inquiry.IdQuery = result.IdQuery
+	inquiry.ErrorCode = result.ErrorCode
+	inquiry.ErrorString = result.ErrorString
+	inquiry.Score = result.Score
+	inquiry.OneYearProbabilityOfDefault = result.OneYearProbabilityOfDefault
+	inquiry.RiskGrade = result.RiskGrade
+	inquiry.ScoreByML = result.ScoreByML
+	inquiry.OneYearProbabilityOfDefaultByML = result.OneYearProbabilityOfDefaultByML
+	inquiry.RiskGradeByML = result.RiskGradeByML
+	inquiry.Causes = result.Causes
+}
+
+// ИИН субъекта, если карта запрашивается по атрибуту IIN
+func subjectIin(score models.ScoreRequest) string {
+	if strings.EqualFold(score.Score.Attributes.Name, "IIN") {
+		return strings.TrimSpace(score.Score.Attributes.Value)
+	}
+	return ""
+}
+
+// saveScoreInquiry сохраняет запрос и, если бюро ответило, зашифрованный ответ в архив — в одной транзакции
+func saveScoreInquiry(ctx context.Context, inquiry *models.ScoreInquiry, exchange *bureauExchange) error {
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		// После Commit откат ничего не делает
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err := repositories.CreateScoreInquiry(ctx, tx, inquiry); err != nil {
+		return err
+	}
+	archive, err := sealBureauExchange(exchange, inquiry.Id)
+	if err != nil {
+		return err
+	}
+	if archive != nil {
+		if err := repositories.CreateBureauArchiveRecord(ctx, tx, archive); err != nil {
+			return fmt.Errorf("failed to archive bureau response: %v", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit score inquiry: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/pii"
+	"go-keycloak-jwt/repositories"
+	"log"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+)
+
+// Теневой скоринг: доля запросов POST /score дополнительно и асинхронно отправляется
+// вторичному провайдеру, чтобы сравнить его с основным на реальном потоке перед переключением.
+// Теневой результат сохраняется рядом с основным и вызывающему никогда не возвращается.
+var scoreShadow struct {
+	provider ScoreProvider
+	percent  float64
+	// Карта теневого провайдера для карты основного, если имена различаются
+	cards   map[string]string
+	timeout time.Duration
+	// Ограничивает число одновременных теневых запросов; лишние отбрасываются
+	slots chan struct{}
+}
+
+// InitScoreShadow читает SCORE_SHADOW_PROVIDER и SCORE_SHADOW_PERCENT; вызывается после InitScoreProviders
+func InitScoreShadow() {
+	scoreShadow.provider = nil
+	name := os.Getenv("SCORE_SHADOW_PROVIDER")
+	if name == "" {
+		return
+	}
+	provider, ok := scoreProviders[name]
+	if !ok {
+		log.Fatalf("unknown SCORE_SHADOW_PROVIDER: %s", name)
+	}
+	percent := envFloat("SCORE_SHADOW_PERCENT", 0)
+	if percent > 100 {
+		log.Fatalf("SCORE_SHADOW_PERCENT must be between 0 and 100")
+	}
+
+	scoreShadow.provider = provider
+	scoreShadow.percent = percent
+	scoreShadow.cards = parseEnvPairs("SCORE_SHADOW_CARDS")
+	scoreShadow.timeout = envDuration("SCORE_SHADOW_TIMEOUT", 30*time.Second)
+	scoreShadow.slots = make(chan struct{}, envInt("SCORE_SHADOW_CONCURRENCY", 4))
+}
+
+// startShadowScore с вероятностью SCORE_SHADOW_PERCENT отправляет запрос теневому провайдеру в фоне.
+// Не вызывается, если основной провайдер и есть теневой.
+func startShadowScore(tokenString string, principal models.Principal, score models.ScoreRequest, inquiry models.ScoreInquiry) {
+	provider := scoreShadow.provider
+	if provider == nil || provider.Name() == inquiry.Provider || rand.Float64()*100 >= scoreShadow.percent {
+		return
+	}
+
+	select {
+	case scoreShadow.slots <- struct{}{}:
+	default:
+		log.Printf("shadow scoring skipped for inquiry %d: too many requests in flight", inquiry.Id)
+		return
+	}
+
+	if card, ok := scoreShadow.cards[strings.TrimSpace(score.Score.ScoreCard)]; ok {
+		score.Score.ScoreCard = card
+	}
+
+	go func() {
+		defer func() {
+			<-scoreShadow.slots
+		}()
+		runShadowScore(provider, tokenString, principal, score, inquiry.Id)
+	}()
+}
+
+func runShadowScore(provider ScoreProvider, tokenString string, principal models.Principal, score models.ScoreRequest, inquiryId int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), scoreShadow.timeout)
+	defer cancel()
+
+	started := time.Now()
+	result, err := provider.Score(ctx, ScoreCall{
+		SubjectToken: tokenString,
+		Principal:    principal,
+		Request:      score,
+	})
+	shadow := models.ScoreShadowResult{
+		InquiryId: inquiryId,
+		Provider:  provider.Name(),
+		ScoreCard: score.Score.ScoreCard,
+		LatencyMs: time.Since(started).Milliseconds(),
+	}
+	if err != nil {
+		shadow.ErrorCode = "-1"
+		shadow.ErrorString = pii.RedactText(err.Error())
+	} else {
+		shadow.IdQuery = result.IdQuery
+		shadow.ErrorCode = result.ErrorCode
+		shadow.ErrorString = pii.RedactText(result.ErrorString)
+		shadow.Score = result.Score
+		shadow.OneYearProbabilityOfDefault = result.OneYearProbabilityOfDefault
+		shadow.RiskGrade = result.RiskGrade
+		shadow.ScoreByML = result.ScoreByML
+		shadow.OneYearProbabilityOfDefaultByML = result.OneYearProbabilityOfDefaultByML
+		shadow.RiskGradeByML = result.RiskGradeByML
+	}
+
+	if err := repositories.CreateScoreShadowResult(ctx, &shadow); err != nil {
+		log.Printf("failed to save shadow score for inquiry %d: %v", inquiryId, err)
+	}
+}
+
+// GetScoreShadowComparison — доля совпадений класса риска и разница баллов теневого и основного провайдеров.
+// Доля совпадений считается среди пар, где класс вернули оба провайдера
+func GetScoreShadowComparison(ctx context.Context, filter models.ScoreShadowFilter) ([]models.ScoreShadowComparison, error) {
+	comparisons, err := repositories.CompareScoreShadowResults(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for i := range comparisons {
+		c := &comparisons[i]
+		c.RiskGradeAgreement = agreementRate(c.RiskGradeMatches, c.RiskGradeCompared)
+		c.RiskGradeByMLAgreement = agreementRate(c.RiskGradeByMLMatches, c.RiskGradeByMLCompared)
+	}
+	return comparisons, nil
+}
+
+func agreementRate(matches int64, compared int64) *float64 {
+	if compared == 0 {
+		return nil
+	}
+	rate := float64(matches) / float64(compared)
+	return &rate
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"github.com/golang-jwt/jwt/v4"
+	"go-keycloak-jwt/models"
+	"go-keycloak-jwt/pii"
+	"strconv"
+	"time"
+)
+
+// SignScoreResult возвращает компактный JWS над нормализованным результатом скоринга, чтобы получатель
+// (например, кредитный конвейер) мог доказать, что результат выдан сервисом и не изменён.
+// ИИН в подписи маскируется так же, как в ответе, если пользователю нельзя видеть персональные данные.
+func SignScoreResult(principal models.Principal, inquiry models.ScoreInquiry, result models.ScoreResult) (string, error) {
+	subject := inquiry.SubjectIin
+	if !principal.CanViewPII() {
+		subject = pii.MaskIIN(subject)
+	}
+	claims := jwt.MapClaims{
+		"iss":         serviceIssuer(),
+		"iat":         time.Now().Unix(),
+		"jti":         strconv.FormatInt(inquiry.Id, 10),
+		"inquiry_id":  inquiry.Id,
+		"user_id":     principal.UserId,
+		"subject_iin": subject,
+		"cached":      inquiry.CachedFromId != nil,
+		"inquired_at": inquiry.InquiredAt.UTC().Format(time.RFC3339),
+		"result":      result,
+	}
+	if decision := ScoreDecisionOf(inquiry); decision != nil {
+		claims["decision"] = decision
+	}
+	return signServiceToken(claims, scoreResultTokenType)
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-keycloak-jwt/iin"
+	"go-keycloak-jwt/models"
+	"strings"
+)
+
+const (
+	scoreCardField      = "Score.ScoreCard"
+	attributeNameField  = "Score.attributes.name"
+	attributeValueField = "Score.attributes.value"
+)
+
+// ValidateScoreRequest проверяет запрос до обращения в бюро, чтобы опечатки
+// не превращались в оплачиваемые запросы. Если карта объявляет атрибут IIN
+// (или BIN), значение обязано быть корректным ИИН (БИН).
+func ValidateScoreRequest(ctx context.Context, tokenString string, principal models.Principal, score models.ScoreRequest) error {
+	var fields []models.FieldError
+	attribute := score.Score.Attributes
+
+	card, found, ok := findScoreCard(ctx, tokenString, principal, score.Score.ScoreCard)
+	if ok && !found {
+		fields = append(fields, models.FieldError{
+			Field:   scoreCardField,
+			Code:    "UNKNOWN_SCORE_CARD",
+			Message: "unknown score card",
+		})
+	}
+
+	if found {
+		for _, declared := range card.Attributes {
+			name := strings.ToUpper(strings.TrimSpace(declared))
+			if name != "IIN" && name != "BIN" {
+				continue
+			}
+			if !strings.EqualFold(strings.TrimSpace(attribute.Name), name) {
+				fields = append(fields, models.FieldError{
+					Field:   attributeNameField,
+					Code:    name + "_REQUIRED",
+					Message: "score card requires the " + name + " attribute",
+				})
+			}
+		}
+	}
+
+	// Значение проверяем по имени атрибута, даже если описания карт недоступны
+	switch strings.ToUpper(strings.TrimSpace(attribute.Name)) {
+	case "IIN":
+		if _, err := iin.ParseIIN(strings.TrimSpace(attribute.Value)); err != nil {
+			fields = append(fields, identifierFieldError("IIN", err))
+		}
+	case "BIN":
+		if _, err := iin.ParseBIN(strings.TrimSpace(attribute.Value)); err != nil {
+			fields = append(fields, identifierFieldError("BIN", err))
+		}
+	}
+
+	if len(fields) > 0 {
+		return &models.ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+func identifierFieldError(kind string, err error) models.FieldError {
+	code := kind + "_INVALID"
+	switch {
+	case errors.Is(err, iin.ErrFormat):
+		code = kind + "_FORMAT"
+	case errors.Is(err, iin.ErrChecksum):
+		code = kind + "_CHECKSUM"
+	case errors.Is(err, iin.ErrBirthDate):
+		code = kind + "_BIRTH_DATE"
+	case errors.Is(err, iin.ErrCentury):
+		code = kind + "_CENTURY"
+	case errors.Is(err, iin.ErrNotIIN), errors.Is(err, iin.ErrNotBIN):
+		code = kind + "_WRONG_KIND"
+	case errors.Is(err, iin.ErrBINDate):
+		code = kind + "_REGISTRATION_DATE"
+	case errors.Is(err, iin.ErrBINType):
+		code = kind + "_ENTITY_TYPE"
+	}
+	return models.FieldError{
+		Field:   attributeValueField,
+		Code:    code,
+		Message: kind + " " + err.Error(),
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"crypto/rsa"
+	"errors"
+	"github.com/golang-jwt/jwt/v4"
+	"go-keycloak-jwt/helpers"
+	"go-keycloak-jwt/models"
+	"log"
+	"os"
+	"strings"
+)
+
+var ErrServiceKeysDisabled = errors.New("service signing keys are not configured")
+
+// Минимальный размер RSA-ключа подписи
+const minServiceKeyBits = 2048
+
+// Тип JWS в заголовке typ: токены доступа и подписанные результаты подписываются одними ключами,
+// и получатель по типу не примет результат скоринга за токен доступа (RFC 8725, разд. 3.11)
+const (
+	serviceAccessTokenType = "at+jwt"
+	scoreResultTokenType   = "score-result+jwt"
+)
+
+// Ключи подписи сервиса по kid; подписывается текущим, остальные публикуются в JWKS,
+// пока получатели могут проверять подписанное ими до ротации
+type serviceKeyring struct {
+	currentId string
+	ids       []string
+	keys      map[string]*rsa.PrivateKey
+}
+
+var serviceKeys *serviceKeyring
+
+// InitServiceKeys читает ключи подписи сервиса:
+// SERVICE_SIGNING_KEYS — список "kid:путь к PEM" через запятую (RSA, PKCS#1 или PKCS#8),
+// SERVICE_SIGNING_KEY_ID — ключ для новых подписей (по умолчанию первый в списке).
+// Без ключей подпись ответов выключена.
+func InitServiceKeys() {
+	value := os.Getenv("SERVICE_SIGNING_KEYS")
+	if value == "" {
+		log.Print("SERVICE_SIGNING_KEYS is not set, signed responses are disabled")
+		serviceKeys = nil
+		return
+	}
+
+	keyring := &serviceKeyring{keys: map[string]*rsa.PrivateKey{}}
+	for _, item := range strings.Split(value, ",") {
+		kid, path, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok || kid == "" || path == "" {
+			log.Fatalf("invalid SERVICE_SIGNING_KEYS entry %q, expected kid:path", item)
+		}
+		if _, exists := keyring.keys[kid]; exists {
+			log.Fatalf("duplicate SERVICE_SIGNING_KEYS kid %s", kid)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("failed to read signing key %s: %v", kid, err)
+		}
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+		if err != nil {
+			log.Fatalf("invalid signing key %s: %v", kid, err)
+		}
+		if key.N.BitLen() < minServiceKeyBits {
+			log.Fatalf("signing key %s is %d bits, at least %d required", kid, key.N.BitLen(), minServiceKeyBits)
+		}
+		keyring.keys[kid] = key
+		keyring.ids = append(keyring.ids, kid)
+		if keyring.currentId == "" {
+			keyring.currentId = kid
+		}
+	}
+	if kid := os.Getenv("SERVICE_SIGNING_KEY_ID"); kid != "" {
+		if _, ok := keyring.keys[kid]; !ok {
+			log.Fatalf("SERVICE_SIGNING_KEY_ID %s is not in SERVICE_SIGNING_KEYS", kid)
+		}
+		keyring.currentId = kid
+	}
+	serviceKeys = keyring
+}
+
+func ServiceSigningEnabled() bool {
+	return serviceKeys != nil
+}
+
+// serviceIssuer — значение iss в токенах и подписях сервиса
+func serviceIssuer() string {
+	if issuer := os.Getenv("SERVICE_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "go-keycloak-jwt"
+}
+
+// signServiceToken подписывает claims текущим ключом (RS256) и возвращает компактный JWS с типом typ
+func signServiceToken(claims jwt.Claims, typ string) (string, error) {
+	if serviceKeys == nil {
+		return "", ErrServiceKeysDisabled
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = serviceKeys.currentId
+	token.Header["typ"] = typ
+	return token.SignedString(serviceKeys.keys[serviceKeys.currentId])
+}
+
+// ServiceJWKS — открытые ключи подписи сервиса для /.well-known/jwks.json
+func ServiceJWKS() models.JWKSet {
+	set := models.JWKSet{Keys: []models.JWK{}}
+	if serviceKeys == nil {
+		return set
+	}
+	for _, kid := range serviceKeys.ids {
+		set.Keys = append(set.Keys, helpers.PublicKeyJWK(kid, &serviceKeys.keys[kid].PublicKey))
+	}
+	return set
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"github.com/golang-jwt/jwt/v4"
+	"go-keycloak-jwt/models"
+	"testing"
+	"time"
+)
+
+func withTestServiceKeys(t *testing.T) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, minServiceKeyBits)
+	if err != nil {
+		t.Fatalf("GenerateKey error = %v", err)
+	}
+	previous := serviceKeys
+	serviceKeys = &serviceKeyring{currentId: "test", ids: []string{"test"}, keys: map[string]*rsa.PrivateKey{"test": key}}
+	t.Cleanup(func() { serviceKeys = previous })
+}
+
+func tokenHeader(t *testing.T, token string) map[string]interface{} {
+	t.Helper()
+	parsed, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified error = %v", err)
+	}
+	return parsed.Header
+}
+
+// Токен доступа и подписанный результат подписаны одним ключом, но различаются типом
+func TestServiceTokenTypes(t *testing.T) {
+	withTestServiceKeys(t)
+	principal := models.Principal{UserId: "user-1"}
+
+	accessToken, err := serviceTokenMinter{ttl: time.Minute}.Mint(context.Background(), principal, "", "https://bureau.example.kz")
+	if err != nil {
+		t.Fatalf("Mint error = %v", err)
+	}
+	if typ := tokenHeader(t, accessToken)["typ"]; typ != serviceAccessTokenType {
+		t.Errorf("access token typ = %v, want %s", typ, serviceAccessTokenType)
+	}
+
+	signed, err := SignScoreResult(principal, models.ScoreInquiry{Id: 1}, models.ScoreResult{})
+	if err != nil {
+		t.Fatalf("SignScoreResult error = %v", err)
+	}
+	if typ := tokenHeader(t, signed)["typ"]; typ != scoreResultTokenType {
+		t.Errorf("score result typ = %v, want %s", typ, scoreResultTokenType)
+	}
+}
+
+func TestServiceTokenRequiresAudienceAndLifetime(t *testing.T) {
+	withTestServiceKeys(t)
+	principal := models.Principal{UserId: "user-1"}
+
+	if _, err := (serviceTokenMinter{ttl: time.Minute}).Mint(context.Background(), principal, "", ""); err == nil {
+		t.Error("Mint issued a token without an audience")
+	}
+	if _, err := (serviceTokenMinter{}).Mint(context.Background(), principal, "", "creditinfo"); err == nil {
+		t.Error("Mint issued a token without a lifetime")
+	}
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"go-keycloak-jwt/i18n"
+	"go-keycloak-jwt/models"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const creditinfoNamespace = "http://score.ws.creditinfo.com/"
+const soapEnvelopeNamespace = "http://schemas.xmlsoap.org/soap/envelope/"
+
+// BureauTransportError — бюро недоступно: сеть, таймаут, 5xx без SOAP Fault или с Fault класса Server.
+// Только такие ошибки учитываются выключателем и повторами.
+type BureauTransportError struct {
+	Err error
+}
+
+func (e *BureauTransportError) Error() string {
+	return fmt.Sprintf("bureau transport error: %v", e.Err)
+}
+
+func (e *BureauTransportError) Unwrap() error {
+	return e.Err
+}
+
+type bureauCredentialKey struct{}
+
+// credentialResolver — клиент бюро, которому нужны учётные данные. resilientScoreClient выбирает их
+// до выключателя: ошибка хранилища — не ответ бюро и не должна сбрасывать счётчик сбоев
+type credentialResolver interface {
+	resolveCredential(ctx context.Context, principal models.Principal) (models.BureauCredential, error)
+}
+
+func withBureauCredential(ctx context.Context, credential models.BureauCredential) context.Context {
+	return context.WithValue(ctx, bureauCredentialKey{}, credential)
+}
+
+// soapScoreClient обращается к SOAP-сервису скоринга бюро по адресу BUREAU_URL
+// Учётные данные бюро берутся из CredentialStore для каждого запроса;
+// без хранилища (воспроизведение записей) заголовки с ними не заполняются
+type soapScoreClient struct {
+	url         string
+	httpClient  *http.Client
+	credentials CredentialStore
+}
+
+func newSoapScoreClient(url string, credentials CredentialStore) *soapScoreClient {
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: 5 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
+		TLSHandshakeTimeout:   5 * time.Second,
+		MaxIdleConns:          20,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+	// Общий таймаут не задаём: сроки для каждой операции приходят через context
+	httpClient := &http.Client{Transport: newTrafficTransport(transport)}
+	return &soapScoreClient{url: url, httpClient: httpClient, credentials: credentials}
+}
+
+func (s *soapScoreClient) GetScoreCards(ctx context.Context, call ScoreCardsCall) ([]models.ScoreCardsReturnDataXml, error) {
+	body := models.SoapRequestBodyXml{GetScoreCards: &models.GetScoreCardsRequestXml{Xmlns: creditinfoNamespace}}
+
+	var envelope models.ScoreCardsEnvelopeXml
+	if err := s.do(ctx, "GetScoreCards", call.SubjectToken, call.Principal, body, &envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Body.GetScoreCardsResponse.ReturnData, nil
+}
+
+func (s *soapScoreClient) Score(ctx context.Context, call ScoreCall) (models.ScoreResponseXml, error) {
+	attributes := call.Request.Score.Attributes
+	request := &models.ScoreRequestXml{
+		Xmlns:      creditinfoNamespace,
+		ScoreCard:  call.Request.Score.ScoreCard,
+		Attributes: models.ScoreRequestAttributeXml{Name: attributes.Name, Value: attributes.Value},
+	}
+	if attributes.Values.Id != "" || attributes.Values.Value != "" {
+		request.Attributes.Values = &models.ScoreRequestAttributeValuesXml{
+			Id:    attributes.Values.Id,
+			Value: attributes.Values.Value,
+		}
+	}
+
+	var response models.ScoreResponseXml
+	body := models.SoapRequestBodyXml{Score: request}
+	if err := s.do(ctx, "Score", call.SubjectToken, call.Principal, body, &response.Envelope); err != nil {
+		return models.ScoreResponseXml{}, err
+	}
+	return response, nil
+}
+
+// resolveCredential — учётные данные, уже выбранные вызывающим, иначе из хранилища
+func (s *soapScoreClient) resolveCredential(ctx context.Context, principal models.Principal) (models.BureauCredential, error) {
+	if credential, ok := ctx.Value(bureauCredentialKey{}).(models.BureauCredential); ok {
+		return credential, nil
+	}
+	if s.credentials == nil {
+		return withCredentialDefaults(models.BureauCredential{}), nil
+	}
+	return s.credentials.Lookup(ctx, principal)
+}
+
+func (s *soapScoreClient) do(ctx context.Context, operation string, subjectToken string, principal models.Principal, body models.SoapRequestBodyXml, out interface{}) error {
+	credential, err := s.resolveCredential(ctx, principal)
+	if err != nil {
+		return err
+	}
+	// Язык ответа бюро (тексты причин) — язык, явно выбранный пользователем (заголовок или профиль);
+	// иначе, как и в фоновых задачах, — из учётных данных
+	if locale, ok := i18n.ExplicitFromContext(ctx); ok {
+		credential.Culture = i18n.Culture(locale)
+	}
+
+	securityToken, err := mintDownstreamToken(ctx, principal, subjectToken, models.ProviderCreditinfo)
+	if err != nil {
+		return err
+	}
+
+	payload, err := xml.Marshal(models.SoapRequestEnvelopeXml{XmlnsS: soapEnvelopeNamespace, Body: body})
+	if err != nil {
+		return fmt.Errorf("failed to build %s request: %v", operation, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(append([]byte(xml.Header), payload...)))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %v", operation, err)
+	}
+	req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+	req.Header.Set("SOAPAction", `"`+creditinfoNamespace+operation+`"`)
+	req.Header.Set("Culture", credential.Culture)
+	req.Header.Set("Password", credential.Password)
+	if securityToken != "" {
+		req.Header.Set("SecurityToken", securityToken)
+	}
+	req.Header.Set("UserId", principal.UserId)
+	req.Header.Set("UserName", credential.UserName)
+	req.Header.Set("Version", credential.Version)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return &BureauTransportError{Err: err}
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &BureauTransportError{Err: err}
+	}
+	captureBureauExchange(ctx, operation, resp.StatusCode, data)
+
+	if resp.StatusCode != http.StatusOK {
+		var fault models.SoapFaultEnvelopeXml
+		if xml.Unmarshal(data, &fault) == nil && fault.Body.Fault != nil {
+			faultErr := fmt.Errorf("bureau %s fault %s: %s", operation, fault.Body.Fault.Code, fault.Body.Fault.String)
+			// Fault класса Server с 5xx — сбой самого бюро; Client — ошибка в запросе, бюро работает
+			if resp.StatusCode >= 500 && isServerFault(fault.Body.Fault.Code) {
+				return &BureauTransportError{Err: faultErr}
+			}
+			return faultErr
+		}
+		statusErr := fmt.Errorf("bureau %s returned HTTP %d", operation, resp.StatusCode)
+		if resp.StatusCode >= 500 {
+			return &BureauTransportError{Err: statusErr}
+		}
+		return statusErr
+	}
+
+	if err := xml.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse %s response: %v", operation, err)
+	}
+	return nil
+}
+
+// isServerFault — faultcode класса Server (SOAP 1.1) или Receiver (SOAP 1.2), с префиксом и подкодами
+func isServerFault(code string) bool {
+	code = strings.TrimSpace(code)
+	if i := strings.LastIndex(code, ":"); i >= 0 {
+		code = code[i+1:]
+	}
+	class := strings.SplitN(code, ".", 2)[0]
+	return class == "Server" || class == "Receiver"
+}
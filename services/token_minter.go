@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"go-keycloak-jwt/models"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Режимы выдачи токенов для внешних систем (DOWNSTREAM_TOKENS)
+const (
+	// Сервис сам подписывает короткоживущий JWT ключом из SERVICE_SIGNING_KEYS
+	DownstreamTokensService = "service"
+	// Обмен токена пользователя в Keycloak (RFC 8693)
+	DownstreamTokensExchange = "exchange"
+	// Внешним системам токен не передаётся
+	DownstreamTokensNone = "none"
+)
+
+// TokenMinter выпускает токен для одного обращения к внешней системе (audience) от имени пользователя.
+// Токен пользователя (subjectToken) остаётся внутри сервиса и наружу не передаётся.
+type TokenMinter interface {
+	Mint(ctx context.Context, principal models.Principal, subjectToken string, audience string) (string, error)
+}
+
+var tokenMinter TokenMinter = noTokenMinter{}
+
+// Audience по имени провайдера; по умолчанию совпадает с именем
+var downstreamAudiences map[string]string
+
+// InitTokenMinter выбирает способ выдачи токенов для бюро и провайдеров; вызывается после InitServiceKeys.
+// DOWNSTREAM_TOKENS: service (по умолчанию, если есть ключи подписи), exchange или none.
+func InitTokenMinter() {
+	downstreamAudiences = parseEnvPairs("DOWNSTREAM_TOKEN_AUDIENCES")
+	ttl := envDuration("DOWNSTREAM_TOKEN_TTL", 2*time.Minute)
+
+	mode := os.Getenv("DOWNSTREAM_TOKENS")
+	if mode == "" {
+		mode = DownstreamTokensNone
+		if ServiceSigningEnabled() {
+			mode = DownstreamTokensService
+		}
+	}
+	switch mode {
+	case DownstreamTokensService:
+		if !ServiceSigningEnabled() {
+			log.Fatal("DOWNSTREAM_TOKENS=service requires SERVICE_SIGNING_KEYS")
+		}
+		if ttl <= 0 {
+			log.Fatal("DOWNSTREAM_TOKEN_TTL must be positive")
+		}
+		tokenMinter = serviceTokenMinter{ttl: ttl}
+	case DownstreamTokensExchange:
+		if os.Getenv("TOKEN_URL") == "" || os.Getenv("CLIENT_ID") == "" {
+			log.Fatal("DOWNSTREAM_TOKENS=exchange requires TOKEN_URL and CLIENT_ID")
+		}
+		tokenMinter = newKeycloakTokenExchanger()
+	case DownstreamTokensNone:
+		log.Print("DOWNSTREAM_TOKENS=none, downstream calls carry no token")
+		tokenMinter = noTokenMinter{}
+	default:
+		log.Fatalf("unknown DOWNSTREAM_TOKENS: %s", mode)
+	}
+}
+
+// mintDownstreamToken — токен для обращения к провайдеру; пустая строка, если токены не передаются
+func mintDownstreamToken(ctx context.Context, principal models.Principal, subjectToken string, provider string) (string, error) {
+	audience := provider
+	if value, ok := downstreamAudiences[provider]; ok {
+		audience = value
+	}
+	token, err := tokenMinter.Mint(ctx, principal, strings.TrimPrefix(subjectToken, "Bearer "), audience)
+	if err != nil {
+		return "", fmt.Errorf("failed to issue token for %s: %v", provider, err)
+	}
+	return token, nil
+}
+
+type noTokenMinter struct{}
+
+func (noTokenMinter) Mint(context.Context, models.Principal, string, string) (string, error) {
+	return "", nil
+}
+
+// serviceTokenMinter подписывает JWT сервиса: sub — сервис, aud — внешняя система,
+// act — пользователь, от имени которого выполняется обращение
+type serviceTokenMinter struct {
+	ttl time.Duration
+}
+
+func (m serviceTokenMinter) Mint(_ context.Context, principal models.Principal, _ string, audience string) (string, error) {
+	// Токен доступа без получателя и срока действия принял бы кто угодно и когда угодно
+	if audience == "" || m.ttl <= 0 {
+		return "", errors.New("service token requires an audience and a positive lifetime")
+	}
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	act := map[string]string{"sub": principal.UserId}
+	if principal.UserName != "" {
+		act["preferred_username"] = principal.UserName
+	}
+	claims := jwt.MapClaims{
+		"iss": serviceIssuer(),
+		"sub": serviceIssuer(),
+		"aud": audience,
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+		"exp": now.Add(m.ttl).Unix(),
+		"jti": hex.EncodeToString(jti),
+		"act": act,
+	}
+	return signServiceToken(claims, serviceAccessTokenType)
+}
+
+// keycloakTokenExchanger обменивает токен пользователя на токен с audience внешней системы (RFC 8693).
+// В фоновых задачах токена пользователя нет — тогда запрашивается токен клиента (client_credentials).
+// Токены кэшируются до истечения, чтобы не ходить в Keycloak на каждый запрос.
+type keycloakTokenExchanger struct {
+	tokenUrl     string
+	clientId     string
+	clientSecret string
+	httpClient   *http.Client
+
+	mu     sync.Mutex
+	tokens map[string]exchangedToken
+}
+
+type exchangedToken struct {
+	token     string
+	expiresAt time.Time
+}
+
+func newKeycloakTokenExchanger() *keycloakTokenExchanger {
+	return &keycloakTokenExchanger{
+		tokenUrl:     os.Getenv("TOKEN_URL"),
+		clientId:     os.Getenv("CLIENT_ID"),
+		clientSecret: os.Getenv("CLIENT_SECRET"),
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+		tokens:       map[string]exchangedToken{},
+	}
+}
+
+func (k *keycloakTokenExchanger) Mint(ctx context.Context, _ models.Principal, subjectToken string, audience string) (string, error) {
+	sum := sha256.Sum256([]byte(subjectToken + "\x00" + audience))
+	key := hex.EncodeToString(sum[:])
+
+	k.mu.Lock()
+	cached, ok := k.tokens[key]
+	k.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.token, nil
+	}
+
+	form := url.Values{}
+	form.Set("client_id", k.clientId)
+	form.Set("client_secret", k.clientSecret)
+	form.Set("audience", audience)
+	if subjectToken != "" {
+		form.Set("grant_type", "urn:ietf:params:oauth:grant-type:token-exchange")
+		form.Set("subject_token", subjectToken)
+		form.Set("subject_token_type", "urn:ietf:params:oauth:token-type:access_token")
+		form.Set("requested_token_type", "urn:ietf:params:oauth:token-type:access_token")
+	} else {
+		form.Set("grant_type", "client_credentials")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.tokenUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token exchange returned HTTP %d", resp.StatusCode)
+	}
+
+	var response struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil || response.AccessToken == "" {
+		return "", fmt.Errorf("invalid token exchange response")
+	}
+
+	// Запас, чтобы токен не истёк по дороге к внешней системе
+	expiresAt := time.Now().Add(time.Duration(response.ExpiresIn)*time.Second - 30*time.Second)
+	k.mu.Lock()
+	for cachedKey, token := range k.tokens {
+		if time.Now().After(token.expiresAt) {
+			delete(k.tokens, cachedKey)
+		}
+	}
+	k.tokens[key] = exchangedToken{token: response.AccessToken, expiresAt: expiresAt}
+	k.mu.Unlock()
+	return response.AccessToken, nil
+}
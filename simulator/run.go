@@ -0,0 +1,48 @@
+package simulator
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"net/http/httptest"
+)
+
+// NewTestServer поднимает симулятор на случайном локальном порту.
+// Адрес для BUREAU_URL — server.URL; сбои меняются через Simulator.SetFaults.
+func NewTestServer(config Config) (*httptest.Server, *Simulator) {
+	sim := New(config)
+	return httptest.NewServer(sim), sim
+}
+
+// Run запускает симулятор как отдельный процесс: go run . simulator -addr :8090
+func Run(args []string) {
+	flags := flag.NewFlagSet("simulator", flag.ExitOnError)
+	addr := flags.String("addr", ":8090", "адрес, на котором слушать")
+	fixtures := flags.String("fixtures", "", "каталог с фикстурами <ИИН>.json")
+	latency := flags.Duration("latency", 0, "задержка каждого ответа")
+	faultRate := flags.Float64("fault-rate", 0, "доля ответов SOAP Fault (0..1)")
+	unavailableRate := flags.Float64("unavailable-rate", 0, "доля ответов HTTP 503 (0..1)")
+	errorCode := flags.String("error-code", "", "код ошибки бюро во всех ответах Score")
+	errorString := flags.String("error-string", "Simulated bureau error", "текст ошибки бюро для -error-code")
+	if err := flags.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+
+	config := Config{
+		FixturesDir: *fixtures,
+		Faults: Faults{
+			Latency:         *latency,
+			FaultRate:       *faultRate,
+			UnavailableRate: *unavailableRate,
+			ErrorCode:       *errorCode,
+		},
+	}
+	if *errorCode != "" {
+		config.Faults.ErrorString = *errorString
+	}
+
+	log.Printf("Creditinfo simulator listening on %s", *addr)
+	if err := http.ListenAndServe(*addr, New(config)); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,298 @@
+// Package simulator имитирует SOAP-сервис скоринга Creditinfo (операции
+// GetScoreCards и Score) для разработки и интеграционных тестов.
+//
+// Ответы детерминированы: для ИИН берётся фикстура <ИИН>.json из FixturesDir,
+// а если её нет — результат вычисляется из хэша карты и ИИН. Задержки, SOAP Fault,
+// HTTP-ошибки и коды ошибок бюро можно включить в Config, в фикстуре, заголовком
+// X-Simulator-Fault конкретного запроса или во время работы через POST /_simulator/faults.
+//
+// В тестах обработчик подключается через httptest:
+//
+//	server := httptest.NewServer(simulator.NewHandler(simulator.Config{}))
+//	defer server.Close()
+package simulator
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Скоринговая карта, которую отдаёт GetScoreCards
+type ScoreCard struct {
+	Name       string   `json:"name"`
+	Attributes []string `json:"attributes"`
+}
+
+// Faults — внедряемые сбои
+type Faults struct {
+	// Задержка перед ответом
+	Latency time.Duration `json:"latency"`
+	// Доля запросов (0..1), на которые отвечать SOAP Fault с HTTP 500
+	FaultRate float64 `json:"fault_rate"`
+	// Доля запросов (0..1), на которые отвечать HTTP 503 без тела
+	UnavailableRate float64 `json:"unavailable_rate"`
+	// Код и текст ошибки бюро для всех ответов Score (пусто — успех)
+	ErrorCode   string `json:"error_code"`
+	ErrorString string `json:"error_string"`
+}
+
+type Config struct {
+	ScoreCards  []ScoreCard
+	FixturesDir string
+	Faults      Faults
+}
+
+// Фикстура результата Score для конкретного ИИН: поля return плюс сбои
+type Fixture struct {
+	ErrorCode                       string  `json:"ErrorCode"`
+	ErrorString                     string  `json:"ErrorString"`
+	Score                           string  `json:"Score"`
+	OneYearProbabilityOfDefault     string  `json:"OneYearProbabilityOfDefault"`
+	RiskGrade                       string  `json:"RiskGrade"`
+	ScoreByML                       string  `json:"ScoreByML"`
+	OneYearProbabilityOfDefaultByML string  `json:"OneYearProbabilityOfDefaultByML"`
+	RiskGradeByML                   string  `json:"RiskGradeByML"`
+	Causes                          []Cause `json:"Causes"`
+	LatencyMs                       int     `json:"latency_ms"`
+	Fault                           string  `json:"fault"`
+}
+
+type Cause struct {
+	Name      string `json:"name" xml:"name"`
+	CauseText string `json:"causeText" xml:"causeText"`
+}
+
+var DefaultScoreCards = []ScoreCard{{Name: "BehaviorScoring", Attributes: []string{"IIN"}}}
+
+type Simulator struct {
+	config  Config
+	mu      sync.RWMutex
+	faults  Faults
+	idQuery int64
+}
+
+func New(config Config) *Simulator {
+	if len(config.ScoreCards) == 0 {
+		config.ScoreCards = DefaultScoreCards
+	}
+	return &Simulator{config: config, faults: config.Faults, idQuery: 3600}
+}
+
+// NewHandler возвращает http.Handler симулятора: SOAP на любом пути и
+// управление сбоями на /_simulator/faults
+func NewHandler(config Config) http.Handler {
+	return New(config)
+}
+
+// SetFaults меняет внедряемые сбои во время работы
+func (s *Simulator) SetFaults(faults Faults) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = faults
+}
+
+func (s *Simulator) currentFaults() Faults {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.faults
+}
+
+func (s *Simulator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/_simulator/faults" {
+		s.serveFaults(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeFault(w, "S:Client", "failed to read request")
+		return
+	}
+	var envelope requestEnvelope
+	if err := xml.Unmarshal(body, &envelope); err != nil {
+		writeFault(w, "S:Client", "malformed SOAP envelope: "+err.Error())
+		return
+	}
+
+	faults := s.currentFaults()
+	var fixture *Fixture
+	if envelope.Body.Score != nil {
+		fixture, err = s.loadFixture(envelope.Body.Score.subject())
+		if err != nil {
+			writeFault(w, "S:Server", err.Error())
+			return
+		}
+	}
+
+	latency := faults.Latency
+	if fixture != nil && fixture.LatencyMs > 0 {
+		latency = time.Duration(fixture.LatencyMs) * time.Millisecond
+	}
+	if header := r.Header.Get("X-Simulator-Latency"); header != "" {
+		if d, err := time.ParseDuration(header); err == nil {
+			latency = d
+		}
+	}
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	fault := r.Header.Get("X-Simulator-Fault")
+	if fault == "" && fixture != nil {
+		fault = fixture.Fault
+	}
+	if fault == "" && rand.Float64() < faults.FaultRate {
+		fault = "soap-fault"
+	}
+	if fault == "" && rand.Float64() < faults.UnavailableRate {
+		fault = "unavailable"
+	}
+	switch fault {
+	case "soap-fault":
+		writeFault(w, "S:Server", "Simulated bureau fault")
+		return
+	case "unavailable":
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	switch {
+	case envelope.Body.GetScoreCards != nil:
+		s.writeScoreCards(w)
+	case envelope.Body.Score != nil:
+		result := s.scoreResult(*envelope.Body.Score, fixture, faults)
+		if strings.HasPrefix(fault, "error-code:") {
+			result.ErrorCode = strings.TrimPrefix(fault, "error-code:")
+			result.ErrorString = "Simulated bureau error"
+		}
+		writeXML(w, http.StatusOK, responseBody{ScoreResponse: &scoreResponse{Xmlns: creditinfoNamespace, Return: result}})
+	default:
+		writeFault(w, "S:Client", "unknown operation")
+	}
+}
+
+func (s *Simulator) serveFaults(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost, http.MethodPut:
+		var faults Faults
+		if err := json.NewDecoder(r.Body).Decode(&faults); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.SetFaults(faults)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.currentFaults())
+}
+
+func (s *Simulator) loadFixture(subject string) (*Fixture, error) {
+	if s.config.FixturesDir == "" || subject == "" || strings.ContainsAny(subject, `/\.`) {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filepath.Join(s.config.FixturesDir, subject+".json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %v", err)
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %v", subject, err)
+	}
+	return &fixture, nil
+}
+
+var (
+	riskGrades = []string{"A1", "A2", "A3", "B1", "B2", "B3", "C1", "C2", "C3", "D1", "D2", "D3", "E1", "E2", "E3"}
+	pdRanges   = []string{"0% - 1%", "1% - 2%", "2% - 3%", "3% - 5%", "5% - 10%", "10% - 15%", "15% - 25%", "25% - 50%", "50% - 100%"}
+	causes     = []Cause{
+		{Name: "Delinquency", CauseText: "Наличие просроченной задолженности"},
+		{Name: "Utilization", CauseText: "Высокая загрузка кредитных лимитов"},
+		{Name: "Inquiries", CauseText: "Много запросов кредитной истории за последнее время"},
+		{Name: "History", CauseText: "Короткая кредитная история"},
+	}
+)
+
+func (s *Simulator) scoreResult(request scoreRequest, fixture *Fixture, faults Faults) scoreReturn {
+	result := scoreReturn{ErrorCode: "0", ErrorString: "Выполнено успешно"}
+	subject := request.subject()
+
+	switch {
+	case fixture != nil:
+		result = scoreReturn{
+			ErrorCode:                       fixture.ErrorCode,
+			ErrorString:                     fixture.ErrorString,
+			Score:                           fixture.Score,
+			OneYearProbabilityOfDefault:     fixture.OneYearProbabilityOfDefault,
+			RiskGrade:                       fixture.RiskGrade,
+			ScoreByML:                       fixture.ScoreByML,
+			OneYearProbabilityOfDefaultByML: fixture.OneYearProbabilityOfDefaultByML,
+			RiskGradeByML:                   fixture.RiskGradeByML,
+			Causes:                          fixture.Causes,
+		}
+		if result.ErrorCode == "" {
+			result.ErrorCode = "0"
+			result.ErrorString = "Выполнено успешно"
+		}
+	case subject == "":
+		result.ErrorCode = "2"
+		result.ErrorString = "Субъект не найден"
+	default:
+		// Один и тот же ИИН по одной карте всегда получает один и тот же результат
+		h := sha256.Sum256([]byte(strings.TrimSpace(request.ScoreCard) + "|" + subject))
+		score := 20 + int(h[0])%80
+		grade := (100 - score) * len(riskGrades) / 81
+		pd := grade * len(pdRanges) / len(riskGrades)
+		mlGrade := (grade + int(h[1])%3 - 1 + len(riskGrades)) % len(riskGrades)
+
+		result.Score = strconv.Itoa(score) + ".0"
+		result.OneYearProbabilityOfDefault = pdRanges[pd]
+		result.RiskGrade = riskGrades[grade]
+		result.ScoreByML = strconv.Itoa(300+int(h[2])%550) + ".0"
+		result.OneYearProbabilityOfDefaultByML = pdRanges[mlGrade*len(pdRanges)/len(riskGrades)]
+		result.RiskGradeByML = riskGrades[mlGrade]
+		// Чем ниже балл, тем больше причин: от одной до трёх
+		if score < 60 {
+			for i := 0; i <= (60-score)/15; i++ {
+				result.Causes = append(result.Causes, causes[(int(h[3])+i)%len(causes)])
+			}
+		}
+	}
+
+	if faults.ErrorCode != "" {
+		result.ErrorCode = faults.ErrorCode
+		result.ErrorString = faults.ErrorString
+	}
+	if result.ErrorCode != "0" {
+		result.Score, result.RiskGrade, result.ScoreByML, result.RiskGradeByML = "", "", "", ""
+		result.OneYearProbabilityOfDefault, result.OneYearProbabilityOfDefaultByML = "", ""
+		result.Causes = nil
+	}
+	result.IdQuery = strconv.FormatInt(atomic.AddInt64(&s.idQuery, 1), 10)
+	return result
+}
@@ -0,0 +1,117 @@
+package simulator
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+)
+
+const (
+	soapEnvelopeNamespace = "http://schemas.xmlsoap.org/soap/envelope/"
+	creditinfoNamespace   = "http://score.ws.creditinfo.com/"
+)
+
+// Входящий SOAP-запрос: пространства имён не проверяем, операции различаем по элементу Body
+type requestEnvelope struct {
+	Body struct {
+		GetScoreCards *struct{}     `xml:"GetScoreCards"`
+		Score         *scoreRequest `xml:"Score"`
+	} `xml:"Body"`
+}
+
+type scoreRequest struct {
+	ScoreCard  string `xml:"ScoreCard"`
+	Attributes []struct {
+		Name  string `xml:"name"`
+		Value string `xml:"value"`
+	} `xml:"attributes"`
+}
+
+// Субъект запроса — значение атрибута IIN (или BIN)
+func (r scoreRequest) subject() string {
+	for _, a := range r.Attributes {
+		name := strings.ToUpper(strings.TrimSpace(a.Name))
+		if name == "IIN" || name == "BIN" {
+			return strings.TrimSpace(a.Value)
+		}
+	}
+	return ""
+}
+
+// Ответы повторяют форму GetScoreCardsXml и ScoreXml из сервиса
+type responseEnvelope struct {
+	XMLName xml.Name     `xml:"S:Envelope"`
+	XmlnsS  string       `xml:"xmlns:S,attr"`
+	Body    responseBody `xml:"S:Body"`
+}
+
+type responseBody struct {
+	GetScoreCardsResponse *scoreCardsResponse `xml:"GetScoreCardsResponse,omitempty"`
+	ScoreResponse         *scoreResponse      `xml:"ScoreResponse,omitempty"`
+	Fault                 *soapFault          `xml:"S:Fault,omitempty"`
+}
+
+type scoreCardsResponse struct {
+	Xmlns  string            `xml:"xmlns,attr"`
+	Return []scoreCardReturn `xml:"return"`
+}
+
+type scoreCardReturn struct {
+	Attributes []scoreCardAttribute `xml:"attributes"`
+	Name       string               `xml:"name"`
+}
+
+type scoreCardAttribute struct {
+	Name string `xml:"name"`
+}
+
+type scoreResponse struct {
+	Xmlns  string      `xml:"xmlns,attr"`
+	Return scoreReturn `xml:"return"`
+}
+
+type scoreReturn struct {
+	IdQuery                         string  `xml:"IdQuery"`
+	ErrorCode                       string  `xml:"ErrorCode"`
+	ErrorString                     string  `xml:"ErrorString"`
+	Score                           string  `xml:"Score"`
+	OneYearProbabilityOfDefault     string  `xml:"OneYearProbabilityOfDefault"`
+	RiskGrade                       string  `xml:"RiskGrade"`
+	ScoreByML                       string  `xml:"ScoreByML"`
+	OneYearProbabilityOfDefaultByML string  `xml:"OneYearProbabilityOfDefaultByML"`
+	RiskGradeByML                   string  `xml:"RiskGradeByML"`
+	Causes                          []Cause `xml:"Causes"`
+}
+
+type soapFault struct {
+	Code   string `xml:"faultcode"`
+	String string `xml:"faultstring"`
+}
+
+func (s *Simulator) writeScoreCards(w http.ResponseWriter) {
+	response := &scoreCardsResponse{Xmlns: creditinfoNamespace}
+	for _, card := range s.config.ScoreCards {
+		ret := scoreCardReturn{Name: card.Name}
+		for _, attribute := range card.Attributes {
+			ret.Attributes = append(ret.Attributes, scoreCardAttribute{Name: attribute})
+		}
+		response.Return = append(response.Return, ret)
+	}
+	writeXML(w, http.StatusOK, responseBody{GetScoreCardsResponse: response})
+}
+
+func writeFault(w http.ResponseWriter, code string, message string) {
+	writeXML(w, http.StatusInternalServerError, responseBody{Fault: &soapFault{Code: code, String: message}})
+}
+
+func writeXML(w http.ResponseWriter, status int, body responseBody) {
+	data, err := xml.Marshal(responseEnvelope{XmlnsS: soapEnvelopeNamespace, Body: body})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(data)
+}